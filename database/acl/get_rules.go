@@ -0,0 +1,31 @@
+package acl
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func GetRules(username string) ([]types.ACLRule, error) {
+	rules := []types.ACLRule{}
+
+	rows, err := database.DB.Query(
+		"SELECT id, path, permission, allow FROM acl_rules WHERE username = ? ORDER BY path, permission;",
+		username,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting acl rules: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule types.ACLRule
+		if err := rows.Scan(&rule.ID, &rule.Path, &rule.Permission, &rule.Allow); err != nil {
+			return nil, fmt.Errorf("error scanning acl rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
@@ -0,0 +1,39 @@
+package acl
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+// ReplaceRules deletes the old rules of the user and saves the new ones in one transaction.
+func ReplaceRules(username string, rules []types.ACLRule) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM acl_rules WHERE username = ?;", username); err != nil {
+		return fmt.Errorf("error deleting acl rules: %w", err)
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO acl_rules(username, path, permission, allow) VALUES(?, ?, ?, ?);")
+	if err != nil {
+		return fmt.Errorf("error creating db stmt: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, rule := range rules {
+		if _, err := stmt.Exec(username, rule.Path, rule.Permission, rule.Allow); err != nil {
+			return fmt.Errorf("error executing db stmt: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit error: %w", err)
+	}
+
+	return nil
+}
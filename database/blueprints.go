@@ -31,6 +31,13 @@ func CreateUsersTable() {
 			read_users_permission BOOLEAN DEFAULT FALSE,
 			edit_users_permission BOOLEAN DEFAULT FALSE,
 			read_logs_permission BOOLEAN DEFAULT FALSE,
+			token_version INTEGER NOT NULL DEFAULT 0,
+			two_factor_required BOOLEAN DEFAULT FALSE,
+			totp_enabled BOOLEAN DEFAULT FALSE,
+			totp_secret TEXT NULL,
+			totp_pending_secret TEXT NULL,
+			totp_last_step INTEGER NOT NULL DEFAULT 0,
+			quota TEXT NOT NULL DEFAULT '',
         	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		);
 	`)
@@ -91,3 +98,302 @@ func CreateRecoveryTable() {
 	fmt.Println("Recovery table has been created!")
 
 }
+
+func CreateRefreshTokensTable() {
+	_, err := DB.Exec(`
+		CREATE TABLE IF NOT EXISTS refresh_tokens (
+			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+			username TEXT NOT NULL,
+			user_id INTEGER NOT NULL DEFAULT 0,
+			token_hash TEXT NOT NULL UNIQUE,
+			family_id TEXT NOT NULL,
+			token_version INTEGER NOT NULL DEFAULT 0,
+			revoked BOOLEAN DEFAULT FALSE,
+			expires_at DATETIME NOT NULL,
+        	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (username) REFERENCES users(username) 
+                ON DELETE CASCADE 
+                ON UPDATE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_refresh_tokens_username ON refresh_tokens(username);
+		CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+	`)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func CreateTwoFactorRecoveryCodesTable() {
+	_, err := DB.Exec(`
+		CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+			username TEXT NOT NULL,
+			code_hash TEXT NOT NULL,
+			used BOOLEAN DEFAULT FALSE,
+        	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (username) REFERENCES users(username) 
+                ON DELETE CASCADE 
+                ON UPDATE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_two_factor_recovery_codes_username ON two_factor_recovery_codes(username);
+	`)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func CreateACLRulesTable() {
+	_, err := DB.Exec(`
+		CREATE TABLE IF NOT EXISTS acl_rules (
+			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+			username TEXT NOT NULL,
+			path TEXT NOT NULL,
+			permission TEXT NOT NULL,
+			allow BOOLEAN NOT NULL,
+        	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (username, path, permission),
+			FOREIGN KEY (username) REFERENCES users(username) 
+                ON DELETE CASCADE 
+                ON UPDATE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_acl_rules_username ON acl_rules(username);
+	`)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func CreateGroupsTables() {
+	_, err := DB.Exec(`
+		CREATE TABLE IF NOT EXISTS user_groups (
+			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			scope TEXT NOT NULL DEFAULT '',
+			read_directories BOOLEAN DEFAULT FALSE,
+        	read_files BOOLEAN DEFAULT FALSE,
+        	create_permission BOOLEAN DEFAULT FALSE,
+        	change_permission BOOLEAN DEFAULT FALSE,
+        	delete_permission BOOLEAN DEFAULT FALSE,
+        	move_permission BOOLEAN DEFAULT FALSE,
+        	download_permission BOOLEAN DEFAULT FALSE,
+        	upload_permission BOOLEAN DEFAULT FALSE,
+        	rename_permission BOOLEAN DEFAULT FALSE,
+        	extract_permission BOOLEAN DEFAULT FALSE,
+			archive_permission BOOLEAN DEFAULT FALSE,
+        	copy_permission BOOLEAN DEFAULT FALSE,
+			logs_permission BOOLEAN DEFAULT FALSE,
+			read_recovery_permission BOOLEAN DEFAULT FALSE,
+			use_recovery_permission BOOLEAN DEFAULT FALSE,
+			read_users_permission BOOLEAN DEFAULT FALSE,
+			edit_users_permission BOOLEAN DEFAULT FALSE,
+        	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS user_group_members (
+			username TEXT NOT NULL,
+			group_id INTEGER NOT NULL,
+			PRIMARY KEY (username, group_id),
+			FOREIGN KEY (username) REFERENCES users(username) 
+                ON DELETE CASCADE 
+                ON UPDATE CASCADE,
+			FOREIGN KEY (group_id) REFERENCES user_groups(id) 
+                ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS user_permission_overrides (
+			username TEXT NOT NULL,
+			permission TEXT NOT NULL,
+			allow BOOLEAN NOT NULL,
+			PRIMARY KEY (username, permission),
+			FOREIGN KEY (username) REFERENCES users(username) 
+                ON DELETE CASCADE 
+                ON UPDATE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS group_acl_rules (
+			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+			group_id INTEGER NOT NULL,
+			path TEXT NOT NULL,
+			permission TEXT NOT NULL,
+			allow BOOLEAN NOT NULL,
+			UNIQUE (group_id, path, permission),
+			FOREIGN KEY (group_id) REFERENCES user_groups(id) 
+                ON DELETE CASCADE
+		);
+	`)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func CreateUploadsTable() {
+	_, err := DB.Exec(`
+		CREATE TABLE IF NOT EXISTS uploads (
+			id TEXT NOT NULL PRIMARY KEY,
+			username TEXT NOT NULL,
+			path TEXT NOT NULL,
+			length INTEGER NOT NULL,
+			upload_offset INTEGER NOT NULL DEFAULT 0,
+			metadata TEXT NOT NULL DEFAULT '',
+			expires_at DATETIME NOT NULL,
+        	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (username) REFERENCES users(username) 
+                ON DELETE CASCADE 
+                ON UPDATE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_uploads_username ON uploads(username);
+	`)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func CreateShareLinksTable() {
+	_, err := DB.Exec(`
+		CREATE TABLE IF NOT EXISTS share_links (
+			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+			token TEXT NOT NULL UNIQUE,
+			username TEXT NOT NULL,
+			path TEXT NOT NULL,
+			is_directory BOOLEAN DEFAULT FALSE,
+			mode TEXT NOT NULL DEFAULT 'read',
+			password_hash TEXT NOT NULL DEFAULT '',
+			expires_at DATETIME NULL,
+			max_uses INTEGER NOT NULL DEFAULT 0,
+			use_count INTEGER NOT NULL DEFAULT 0,
+        	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (username) REFERENCES users(username) 
+                ON DELETE CASCADE 
+                ON UPDATE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_share_links_username ON share_links(username);
+	`)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func CreateS3KeysTable() {
+	_, err := DB.Exec(`
+		CREATE TABLE IF NOT EXISTS s3_keys (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			access_key_id TEXT NOT NULL UNIQUE,
+			secret_access_key TEXT NOT NULL,
+			username TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (username) REFERENCES users(username)
+				ON DELETE CASCADE
+				ON UPDATE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_s3_keys_username ON s3_keys(username);
+	`)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func CreateMultipartUploadsTable() {
+	_, err := DB.Exec(`
+		CREATE TABLE IF NOT EXISTS multipart_uploads (
+			id TEXT NOT NULL PRIMARY KEY,
+			username TEXT NOT NULL,
+			path TEXT NOT NULL,
+			expires_at DATETIME NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (username) REFERENCES users(username)
+				ON DELETE CASCADE
+				ON UPDATE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_multipart_uploads_username ON multipart_uploads(username);
+	`)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func CreateSFTPKeysTable() {
+	_, err := DB.Exec(`
+		CREATE TABLE IF NOT EXISTS sftp_keys (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			username TEXT NOT NULL,
+			public_key TEXT NOT NULL,
+			fingerprint TEXT NOT NULL,
+			comment TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (username, fingerprint),
+			FOREIGN KEY (username) REFERENCES users(username)
+				ON DELETE CASCADE
+				ON UPDATE CASCADE
+		);
+	`)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Versions aren't removed with their users, they belong to the files
+func CreateFileVersionsTable() {
+	_, err := DB.Exec(`
+		CREATE TABLE IF NOT EXISTS file_versions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			path TEXT NOT NULL,
+			location TEXT NOT NULL,
+			username TEXT NOT NULL,
+			size_display TEXT NOT NULL,
+			size_bytes INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_file_versions_path ON file_versions(path);
+	`)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func CreateJobsTable() {
+	_, err := DB.Exec(`
+		CREATE TABLE IF NOT EXISTS jobs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			username TEXT NOT NULL,
+			status TEXT NOT NULL,
+			source TEXT NOT NULL,
+			destination TEXT NOT NULL DEFAULT '',
+			format TEXT NOT NULL DEFAULT '',
+			entries TEXT NOT NULL DEFAULT '',
+			result TEXT NOT NULL DEFAULT '',
+			progress INTEGER NOT NULL DEFAULT 0,
+			total INTEGER NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			started_at DATETIME NULL,
+			finished_at DATETIME NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_jobs_username ON jobs(username);
+		CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status);
+	`)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
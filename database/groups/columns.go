@@ -0,0 +1,84 @@
+package groups
+
+import (
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+// Same permission columns as the users table
+const groupColumns = `
+	id,
+	name,
+	scope,
+	read_directories,
+	read_files,
+	create_permission,
+	change_permission,
+	delete_permission,
+	move_permission,
+	download_permission,
+	upload_permission,
+	rename_permission,
+	extract_permission,
+	archive_permission,
+	copy_permission,
+	read_recovery_permission,
+	use_recovery_permission,
+	read_users_permission,
+	edit_users_permission,
+	logs_permission
+`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanGroup(row scanner) (types.Group, error) {
+	g := types.Group{}
+
+	err := row.Scan(
+		&g.ID,
+		&g.Name,
+		&g.Scope,
+		&g.Permissions.ReadDirectories,
+		&g.Permissions.ReadFiles,
+		&g.Permissions.Create,
+		&g.Permissions.Change,
+		&g.Permissions.Delete,
+		&g.Permissions.Move,
+		&g.Permissions.DownloadFiles,
+		&g.Permissions.UploadFiles,
+		&g.Permissions.Rename,
+		&g.Permissions.Extract,
+		&g.Permissions.Archive,
+		&g.Permissions.Copy,
+		&g.Permissions.ReadRecovery,
+		&g.Permissions.UseRecovery,
+		&g.Permissions.ReadUsers,
+		&g.Permissions.EditUsers,
+		&g.Permissions.ReadLogs,
+	)
+
+	return g, err
+}
+
+func permissionValues(group *types.Group) []any {
+	return []any{
+		group.Permissions.ReadDirectories,
+		group.Permissions.ReadFiles,
+		group.Permissions.Create,
+		group.Permissions.Change,
+		group.Permissions.Delete,
+		group.Permissions.Move,
+		group.Permissions.DownloadFiles,
+		group.Permissions.UploadFiles,
+		group.Permissions.Rename,
+		group.Permissions.Extract,
+		group.Permissions.Archive,
+		group.Permissions.Copy,
+		group.Permissions.ReadRecovery,
+		group.Permissions.UseRecovery,
+		group.Permissions.ReadUsers,
+		group.Permissions.EditUsers,
+		group.Permissions.ReadLogs,
+	}
+}
@@ -0,0 +1,59 @@
+package groups
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func CreateGroup(group *types.Group) error {
+	if exists, _ := CheckIfGroupExists(group.Name); exists {
+		return ErrGroupExists
+	}
+
+	values := append([]any{group.Name, group.Scope}, permissionValues(group)...)
+
+	result, err := database.DB.Exec(`
+		INSERT INTO user_groups(
+			name,
+			scope,
+			read_directories,
+			read_files,
+			create_permission,
+			change_permission,
+			delete_permission,
+			move_permission,
+			download_permission,
+			upload_permission,
+			rename_permission,
+			extract_permission,
+			archive_permission,
+			copy_permission,
+			read_recovery_permission,
+			use_recovery_permission,
+			read_users_permission,
+			edit_users_permission,
+			logs_permission
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, values...)
+
+	if err != nil {
+		return fmt.Errorf("error creating group: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	idInt := int(id)
+	group.ID = &idInt
+	return nil
+}
+
+func CheckIfGroupExists(name string) (bool, error) {
+	var count int
+	err := database.DB.QueryRow("SELECT COUNT(*) FROM user_groups WHERE name = ?;", name).Scan(&count)
+	return count > 0, err
+}
@@ -0,0 +1,80 @@
+package groups
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func GetAll() ([]types.Group, error) {
+	return queryGroups("SELECT " + groupColumns + " FROM user_groups ORDER BY name;")
+}
+
+// GetUserGroups returns the groups of the user sorted by name.
+func GetUserGroups(username string) ([]types.Group, error) {
+	return queryGroups(`
+		SELECT `+groupColumns+`
+		FROM user_groups
+		WHERE id IN (SELECT group_id FROM user_group_members WHERE username = ?)
+		ORDER BY name;
+	`, username)
+}
+
+// GetMemberships returns group names by username for all users.
+func GetMemberships() (map[string][]string, error) {
+	memberships := make(map[string][]string)
+
+	rows, err := database.DB.Query(`
+		SELECT m.username, g.name
+		FROM user_group_members m
+		JOIN user_groups g ON g.id = m.group_id
+		ORDER BY g.name;
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error getting group memberships: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var username, name string
+		if err := rows.Scan(&username, &name); err != nil {
+			return nil, fmt.Errorf("error scanning group membership: %w", err)
+		}
+		memberships[username] = append(memberships[username], name)
+	}
+
+	return memberships, rows.Err()
+}
+
+func queryGroups(query string, args ...any) ([]types.Group, error) {
+	groups := []types.Group{}
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting groups: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for index := range groups {
+		groups[index].ACL, err = GetRules(*groups[index].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,62 @@
+package groups
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func GetRules(groupID int) ([]types.ACLRule, error) {
+	rules := []types.ACLRule{}
+
+	rows, err := database.DB.Query(
+		"SELECT id, path, permission, allow FROM group_acl_rules WHERE group_id = ? ORDER BY path, permission;",
+		groupID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting group acl rules: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule types.ACLRule
+		if err := rows.Scan(&rule.ID, &rule.Path, &rule.Permission, &rule.Allow); err != nil {
+			return nil, fmt.Errorf("error scanning group acl rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// ReplaceRules deletes the old rules of the group and saves the new ones in one transaction.
+func ReplaceRules(groupID int, rules []types.ACLRule) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM group_acl_rules WHERE group_id = ?;", groupID); err != nil {
+		return fmt.Errorf("error deleting group acl rules: %w", err)
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO group_acl_rules(group_id, path, permission, allow) VALUES(?, ?, ?, ?);")
+	if err != nil {
+		return fmt.Errorf("error creating db stmt: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, rule := range rules {
+		if _, err := stmt.Exec(groupID, rule.Path, rule.Permission, rule.Allow); err != nil {
+			return fmt.Errorf("error executing db stmt: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit error: %w", err)
+	}
+
+	return nil
+}
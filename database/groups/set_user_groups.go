@@ -0,0 +1,50 @@
+package groups
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+var (
+	ErrGroupExists  = errors.New("group already exists")
+	ErrUnknownGroup = errors.New("group doesn't exist")
+)
+
+// SetUserGroups replaces the memberships of the user. Nothing is changed if a group doesn't exist.
+func SetUserGroups(username string, names []string) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM user_group_members WHERE username = ?;", username); err != nil {
+		return fmt.Errorf("error deleting group memberships: %w", err)
+	}
+
+	for _, name := range names {
+		result, err := tx.Exec(
+			"INSERT OR IGNORE INTO user_group_members(username, group_id) SELECT ?, id FROM user_groups WHERE name = ?;",
+			username,
+			name,
+		)
+		if err != nil {
+			return fmt.Errorf("error adding group membership: %w", err)
+		}
+
+		if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+			var count int
+			if err := tx.QueryRow("SELECT COUNT(*) FROM user_groups WHERE name = ?;", name).Scan(&count); err != nil || count == 0 {
+				return fmt.Errorf("%w: %s", ErrUnknownGroup, name)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit error: %w", err)
+	}
+
+	return nil
+}
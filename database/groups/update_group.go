@@ -0,0 +1,78 @@
+package groups
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func UpdateGroup(id int, group *types.Group) error {
+	values := append([]any{group.Name, group.Scope}, permissionValues(group)...)
+	values = append(values, id)
+
+	result, err := database.DB.Exec(`
+		UPDATE user_groups SET
+			name = ?,
+			scope = ?,
+			read_directories = ?,
+			read_files = ?,
+			create_permission = ?,
+			change_permission = ?,
+			delete_permission = ?,
+			move_permission = ?,
+			download_permission = ?,
+			upload_permission = ?,
+			rename_permission = ?,
+			extract_permission = ?,
+			archive_permission = ?,
+			copy_permission = ?,
+			read_recovery_permission = ?,
+			use_recovery_permission = ?,
+			read_users_permission = ?,
+			edit_users_permission = ?,
+			logs_permission = ?
+		WHERE id = ?
+	`, values...)
+
+	if err != nil {
+		return fmt.Errorf("error updating group: %w", err)
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RemoveGroup removes the group with its memberships and path rules. The foreign keys can't be relied on,
+// PRAGMA foreign_keys is only enabled on one connection of the pool.
+func RemoveGroup(id int) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		"DELETE FROM user_group_members WHERE group_id = ?;",
+		"DELETE FROM group_acl_rules WHERE group_id = ?;",
+	} {
+		if _, err := tx.Exec(query, id); err != nil {
+			return fmt.Errorf("error executing db stmt: %w", err)
+		}
+	}
+
+	result, err := tx.Exec("DELETE FROM user_groups WHERE id = ?;", id)
+	if err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 
 	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/database/groups"
 	"github.com/MertJSX/folder-host-go/database/users"
 	"github.com/MertJSX/folder-host-go/utils"
 	"github.com/MertJSX/folder-host-go/utils/config"
@@ -37,6 +38,11 @@ func InitializeDatabase() {
 		database.CreateUsersTable()
 		database.CreateLogsTable()
 		database.CreateRecoveryTable()
+	}
+
+	database.Migrate()
+
+	if firstTime {
 		err = users.CreateUser(&config.Config.AdminAccount)
 
 		if err != nil {
@@ -46,5 +52,10 @@ func InitializeDatabase() {
 
 	users.UpdateAdmin(&config.Config.AdminAccount)
 
+	// Groups of the admin are created in the web panel and only referenced by name in config.yml
+	if err := groups.SetUserGroups(config.Config.AdminAccount.Username, config.Config.AdminAccount.Groups); err != nil {
+		fmt.Printf("Error setting groups of the admin account: %v\n", err)
+	}
+
 	fmt.Println("Database connection established successfully!")
 }
@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func CreateJob(job *types.Job) error {
+	result, err := database.DB.Exec(`
+		INSERT INTO jobs(
+			type,
+			username,
+			status,
+			source,
+			destination,
+			format,
+			entries
+		) VALUES(?, ?, ?, ?, ?, ?, ?)
+	`,
+		job.Type,
+		job.Username,
+		job.Status,
+		job.Source,
+		job.Destination,
+		job.Format,
+		encodeEntries(job.Entries),
+	)
+
+	if err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error reading job id: %w", err)
+	}
+	job.ID = int(id)
+	job.CreatedAt = time.Now().UTC()
+
+	return nil
+}
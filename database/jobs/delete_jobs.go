@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+// DeleteFinishedJobsBefore removes completed, failed and cancelled jobs that finished before the date.
+func DeleteFinishedJobsBefore(date time.Time) error {
+	_, err := database.DB.Exec(
+		"DELETE FROM jobs WHERE status IN (?, ?, ?) AND finished_at < ?;",
+		types.JobCompleted,
+		types.JobFailed,
+		types.JobCancelled,
+		date.UTC(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	return nil
+}
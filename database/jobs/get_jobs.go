@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+const selectJobColumns = `
+		SELECT
+			id,
+			type,
+			username,
+			status,
+			source,
+			destination,
+			format,
+			entries,
+			result,
+			progress,
+			total,
+			error,
+			attempts,
+			created_at,
+			started_at,
+			finished_at
+		FROM jobs
+`
+
+func GetJobByID(id int) (types.Job, error) {
+	var job types.Job
+
+	err := scanJob(database.DB.QueryRow(selectJobColumns+"WHERE id = ?", id), &job)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.Job{}, sql.ErrNoRows
+		}
+		return types.Job{}, err
+	}
+
+	return job, nil
+}
+
+// GetJobsByUsername returns the latest jobs of the user, the newest first.
+func GetJobsByUsername(username string, limit int) ([]types.Job, error) {
+	return getJobs(selectJobColumns+"WHERE username = ? ORDER BY id DESC LIMIT ?", username, limit)
+}
+
+// GetAllJobs returns the latest jobs of every user, the newest first.
+func GetAllJobs(limit int) ([]types.Job, error) {
+	return getJobs(selectJobColumns+"ORDER BY id DESC LIMIT ?", limit)
+}
+
+// GetJobsByStatus returns the jobs with the status, the oldest first.
+func GetJobsByStatus(status string) ([]types.Job, error) {
+	return getJobs(selectJobColumns+"WHERE status = ? ORDER BY id", status)
+}
+
+func getJobs(query string, args ...any) ([]types.Job, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing db query: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []types.Job{}
+	for rows.Next() {
+		var job types.Job
+		if err := scanJob(rows, &job); err != nil {
+			return nil, fmt.Errorf("error scanning job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row rowScanner, job *types.Job) error {
+	var entries string
+
+	err := row.Scan(
+		&job.ID,
+		&job.Type,
+		&job.Username,
+		&job.Status,
+		&job.Source,
+		&job.Destination,
+		&job.Format,
+		&entries,
+		&job.Result,
+		&job.Progress,
+		&job.Total,
+		&job.Error,
+		&job.Attempts,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	job.Entries = decodeEntries(entries)
+	return nil
+}
+
+// Entries are kept as a JSON array, they are only read with the job.
+func encodeEntries(entries []string) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(entries)
+	return string(data)
+}
+
+func decodeEntries(data string) []string {
+	var entries []string
+	if data != "" {
+		json.Unmarshal([]byte(data), &entries)
+	}
+	return entries
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+// UpdateJob saves everything that changes while a job runs.
+func UpdateJob(job types.Job) error {
+	_, err := database.DB.Exec(`
+		UPDATE jobs SET
+			status = ?,
+			result = ?,
+			progress = ?,
+			total = ?,
+			error = ?,
+			attempts = ?,
+			started_at = ?,
+			finished_at = ?
+		WHERE id = ?;
+	`,
+		job.Status,
+		job.Result,
+		job.Progress,
+		job.Total,
+		job.Error,
+		job.Attempts,
+		job.StartedAt,
+		job.FinishedAt,
+		job.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	return nil
+}
+
+// RequeueJob resets a failed or cancelled job to queued. False means the job has another status, for example
+// because another request queued it first.
+func RequeueJob(id int) (bool, error) {
+	result, err := database.DB.Exec(`
+		UPDATE jobs SET
+			status = ?,
+			result = '',
+			progress = 0,
+			total = 0,
+			error = '',
+			started_at = NULL,
+			finished_at = NULL
+		WHERE id = ? AND status IN (?, ?);
+	`,
+		types.JobQueued,
+		id,
+		types.JobFailed,
+		types.JobCancelled,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error executing db stmt: %w", err)
+	}
+	return affected == 1, nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"fmt"
+	"log"
+)
+
+// Migrate brings databases created by older versions up to date.
+// It runs on every start, so every step must be safe to repeat.
+func Migrate() {
+	columns := []struct {
+		table      string
+		column     string
+		definition string
+	}{
+		{"users", "token_version", "INTEGER NOT NULL DEFAULT 0"},
+		{"users", "two_factor_required", "BOOLEAN DEFAULT FALSE"},
+		{"users", "totp_enabled", "BOOLEAN DEFAULT FALSE"},
+		{"users", "totp_secret", "TEXT NULL"},
+		{"users", "totp_pending_secret", "TEXT NULL"},
+		{"users", "totp_last_step", "INTEGER NOT NULL DEFAULT 0"},
+		{"users", "quota", "TEXT NOT NULL DEFAULT ''"},
+		{"refresh_tokens", "user_id", "INTEGER NOT NULL DEFAULT 0"},
+		{"jobs", "format", "TEXT NOT NULL DEFAULT ''"},
+		{"jobs", "entries", "TEXT NOT NULL DEFAULT ''"},
+	}
+
+	CreateRefreshTokensTable()
+	CreateTwoFactorRecoveryCodesTable()
+	CreateACLRulesTable()
+	CreateGroupsTables()
+	CreateUploadsTable()
+	CreateShareLinksTable()
+	CreateS3KeysTable()
+	CreateMultipartUploadsTable()
+	CreateSFTPKeysTable()
+	CreateFileVersionsTable()
+	CreateJobsTable()
+
+	// Columns are added after the tables are created, so they can be added to tables of any version
+	for _, column := range columns {
+		if err := AddColumnIfNotExists(column.table, column.column, column.definition); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func AddColumnIfNotExists(table string, column string, definition string) error {
+	rows, err := DB.Query(fmt.Sprintf("PRAGMA table_info(%s);", table))
+	if err != nil {
+		return fmt.Errorf("error reading %s table info: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name         string
+			columnType   string
+			notNull      bool
+			defaultValue any
+			primaryKey   int
+		)
+
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return fmt.Errorf("error reading %s table info: %w", table, err)
+		}
+
+		if name == column {
+			return nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading %s table info: %w", table, err)
+	}
+
+	rows.Close()
+
+	_, err = DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("error adding %s column to %s table: %w", column, table, err)
+	}
+
+	fmt.Printf("Added %s column to %s table!\n", column, table)
+	return nil
+}
@@ -0,0 +1,30 @@
+package multipart
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func CreateMultipartUpload(upload types.MultipartUpload) error {
+	_, err := database.DB.Exec(`
+		INSERT INTO multipart_uploads(
+			id,
+			username,
+			path,
+			expires_at
+		) VALUES(?, ?, ?, ?)
+	`,
+		upload.ID,
+		upload.Username,
+		upload.Path,
+		upload.ExpiresAt.UTC(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,10 @@
+package multipart
+
+import (
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+func DeleteMultipartUpload(id string) error {
+	_, err := database.DB.Exec("DELETE FROM multipart_uploads WHERE id = ?;", id)
+	return err
+}
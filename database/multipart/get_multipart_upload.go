@@ -0,0 +1,87 @@
+package multipart
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+const selectMultipartUploadColumns = `
+		SELECT
+			id,
+			username,
+			path,
+			expires_at,
+			created_at
+		FROM multipart_uploads
+`
+
+func GetMultipartUpload(id string) (types.MultipartUpload, error) {
+	var upload types.MultipartUpload
+
+	err := scanMultipartUpload(database.DB.QueryRow(selectMultipartUploadColumns+"WHERE id = ?", id), &upload)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.MultipartUpload{}, sql.ErrNoRows
+		}
+		return types.MultipartUpload{}, err
+	}
+
+	return upload, nil
+}
+
+func GetExpiredMultipartUploads() ([]types.MultipartUpload, error) {
+	rows, err := database.DB.Query(selectMultipartUploadColumns+"WHERE expires_at < ?", time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("error executing db query: %w", err)
+	}
+	defer rows.Close()
+
+	var expired []types.MultipartUpload
+	for rows.Next() {
+		var upload types.MultipartUpload
+		if err := scanMultipartUpload(rows, &upload); err != nil {
+			return nil, fmt.Errorf("error scanning multipart upload: %w", err)
+		}
+		expired = append(expired, upload)
+	}
+
+	return expired, rows.Err()
+}
+
+// GetMultipartUploadIDs returns the IDs of all known multipart uploads, including expired ones.
+func GetMultipartUploadIDs() (map[string]bool, error) {
+	rows, err := database.DB.Query("SELECT id FROM multipart_uploads;")
+	if err != nil {
+		return nil, fmt.Errorf("error executing db query: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning multipart upload: %w", err)
+		}
+		ids[id] = true
+	}
+
+	return ids, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMultipartUpload(row rowScanner, upload *types.MultipartUpload) error {
+	return row.Scan(
+		&upload.ID,
+		&upload.Username,
+		&upload.Path,
+		&upload.ExpiresAt,
+		&upload.CreatedAt,
+	)
+}
@@ -0,0 +1,17 @@
+package multipart
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+// UpdateMultipartUploadExpiry is called for every part, uploads expire when they get no data.
+func UpdateMultipartUploadExpiry(id string, expiresAt time.Time) error {
+	_, err := database.DB.Exec("UPDATE multipart_uploads SET expires_at = ? WHERE id = ?;", expiresAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+	return nil
+}
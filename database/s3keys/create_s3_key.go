@@ -0,0 +1,55 @@
+package s3keys
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+// CreateS3Key creates a random access key for the user. IDs look like AWS ones, "FH" and 18 base32 characters.
+func CreateS3Key(username string) (types.S3Key, error) {
+	idBytes := make([]byte, 12)
+	secretBytes := make([]byte, 30)
+	if _, err := rand.Read(idBytes); err != nil {
+		return types.S3Key{}, fmt.Errorf("random read failed: %w", err)
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return types.S3Key{}, fmt.Errorf("random read failed: %w", err)
+	}
+
+	key := types.S3Key{
+		AccessKeyID:     "FH" + base32.StdEncoding.EncodeToString(idBytes)[:18],
+		SecretAccessKey: base64.RawURLEncoding.EncodeToString(secretBytes),
+		Username:        username,
+	}
+
+	result, err := database.DB.Exec(`
+		INSERT INTO s3_keys(
+			access_key_id,
+			secret_access_key,
+			username
+		) VALUES(?, ?, ?)
+	`,
+		key.AccessKeyID,
+		key.SecretAccessKey,
+		key.Username,
+	)
+
+	if err != nil {
+		return types.S3Key{}, fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return types.S3Key{}, fmt.Errorf("error reading s3 key id: %w", err)
+	}
+	key.ID = int(id)
+	key.CreatedAt = time.Now().UTC()
+
+	return key, nil
+}
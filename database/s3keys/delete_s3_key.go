@@ -0,0 +1,10 @@
+package s3keys
+
+import (
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+func DeleteS3Key(id int) error {
+	_, err := database.DB.Exec("DELETE FROM s3_keys WHERE id = ?;", id)
+	return err
+}
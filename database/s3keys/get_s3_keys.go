@@ -0,0 +1,83 @@
+package s3keys
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+const selectS3KeyColumns = `
+		SELECT
+			id,
+			access_key_id,
+			secret_access_key,
+			username,
+			created_at
+		FROM s3_keys
+`
+
+func GetS3KeyByAccessKeyID(accessKeyID string) (types.S3Key, error) {
+	return getS3Key(selectS3KeyColumns+"WHERE access_key_id = ?", accessKeyID)
+}
+
+func GetS3KeyByID(id int) (types.S3Key, error) {
+	return getS3Key(selectS3KeyColumns+"WHERE id = ?", id)
+}
+
+// GetS3Keys returns the keys of the user, or the keys of all users if username is empty. Secrets are left out.
+func GetS3Keys(username string) ([]types.S3Key, error) {
+	query := selectS3KeyColumns + "ORDER BY created_at DESC"
+	args := []any{}
+	if username != "" {
+		query = selectS3KeyColumns + "WHERE username = ? ORDER BY created_at DESC"
+		args = append(args, username)
+	}
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing db query: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []types.S3Key{}
+	for rows.Next() {
+		var key types.S3Key
+		if err := scanS3Key(rows, &key); err != nil {
+			return nil, fmt.Errorf("error scanning s3 key: %w", err)
+		}
+		key.SecretAccessKey = ""
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func getS3Key(query string, arg any) (types.S3Key, error) {
+	var key types.S3Key
+
+	err := scanS3Key(database.DB.QueryRow(query, arg), &key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.S3Key{}, sql.ErrNoRows
+		}
+		return types.S3Key{}, err
+	}
+
+	return key, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanS3Key(row rowScanner, key *types.S3Key) error {
+	return row.Scan(
+		&key.ID,
+		&key.AccessKeyID,
+		&key.SecretAccessKey,
+		&key.Username,
+		&key.CreatedAt,
+	)
+}
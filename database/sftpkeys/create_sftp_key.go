@@ -0,0 +1,38 @@
+package sftpkeys
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func CreateSFTPKey(key *types.SFTPKey) error {
+	result, err := database.DB.Exec(`
+		INSERT INTO sftp_keys(
+			username,
+			public_key,
+			fingerprint,
+			comment
+		) VALUES(?, ?, ?, ?)
+	`,
+		key.Username,
+		key.PublicKey,
+		key.Fingerprint,
+		key.Comment,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error reading sftp key id: %w", err)
+	}
+	key.ID = int(id)
+	key.CreatedAt = time.Now().UTC()
+
+	return nil
+}
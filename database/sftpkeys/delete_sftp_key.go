@@ -0,0 +1,10 @@
+package sftpkeys
+
+import (
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+func DeleteSFTPKey(id int) error {
+	_, err := database.DB.Exec("DELETE FROM sftp_keys WHERE id = ?;", id)
+	return err
+}
@@ -0,0 +1,85 @@
+package sftpkeys
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+const selectSFTPKeyColumns = `
+		SELECT
+			id,
+			username,
+			public_key,
+			fingerprint,
+			comment,
+			created_at
+		FROM sftp_keys
+`
+
+func GetSFTPKeyByID(id int) (types.SFTPKey, error) {
+	return getSFTPKey(selectSFTPKeyColumns+"WHERE id = ?", id)
+}
+
+// GetSFTPKeyByFingerprint returns the key of the user with the fingerprint, or sql.ErrNoRows.
+func GetSFTPKeyByFingerprint(username string, fingerprint string) (types.SFTPKey, error) {
+	return getSFTPKey(selectSFTPKeyColumns+"WHERE username = ? AND fingerprint = ?", username, fingerprint)
+}
+
+// GetSFTPKeys returns the keys of the user, or the keys of all users if username is empty.
+func GetSFTPKeys(username string) ([]types.SFTPKey, error) {
+	query := selectSFTPKeyColumns + "ORDER BY created_at DESC"
+	args := []any{}
+	if username != "" {
+		query = selectSFTPKeyColumns + "WHERE username = ? ORDER BY created_at DESC"
+		args = append(args, username)
+	}
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing db query: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []types.SFTPKey{}
+	for rows.Next() {
+		var key types.SFTPKey
+		if err := scanSFTPKey(rows, &key); err != nil {
+			return nil, fmt.Errorf("error scanning sftp key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func getSFTPKey(query string, args ...any) (types.SFTPKey, error) {
+	var key types.SFTPKey
+
+	err := scanSFTPKey(database.DB.QueryRow(query, args...), &key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.SFTPKey{}, sql.ErrNoRows
+		}
+		return types.SFTPKey{}, err
+	}
+
+	return key, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSFTPKey(row rowScanner, key *types.SFTPKey) error {
+	return row.Scan(
+		&key.ID,
+		&key.Username,
+		&key.PublicKey,
+		&key.Fingerprint,
+		&key.Comment,
+		&key.CreatedAt,
+	)
+}
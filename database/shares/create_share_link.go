@@ -0,0 +1,61 @@
+package shares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+// CreateShareLink stores the link and fills its ID and random token.
+func CreateShareLink(link *types.ShareLink) error {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return fmt.Errorf("random read failed: %w", err)
+	}
+	link.Token = hex.EncodeToString(bytes)
+
+	var expiresAt any
+	if link.ExpiresAt != nil {
+		expiresAt = link.ExpiresAt.UTC()
+	}
+
+	result, err := database.DB.Exec(`
+		INSERT INTO share_links(
+			token,
+			username,
+			path,
+			is_directory,
+			mode,
+			password_hash,
+			expires_at,
+			max_uses
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		link.Token,
+		link.Username,
+		link.Path,
+		link.IsDirectory,
+		link.Mode,
+		link.PasswordHash,
+		expiresAt,
+		link.MaxUses,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error reading share link id: %w", err)
+	}
+	link.ID = int(id)
+	link.CreatedAt = time.Now().UTC()
+	link.HasPassword = link.PasswordHash != ""
+
+	return nil
+}
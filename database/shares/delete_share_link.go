@@ -0,0 +1,10 @@
+package shares
+
+import (
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+func DeleteShareLink(id int) error {
+	_, err := database.DB.Exec("DELETE FROM share_links WHERE id = ?;", id)
+	return err
+}
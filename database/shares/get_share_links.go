@@ -0,0 +1,106 @@
+package shares
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+const selectShareLinkColumns = `
+		SELECT
+			id,
+			token,
+			username,
+			path,
+			is_directory,
+			mode,
+			password_hash,
+			expires_at,
+			max_uses,
+			use_count,
+			created_at
+		FROM share_links
+`
+
+func GetShareLinkByToken(token string) (types.ShareLink, error) {
+	return getShareLink(selectShareLinkColumns+"WHERE token = ?", token)
+}
+
+func GetShareLinkByID(id int) (types.ShareLink, error) {
+	return getShareLink(selectShareLinkColumns+"WHERE id = ?", id)
+}
+
+// GetShareLinks returns the links of the user, or the links of all users if username is empty.
+func GetShareLinks(username string) ([]types.ShareLink, error) {
+	query := selectShareLinkColumns + "ORDER BY created_at DESC"
+	args := []any{}
+	if username != "" {
+		query = selectShareLinkColumns + "WHERE username = ? ORDER BY created_at DESC"
+		args = append(args, username)
+	}
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing db query: %w", err)
+	}
+	defer rows.Close()
+
+	links := []types.ShareLink{}
+	for rows.Next() {
+		var link types.ShareLink
+		if err := scanShareLink(rows, &link); err != nil {
+			return nil, fmt.Errorf("error scanning share link: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+func getShareLink(query string, arg any) (types.ShareLink, error) {
+	var link types.ShareLink
+
+	err := scanShareLink(database.DB.QueryRow(query, arg), &link)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.ShareLink{}, sql.ErrNoRows
+		}
+		return types.ShareLink{}, err
+	}
+
+	return link, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanShareLink(row rowScanner, link *types.ShareLink) error {
+	var expiresAt sql.NullTime
+
+	err := row.Scan(
+		&link.ID,
+		&link.Token,
+		&link.Username,
+		&link.Path,
+		&link.IsDirectory,
+		&link.Mode,
+		&link.PasswordHash,
+		&expiresAt,
+		&link.MaxUses,
+		&link.UseCount,
+		&link.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if expiresAt.Valid {
+		link.ExpiresAt = &expiresAt.Time
+	}
+	link.HasPassword = link.PasswordHash != ""
+
+	return nil
+}
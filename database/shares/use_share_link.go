@@ -0,0 +1,26 @@
+package shares
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+// UseShareLink counts one download or upload. It returns false if the use limit is already reached,
+// checking and counting in one statement keeps parallel downloads from going over the limit.
+func UseShareLink(id int) (bool, error) {
+	result, err := database.DB.Exec(
+		"UPDATE share_links SET use_count = use_count + 1 WHERE id = ? AND (max_uses = 0 OR use_count < max_uses);",
+		id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error reading affected rows: %w", err)
+	}
+
+	return affected == 1, nil
+}
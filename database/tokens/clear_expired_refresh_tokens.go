@@ -0,0 +1,18 @@
+package tokens
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+func ClearExpiredRefreshTokens() error {
+	_, err := database.DB.Exec("DELETE FROM refresh_tokens WHERE expires_at < ?;", time.Now().UTC())
+
+	if err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+// CreateRefreshToken stores a new refresh token and returns its plain value. Only the hash is saved.
+// Rotated tokens keep the familyID of the token they replace, so reuse of an old token can revoke the whole chain.
+func CreateRefreshToken(account types.Account, familyID string, expiresAt time.Time) (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("random read failed: %w", err)
+	}
+	token := hex.EncodeToString(bytes)
+
+	var userID int
+	if account.ID != nil {
+		userID = *account.ID
+	}
+
+	_, err := database.DB.Exec(`
+		INSERT INTO refresh_tokens(
+			username,
+			user_id,
+			token_hash,
+			family_id,
+			token_version,
+			expires_at
+		) VALUES(?, ?, ?, ?, ?, ?)
+	`,
+		account.Username,
+		userID,
+		hashToken(token),
+		familyID,
+		account.TokenVersion,
+		expiresAt.UTC(),
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	return token, nil
+}
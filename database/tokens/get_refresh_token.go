@@ -0,0 +1,45 @@
+package tokens
+
+import (
+	"database/sql"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func GetRefreshToken(token string) (types.RefreshToken, error) {
+	const query = `
+		SELECT
+			id,
+			username,
+			user_id,
+			family_id,
+			token_version,
+			revoked,
+			expires_at,
+			created_at
+		FROM refresh_tokens
+		WHERE token_hash = ?
+	`
+
+	var refreshToken types.RefreshToken
+
+	err := database.DB.QueryRow(query, hashToken(token)).Scan(
+		&refreshToken.ID,
+		&refreshToken.Username,
+		&refreshToken.UserID,
+		&refreshToken.FamilyID,
+		&refreshToken.TokenVersion,
+		&refreshToken.Revoked,
+		&refreshToken.ExpiresAt,
+		&refreshToken.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.RefreshToken{}, sql.ErrNoRows
+		}
+		return types.RefreshToken{}, err
+	}
+
+	return refreshToken, nil
+}
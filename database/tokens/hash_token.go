@@ -0,0 +1,12 @@
+package tokens
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Refresh tokens are random 256 bit values, so a plain SHA-256 is enough to keep them useless if the database leaks.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,32 @@
+package tokens
+
+import (
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+// RevokeRefreshToken revokes the token if it isn't revoked yet. False means another request revoked it first,
+// so the token was used twice.
+func RevokeRefreshToken(id int) (bool, error) {
+	result, err := database.DB.Exec("UPDATE refresh_tokens SET revoked = TRUE WHERE id = ? AND revoked = FALSE;", id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func RevokeRefreshTokenFamily(familyID string) error {
+	_, err := database.DB.Exec("UPDATE refresh_tokens SET revoked = TRUE WHERE family_id = ?;", familyID)
+	return err
+}
+
+// RevokeUserRefreshTokens revokes the tokens of the user. The username of old tokens can differ after a rename,
+// tokens from before the user_id column only have the username.
+func RevokeUserRefreshTokens(userID int, username string) error {
+	_, err := database.DB.Exec("UPDATE refresh_tokens SET revoked = TRUE WHERE user_id = ? OR username = ?;", userID, username)
+	return err
+}
@@ -0,0 +1,54 @@
+package twofactor
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/totp"
+)
+
+var (
+	ErrCodeRequired  = errors.New("two factor code required")
+	ErrSetupRequired = errors.New("two factor setup required")
+	ErrWrongCode     = errors.New("wrong two factor code")
+)
+
+// CheckSecondFactor must be called after the password was verified and before any token is created.
+// The code can be a TOTP code or one of the recovery codes.
+func CheckSecondFactor(account *types.Account, code string) error {
+	if !account.TwoFactorEnabled {
+		if account.TwoFactorRequired {
+			return ErrSetupRequired
+		}
+		return nil
+	}
+
+	if code == "" {
+		return ErrCodeRequired
+	}
+
+	if VerifyCode(account, code) {
+		return nil
+	}
+
+	return ErrWrongCode
+}
+
+func VerifyCode(account *types.Account, code string) bool {
+	if account.ID == nil || account.TOTPSecret == "" {
+		return false
+	}
+
+	if step, ok := totp.Validate(account.TOTPSecret, code, time.Now(), account.TOTPLastStep); ok {
+		used, err := UseStep(*account.ID, step)
+		if err != nil || !used {
+			return false
+		}
+		account.TOTPLastStep = step
+		return true
+	}
+
+	used, err := UseRecoveryCode(account.Username, code)
+	return err == nil && used
+}
@@ -0,0 +1,98 @@
+package twofactor
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+const recoveryCodeCount = 10
+
+// ReplaceRecoveryCodes deletes the old codes of the user and returns new ones. Only hashes are stored,
+// so the codes can't be shown again later.
+func ReplaceRecoveryCodes(username string) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM two_factor_recovery_codes WHERE username = ?;", username); err != nil {
+		return nil, fmt.Errorf("error deleting recovery codes: %w", err)
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO two_factor_recovery_codes(username, code_hash) VALUES(?, ?);")
+	if err != nil {
+		return nil, fmt.Errorf("error creating db stmt: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := stmt.Exec(username, hashRecoveryCode(code)); err != nil {
+			return nil, fmt.Errorf("error executing db stmt: %w", err)
+		}
+
+		codes = append(codes, code)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit error: %w", err)
+	}
+
+	return codes, nil
+}
+
+// UseRecoveryCode consumes the code if it's valid and unused.
+func UseRecoveryCode(username string, code string) (bool, error) {
+	result, err := database.DB.Exec(
+		"UPDATE two_factor_recovery_codes SET used = TRUE WHERE username = ? AND code_hash = ? AND used = FALSE;",
+		username,
+		hashRecoveryCode(code),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func CountUnusedRecoveryCodes(username string) (int, error) {
+	var count int
+	err := database.DB.QueryRow("SELECT COUNT(*) FROM two_factor_recovery_codes WHERE username = ? AND used = FALSE;", username).Scan(&count)
+	return count, err
+}
+
+// Codes look like "abcd-efgh-ijkl-mnop" (80 bit)
+func generateRecoveryCode() (string, error) {
+	bytes := make([]byte, 10)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("random read failed: %w", err)
+	}
+
+	raw := strings.ToLower(base32.StdEncoding.EncodeToString(bytes))
+
+	return fmt.Sprintf("%s-%s-%s-%s", raw[0:4], raw[4:8], raw[8:12], raw[12:16]), nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])
+}
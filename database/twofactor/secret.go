@@ -0,0 +1,76 @@
+package twofactor
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+// SavePendingSecret stores the secret of a started setup. The active secret and the recovery codes
+// are kept until Enable is called with a confirmed code.
+func SavePendingSecret(id int, secret string) error {
+	_, err := database.DB.Exec("UPDATE users SET totp_pending_secret = ? WHERE id = ?;", secret, id)
+	return err
+}
+
+// Enable makes the pending secret the active one, step is the step of the confirmed code. It returns false
+// if the pending secret isn't secret anymore, like when the setup was started again meanwhile.
+func Enable(id int, secret string, step int64) (bool, error) {
+	const query = `
+		UPDATE users SET
+			totp_secret = totp_pending_secret,
+			totp_pending_secret = NULL,
+			totp_enabled = TRUE,
+			totp_last_step = ?
+		WHERE id = ? AND totp_pending_secret = ?;
+	`
+
+	result, err := database.DB.Exec(query, step, id, secret)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func Disable(id int, username string) error {
+	const query = `
+		UPDATE users SET
+			totp_secret = NULL,
+			totp_pending_secret = NULL,
+			totp_enabled = FALSE,
+			totp_last_step = 0
+		WHERE id = ?;
+	`
+
+	if _, err := database.DB.Exec(query, id); err != nil {
+		return err
+	}
+
+	if _, err := database.DB.Exec("DELETE FROM two_factor_recovery_codes WHERE username = ?;", username); err != nil {
+		return fmt.Errorf("error deleting recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+// UseStep marks the TOTP step as used. It fails when the same or a later step was already used,
+// which blocks replaying a code even if two requests race each other.
+func UseStep(id int, step int64) (bool, error) {
+	result, err := database.DB.Exec("UPDATE users SET totp_last_step = ? WHERE id = ? AND totp_last_step < ?;", step, id, step)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
@@ -0,0 +1,36 @@
+package uploads
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func CreateUpload(upload types.Upload) error {
+	_, err := database.DB.Exec(`
+		INSERT INTO uploads(
+			id,
+			username,
+			path,
+			length,
+			upload_offset,
+			metadata,
+			expires_at
+		) VALUES(?, ?, ?, ?, ?, ?, ?)
+	`,
+		upload.ID,
+		upload.Username,
+		upload.Path,
+		upload.Length,
+		upload.Offset,
+		upload.Metadata,
+		upload.ExpiresAt.UTC(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	return nil
+}
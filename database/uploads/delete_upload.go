@@ -0,0 +1,10 @@
+package uploads
+
+import (
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+func DeleteUpload(id string) error {
+	_, err := database.DB.Exec("DELETE FROM uploads WHERE id = ?;", id)
+	return err
+}
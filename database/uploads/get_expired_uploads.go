@@ -0,0 +1,48 @@
+package uploads
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func GetExpiredUploads() ([]types.Upload, error) {
+	rows, err := database.DB.Query(selectUploadColumns+"WHERE expires_at < ?", time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("error executing db query: %w", err)
+	}
+	defer rows.Close()
+
+	var expired []types.Upload
+	for rows.Next() {
+		var upload types.Upload
+		if err := scanUpload(rows, &upload); err != nil {
+			return nil, fmt.Errorf("error scanning upload: %w", err)
+		}
+		expired = append(expired, upload)
+	}
+
+	return expired, rows.Err()
+}
+
+// GetUploadIDs returns the IDs of all known uploads, including expired ones.
+func GetUploadIDs() (map[string]bool, error) {
+	rows, err := database.DB.Query("SELECT id FROM uploads;")
+	if err != nil {
+		return nil, fmt.Errorf("error executing db query: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning upload: %w", err)
+		}
+		ids[id] = true
+	}
+
+	return ids, rows.Err()
+}
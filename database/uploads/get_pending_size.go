@@ -0,0 +1,26 @@
+package uploads
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+// GetPendingSize returns the total length of the unexpired uploads of the user. Their data lives outside
+// of the host folder until they are finished, so quota checks have to reserve it.
+func GetPendingSize(username string) (int64, error) {
+	var size int64
+
+	err := database.DB.QueryRow(
+		"SELECT COALESCE(SUM(length), 0) FROM uploads WHERE username = ? AND expires_at >= ?;",
+		username,
+		time.Now().UTC(),
+	).Scan(&size)
+
+	if err != nil {
+		return 0, fmt.Errorf("error executing db query: %w", err)
+	}
+
+	return size, nil
+}
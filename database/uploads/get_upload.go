@@ -0,0 +1,52 @@
+package uploads
+
+import (
+	"database/sql"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+const selectUploadColumns = `
+		SELECT
+			id,
+			username,
+			path,
+			length,
+			upload_offset,
+			metadata,
+			expires_at,
+			created_at
+		FROM uploads
+`
+
+func GetUpload(id string) (types.Upload, error) {
+	var upload types.Upload
+
+	err := scanUpload(database.DB.QueryRow(selectUploadColumns+"WHERE id = ?", id), &upload)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.Upload{}, sql.ErrNoRows
+		}
+		return types.Upload{}, err
+	}
+
+	return upload, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUpload(row rowScanner, upload *types.Upload) error {
+	return row.Scan(
+		&upload.ID,
+		&upload.Username,
+		&upload.Path,
+		&upload.Length,
+		&upload.Offset,
+		&upload.Metadata,
+		&upload.ExpiresAt,
+		&upload.CreatedAt,
+	)
+}
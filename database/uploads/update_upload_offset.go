@@ -0,0 +1,24 @@
+package uploads
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+// UpdateUploadOffset saves the new offset and pushes the expiration forward, so active uploads don't expire.
+func UpdateUploadOffset(id string, offset int64, expiresAt time.Time) error {
+	_, err := database.DB.Exec(
+		"UPDATE uploads SET upload_offset = ?, expires_at = ? WHERE id = ?;",
+		offset,
+		expiresAt.UTC(),
+		id,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	return nil
+}
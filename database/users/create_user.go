@@ -1,13 +1,12 @@
 package users
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
 	"log"
 
 	"github.com/MertJSX/folder-host-go/database"
 	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/password"
 )
 
 func CreateUser(user *types.Account) error {
@@ -45,8 +44,10 @@ func CreateUser(user *types.Account) error {
 			read_recovery_permission,
 			use_recovery_permission,
 			read_users_permission,
-			edit_users_permission
-		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			edit_users_permission,
+			two_factor_required,
+			quota
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 
 	if err != nil {
@@ -55,8 +56,10 @@ func CreateUser(user *types.Account) error {
 
 	defer stmt.Close()
 
-	hash := sha256.Sum256([]byte(user.Password))
-	hashString := hex.EncodeToString(hash[:])
+	hashString, err := password.Hash(user.Password)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
 
 	_, err = stmt.Exec(
 		user.Username,
@@ -80,6 +83,8 @@ func CreateUser(user *types.Account) error {
 		user.Permissions.UseRecovery,
 		user.Permissions.ReadUsers,
 		user.Permissions.EditUsers,
+		user.TwoFactorRequired,
+		user.Quota,
 	)
 
 	if err != nil {
@@ -2,6 +2,7 @@ package users
 
 import (
 	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/database/groups"
 	"github.com/MertJSX/folder-host-go/types"
 )
 
@@ -27,9 +28,17 @@ func GetAll() ([]types.Account, error) {
 			use_recovery_permission,
 			read_users_permission,
 			edit_users_permission,
-			logs_permission
+			logs_permission,
+			two_factor_required,
+			totp_enabled,
+			quota
 		FROM users ORDER BY id;`
 
+	memberships, err := groups.GetMemberships()
+	if err != nil {
+		return nil, err
+	}
+
 	rows, err := database.DB.Query(query)
 	if err != nil {
 		return nil, err
@@ -62,12 +71,16 @@ func GetAll() ([]types.Account, error) {
 			&u.Permissions.ReadUsers,
 			&u.Permissions.EditUsers,
 			&u.Permissions.ReadLogs,
+			&u.TwoFactorRequired,
+			&u.TwoFactorEnabled,
+			&u.Quota,
 		)
 		if err != nil {
 			return nil, err
 		}
 
 		u.Password = ""
+		u.Groups = memberships[u.Username]
 
 		users = append(users, u)
 	}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 
 	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/database/acl"
+	"github.com/MertJSX/folder-host-go/database/groups"
 	"github.com/MertJSX/folder-host-go/types"
 )
 
@@ -31,7 +33,14 @@ func GetUserByUsername(username string) (types.Account, error) {
 			use_recovery_permission,
 			read_users_permission,
 			edit_users_permission,
-			logs_permission
+			logs_permission,
+			token_version,
+			two_factor_required,
+			totp_enabled,
+			COALESCE(totp_secret, ''),
+			COALESCE(totp_pending_secret, ''),
+			totp_last_step,
+			quota
 		FROM users
 		WHERE username = ?
 	`
@@ -63,6 +72,13 @@ func GetUserByUsername(username string) (types.Account, error) {
 		&u.Permissions.ReadUsers,
 		&u.Permissions.EditUsers,
 		&u.Permissions.ReadLogs,
+		&u.TokenVersion,
+		&u.TwoFactorRequired,
+		&u.TwoFactorEnabled,
+		&u.TOTPSecret,
+		&u.TOTPPendingSecret,
+		&u.TOTPLastStep,
+		&u.Quota,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -71,5 +87,27 @@ func GetUserByUsername(username string) (types.Account, error) {
 		return types.Account{}, err
 	}
 
+	u.ACL, err = acl.GetRules(u.Username)
+	if err != nil {
+		return types.Account{}, err
+	}
+
+	u.PermissionOverrides, err = GetPermissionOverrides(u.Username)
+	if err != nil {
+		return types.Account{}, err
+	}
+
+	userGroups, err := groups.GetUserGroups(u.Username)
+	if err != nil {
+		return types.Account{}, err
+	}
+
+	u.Groups = make([]string, 0, len(userGroups))
+	for _, group := range userGroups {
+		u.Groups = append(u.Groups, group.Name)
+	}
+
+	u.ApplyGroups(userGroups)
+
 	return u, nil
 }
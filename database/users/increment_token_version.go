@@ -0,0 +1,17 @@
+package users
+
+import (
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+// IncrementTokenVersion invalidates every access token issued for the user so far.
+func IncrementTokenVersion(id int) error {
+	const query = `
+		UPDATE users SET
+			token_version = token_version + 1
+		WHERE id = ?;
+	`
+
+	_, err := database.DB.Exec(query, id)
+	return err
+}
@@ -0,0 +1,65 @@
+package users
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func GetPermissionOverrides(username string) (map[string]bool, error) {
+	overrides := make(map[string]bool)
+
+	rows, err := database.DB.Query("SELECT permission, allow FROM user_permission_overrides WHERE username = ?;", username)
+	if err != nil {
+		return nil, fmt.Errorf("error getting permission overrides: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var permission string
+		var allow bool
+		if err := rows.Scan(&permission, &allow); err != nil {
+			return nil, fmt.Errorf("error scanning permission override: %w", err)
+		}
+		overrides[permission] = allow
+	}
+
+	return overrides, rows.Err()
+}
+
+// SetPermissionOverrides replaces the overrides of the user. They win against the permissions of the groups.
+func SetPermissionOverrides(username string, overrides map[string]bool) error {
+	for permission := range overrides {
+		if _, ok := (types.AccountPermissions{}).Get(permission); !ok {
+			return fmt.Errorf("unknown permission %q", permission)
+		}
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM user_permission_overrides WHERE username = ?;", username); err != nil {
+		return fmt.Errorf("error deleting permission overrides: %w", err)
+	}
+
+	for permission, allow := range overrides {
+		if _, err := tx.Exec(
+			"INSERT INTO user_permission_overrides(username, permission, allow) VALUES(?, ?, ?);",
+			username,
+			permission,
+			allow,
+		); err != nil {
+			return fmt.Errorf("error adding permission override: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit error: %w", err)
+	}
+
+	return nil
+}
@@ -1,12 +1,27 @@
 package users
 
 import (
+	"fmt"
+
 	"github.com/MertJSX/folder-host-go/database"
 )
 
+// RemoveUser removes the user and its refresh tokens. The foreign key can't be relied on for them,
+// PRAGMA foreign_keys is only enabled on one connection of the pool.
 func RemoveUser(id int) error {
-	const query = `DELETE FROM users WHERE id = ?;`
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM refresh_tokens WHERE user_id = ? OR username = (SELECT username FROM users WHERE id = ?);`, id, id); err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM users WHERE id = ?;`, id); err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
 
-	_, err := database.DB.Exec(query, id)
-	return err
+	return tx.Commit()
 }
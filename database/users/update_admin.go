@@ -1,11 +1,9 @@
 package users
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-
 	"github.com/MertJSX/folder-host-go/database"
 	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/password"
 )
 
 func UpdateAdmin(user *types.Account) error {
@@ -31,14 +29,18 @@ func UpdateAdmin(user *types.Account) error {
 			use_recovery_permission = ?,
 			read_users_permission = ?,
 			edit_users_permission = ?,
-			logs_permission = ?
+			logs_permission = ?,
+			two_factor_required = ?,
+			quota = ?
 		WHERE id = 1
 	`
 
-	hash := sha256.Sum256([]byte(user.Password))
-	hashString := hex.EncodeToString(hash[:])
+	hashString, err := password.Hash(user.Password)
+	if err != nil {
+		return err
+	}
 
-	_, err := database.DB.Exec(
+	_, err = database.DB.Exec(
 		query,
 		user.Username,
 		hashString,
@@ -61,6 +63,8 @@ func UpdateAdmin(user *types.Account) error {
 		user.Permissions.ReadUsers,
 		user.Permissions.EditUsers,
 		user.Permissions.ReadLogs,
+		user.TwoFactorRequired,
+		user.Quota,
 	)
 
 	return err
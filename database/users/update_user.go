@@ -27,7 +27,9 @@ func UpdateUser(id int, user *types.Account) error {
 			use_recovery_permission = ?,
 			read_users_permission = ?,
 			edit_users_permission = ?,
-			logs_permission = ?
+			logs_permission = ?,
+			two_factor_required = ?,
+			quota = ?
 		WHERE id = ?;
 	`
 
@@ -53,6 +55,8 @@ func UpdateUser(id int, user *types.Account) error {
 		user.Permissions.ReadUsers,
 		user.Permissions.EditUsers,
 		user.Permissions.ReadLogs,
+		user.TwoFactorRequired,
+		user.Quota,
 		id,
 	)
 
@@ -1,10 +1,8 @@
 package users
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-
 	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/utils/password"
 )
 
 func UpdateUserPassword(id int, newPassword string) error {
@@ -14,10 +12,12 @@ func UpdateUserPassword(id int, newPassword string) error {
 		WHERE id = ?;
 	`
 
-	hash := sha256.Sum256([]byte(newPassword))
-	passwordHashString := hex.EncodeToString(hash[:])
+	passwordHashString, err := password.Hash(newPassword)
+	if err != nil {
+		return err
+	}
 
-	_, err := database.DB.Exec(
+	_, err = database.DB.Exec(
 		query,
 		passwordHashString,
 		id,
@@ -0,0 +1,29 @@
+package users
+
+import (
+	"log"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/password"
+)
+
+// VerifyPassword checks the password of the account and upgrades legacy or outdated hashes in place.
+func VerifyPassword(account *types.Account, plainPassword string) bool {
+	ok, needsRehash := password.Verify(plainPassword, account.Password)
+	if !ok {
+		return false
+	}
+
+	if needsRehash && account.ID != nil {
+		if err := UpdateUserPassword(*account.ID, plainPassword); err != nil {
+			log.Printf("Error while upgrading password hash of %s: %v\n", account.Username, err)
+			return true
+		}
+
+		if upgradedAccount, err := GetUserByUsername(account.Username); err == nil {
+			account.Password = upgradedAccount.Password
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,40 @@
+package versions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+func CreateVersion(version *types.FileVersion) error {
+	result, err := database.DB.Exec(`
+		INSERT INTO file_versions(
+			path,
+			location,
+			username,
+			size_display,
+			size_bytes
+		) VALUES(?, ?, ?, ?, ?)
+	`,
+		version.Path,
+		version.Location,
+		version.Username,
+		version.SizeDisplay,
+		version.SizeBytes,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error executing db stmt: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error reading version id: %w", err)
+	}
+	version.ID = int(id)
+	version.CreatedAt = time.Now().UTC()
+
+	return nil
+}
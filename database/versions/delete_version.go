@@ -0,0 +1,10 @@
+package versions
+
+import (
+	"github.com/MertJSX/folder-host-go/database"
+)
+
+func DeleteVersion(id int) error {
+	_, err := database.DB.Exec("DELETE FROM file_versions WHERE id = ?;", id)
+	return err
+}
@@ -0,0 +1,94 @@
+package versions
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+const selectVersionColumns = `
+		SELECT
+			id,
+			path,
+			location,
+			username,
+			size_display,
+			size_bytes,
+			created_at
+		FROM file_versions
+`
+
+func GetVersionByID(id int) (types.FileVersion, error) {
+	var version types.FileVersion
+
+	err := scanVersion(database.DB.QueryRow(selectVersionColumns+"WHERE id = ?", id), &version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.FileVersion{}, sql.ErrNoRows
+		}
+		return types.FileVersion{}, err
+	}
+
+	return version, nil
+}
+
+// GetVersionsByPath returns the versions of a file, the newest first.
+func GetVersionsByPath(path string) ([]types.FileVersion, error) {
+	return getVersions(selectVersionColumns+"WHERE path = ? ORDER BY id DESC", path)
+}
+
+// GetVersionsCreatedBefore returns the versions older than the date, the oldest first.
+func GetVersionsCreatedBefore(date time.Time) ([]types.FileVersion, error) {
+	return getVersions(selectVersionColumns+"WHERE created_at < ? ORDER BY id", date.UTC().Format(time.DateTime))
+}
+
+// GetAllVersions returns every version, the oldest first.
+func GetAllVersions() ([]types.FileVersion, error) {
+	return getVersions(selectVersionColumns + "ORDER BY id")
+}
+
+func GetVersionsSize() (int64, error) {
+	var size int64
+	if err := database.DB.QueryRow("SELECT COALESCE(SUM(size_bytes), 0) FROM file_versions").Scan(&size); err != nil {
+		return 0, fmt.Errorf("error executing db query: %w", err)
+	}
+	return size, nil
+}
+
+func getVersions(query string, args ...any) ([]types.FileVersion, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing db query: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []types.FileVersion{}
+	for rows.Next() {
+		var version types.FileVersion
+		if err := scanVersion(rows, &version); err != nil {
+			return nil, fmt.Errorf("error scanning version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanVersion(row rowScanner, version *types.FileVersion) error {
+	return row.Scan(
+		&version.ID,
+		&version.Path,
+		&version.Location,
+		&version.Username,
+		&version.SizeDisplay,
+		&version.SizeBytes,
+		&version.CreatedAt,
+	)
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"runtime"
+	"strings"
 
 	"github.com/MertJSX/folder-host-go/database/initialize"
 	"github.com/MertJSX/folder-host-go/middleware"
@@ -17,6 +18,12 @@ import (
 	"github.com/MertJSX/folder-host-go/utils"
 	"github.com/MertJSX/folder-host-go/utils/cache"
 	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/davfs"
+	"github.com/MertJSX/folder-host-go/utils/jobs"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/MertJSX/folder-host-go/utils/search"
+	"github.com/MertJSX/folder-host-go/utils/sftpserver"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
 	"github.com/MertJSX/folder-host-go/utils/tasks"
 	"github.com/fatih/color"
 	"github.com/gofiber/contrib/websocket"
@@ -30,10 +37,24 @@ import (
 var FrontendFS embed.FS
 
 func main() {
+	const bodyLimit = 10 * 1024 * 1024 // 10 MB
+
 	app := fiber.New(fiber.Config{
-		BodyLimit:             10 * 1024 * 1024, // 10 MB
+		BodyLimit:             bodyLimit,
 		AppName:               "FolderHost",
 		DisableStartupMessage: true,
+		// WebDAV and S3 clients upload whole files with PUT, bigger bodies are streamed and LimitBody keeps the
+		// limit for the other routes
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+		RequestMethods:               append(append([]string{}, fiber.DefaultMethods...), "PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK"),
+	})
+
+	app.Use(func(c *fiber.Ctx) error {
+		if isStreamedPath(c.Path()) {
+			return c.Next()
+		}
+		return middleware.LimitBody(c, bodyLimit)
 	})
 
 	app.Use(compress.New(compress.Config{
@@ -42,26 +63,44 @@ func main() {
 			skipRoutes := []string{
 				"/api/explorer/download",
 				"/api/upload",
+				"/download",
 			}
 			for _, route := range skipRoutes {
 				if c.Path() == route {
 					return true
 				}
 			}
-			return false
+			// Files are sent with their Content-Length and may be ranges
+			return strings.HasPrefix(c.Path(), "/s/") || strings.HasPrefix(c.Path(), "/api/image/") ||
+				strings.HasPrefix(c.Path(), "/api/versions/download/") || isStreamedPath(c.Path())
 		},
 	}))
 
-	app.Use(cors.New())
+	app.Use(cors.New(cors.Config{
+		// tus clients read these headers from the responses
+		ExposeHeaders: "Location,Upload-Offset,Upload-Length,Upload-Metadata,Upload-Expires,Tus-Resumable,Tus-Version,Tus-Extension",
+	}))
 
 	utils.Setup()
 	utils.GetConfig()
 	initialize.InitializeDatabase()
+	search.Start()
+	sizeindex.Start()
+	jobs.OnUpdate(fhWS.SendJobUpdate)
+	jobs.Start()
 
 	go cache.ListenDirectorySetCacheEvents()
 	go tasks.AutoClearOldLogs()
+	go tasks.AutoClearExpiredRefreshTokens()
+	go tasks.AutoClearExpiredUploads()
+	go tasks.AutoClearOldVersions()
+	go tasks.AutoClearFinishedJobs()
 
 	config := &config.Config
+	if config.SFTP.Enabled {
+		go sftpserver.Start()
+	}
+
 	var portInt int = config.Port
 	if portInt == 0 || !utils.IsPortAvailable(portInt) {
 		log.Printf("Your port %d is busy! Searching for another port...", portInt)
@@ -88,10 +127,125 @@ func main() {
 		return routes.Download(c)
 	})
 
+	// Share links are public, they check their own password
+	app.Get("/s/:token", func(c *fiber.Ctx) error {
+		return routes.Share(c)
+	})
+
+	app.Post("/s/:token", func(c *fiber.Ctx) error {
+		return routes.ShareUpload(c)
+	})
+
+	// WebDAV clients send their password with every request
+	app.Use("/dav", func(c *fiber.Ctx) error {
+		return middleware.CheckBasicAuth(c)
+	})
+
+	app.Use("/dav", func(c *fiber.Ctx) error {
+		return routes.WebDAV(c)
+	})
+
+	// S3 clients sign every request with an access key of the user, the bucket is the scope of the user
+	if config.S3Gateway {
+		app.Use(s3.Prefix, func(c *fiber.Ctx) error {
+			return middleware.CheckS3Auth(c)
+		})
+
+		app.Get(s3.Prefix, func(c *fiber.Ctx) error {
+			return routes.S3ListBuckets(c)
+		})
+
+		app.Head(s3.Prefix+"/:bucket", func(c *fiber.Ctx) error {
+			return routes.S3HeadBucket(c)
+		})
+
+		app.Get(s3.Prefix+"/:bucket", func(c *fiber.Ctx) error {
+			return routes.S3ListObjects(c)
+		})
+
+		app.Post(s3.Prefix+"/:bucket", func(c *fiber.Ctx) error {
+			return routes.S3DeleteObjects(c)
+		})
+
+		app.Get(s3.Prefix+"/:bucket/*", func(c *fiber.Ctx) error {
+			return routes.S3GetObject(c)
+		})
+
+		app.Head(s3.Prefix+"/:bucket/*", func(c *fiber.Ctx) error {
+			return routes.S3GetObject(c)
+		})
+
+		app.Put(s3.Prefix+"/:bucket/*", func(c *fiber.Ctx) error {
+			if c.Query("uploadId") != "" {
+				return routes.S3UploadPart(c)
+			}
+			return routes.S3PutObject(c)
+		})
+
+		app.Post(s3.Prefix+"/:bucket/*", func(c *fiber.Ctx) error {
+			if c.Request().URI().QueryArgs().Has("uploads") {
+				return routes.S3CreateMultipartUpload(c)
+			}
+			if c.Query("uploadId") != "" {
+				return routes.S3CompleteMultipartUpload(c)
+			}
+			return s3.SendError(c, s3.ErrNotImplemented)
+		})
+
+		app.Delete(s3.Prefix+"/:bucket/*", func(c *fiber.Ctx) error {
+			if c.Query("uploadId") != "" {
+				return routes.S3AbortMultipartUpload(c)
+			}
+			return routes.S3DeleteObject(c)
+		})
+
+		app.Use(s3.Prefix, func(c *fiber.Ctx) error {
+			return s3.SendError(c, s3.ErrNotImplemented)
+		})
+	}
+
+	// Auth routes are registered before CheckAuth, they authenticate with the request body.
+	app.Post("/api/auth/login", func(c *fiber.Ctx) error {
+		return routes.Login(c)
+	})
+
+	app.Post("/api/auth/refresh", func(c *fiber.Ctx) error {
+		return routes.RefreshToken(c)
+	})
+
+	app.Post("/api/auth/logout", func(c *fiber.Ctx) error {
+		return routes.Logout(c)
+	})
+
+	app.Post("/api/auth/2fa/setup", func(c *fiber.Ctx) error {
+		return routes.TwoFactorSetup(c)
+	})
+
+	app.Post("/api/auth/2fa/enable", func(c *fiber.Ctx) error {
+		return routes.TwoFactorEnable(c)
+	})
+
+	app.Post("/api/auth/2fa/disable", func(c *fiber.Ctx) error {
+		return routes.TwoFactorDisable(c)
+	})
+
+	app.Post("/api/auth/2fa/recovery-codes", func(c *fiber.Ctx) error {
+		return routes.TwoFactorRecoveryCodes(c)
+	})
+
+	// tus clients discover the server with OPTIONS before they have credentials
+	app.Options("/api/tus", func(c *fiber.Ctx) error {
+		return routes.TusOptions(c)
+	})
+
 	app.Use("/api", func(c *fiber.Ctx) error {
 		return middleware.CheckAuth(c)
 	})
 
+	app.Use("/api/tus", func(c *fiber.Ctx) error {
+		return middleware.CheckTusResumable(c)
+	})
+
 	app.Get("/api/user-info", func(c *fiber.Ctx) error {
 		return c.Status(200).JSON(fiber.Map{
 			"username": c.Locals("account").(types.Account).Username,
@@ -114,18 +268,46 @@ func main() {
 		return routes.GetPermissions(c)
 	})
 
+	app.Get("/api/quota", func(c *fiber.Ctx) error {
+		return routes.GetQuota(c)
+	})
+
 	app.Get("/api/explorer/read-dir", func(c *fiber.Ctx) error {
 		return routes.ReadDirectory(c)
 	})
 
+	app.Get("/api/explorer/search", func(c *fiber.Ctx) error {
+		return routes.Search(c)
+	})
+
 	app.Get("/api/explorer/get-download-link", func(c *fiber.Ctx) error {
 		return routes.GetDownloadLink(c)
 	})
 
+	app.Post("/api/explorer/get-archive-link", func(c *fiber.Ctx) error {
+		return routes.GetArchiveLink(c)
+	})
+
 	app.Post("/api/upload", func(c *fiber.Ctx) error {
 		return routes.ChunkedUpload(c)
 	})
 
+	app.Post("/api/tus", func(c *fiber.Ctx) error {
+		return routes.TusCreateUpload(c)
+	})
+
+	app.Head("/api/tus/:id", func(c *fiber.Ctx) error {
+		return routes.TusUploadOffset(c)
+	})
+
+	app.Patch("/api/tus/:id", func(c *fiber.Ctx) error {
+		return routes.TusPatchUpload(c)
+	})
+
+	app.Delete("/api/tus/:id", func(c *fiber.Ctx) error {
+		return routes.TusTerminateUpload(c)
+	})
+
 	app.Delete("/api/explorer/delete", func(c *fiber.Ctx) error {
 		return routes.Delete(c)
 	})
@@ -182,6 +364,122 @@ func main() {
 		return routes.RemoveUser(c)
 	})
 
+	app.Put("/api/users/acl/:id", func(c *fiber.Ctx) error {
+		return routes.EditUserACL(c)
+	})
+
+	app.Delete("/api/users/two-factor/:id", func(c *fiber.Ctx) error {
+		return routes.ResetUserTwoFactor(c)
+	})
+
+	app.Get("/api/security/lockouts", func(c *fiber.Ctx) error {
+		return routes.Lockouts(c)
+	})
+
+	app.Delete("/api/security/lockouts", func(c *fiber.Ctx) error {
+		return routes.ClearLockouts(c)
+	})
+
+	app.Get("/api/groups", func(c *fiber.Ctx) error {
+		return routes.GetGroups(c)
+	})
+
+	app.Post("/api/groups/new", func(c *fiber.Ctx) error {
+		return routes.CreateGroup(c)
+	})
+
+	app.Put("/api/groups/edit", func(c *fiber.Ctx) error {
+		return routes.EditGroup(c)
+	})
+
+	app.Delete("/api/groups/remove/:id", func(c *fiber.Ctx) error {
+		return routes.RemoveGroup(c)
+	})
+
+	app.Get("/api/shares", func(c *fiber.Ctx) error {
+		return routes.GetShareLinks(c)
+	})
+
+	app.Post("/api/shares/new", func(c *fiber.Ctx) error {
+		return routes.CreateShareLink(c)
+	})
+
+	app.Delete("/api/shares/remove/:id", func(c *fiber.Ctx) error {
+		return routes.RemoveShareLink(c)
+	})
+
+	app.Get("/api/s3/keys", func(c *fiber.Ctx) error {
+		return routes.GetS3Keys(c)
+	})
+
+	app.Post("/api/s3/keys/new", func(c *fiber.Ctx) error {
+		return routes.CreateS3Key(c)
+	})
+
+	app.Delete("/api/s3/keys/remove/:id", func(c *fiber.Ctx) error {
+		return routes.RemoveS3Key(c)
+	})
+
+	app.Get("/api/sftp/keys", func(c *fiber.Ctx) error {
+		return routes.GetSFTPKeys(c)
+	})
+
+	app.Post("/api/sftp/keys/new", func(c *fiber.Ctx) error {
+		return routes.CreateSFTPKey(c)
+	})
+
+	app.Delete("/api/sftp/keys/remove/:id", func(c *fiber.Ctx) error {
+		return routes.RemoveSFTPKey(c)
+	})
+
+	app.Get("/api/versions", func(c *fiber.Ctx) error {
+		return routes.GetVersions(c)
+	})
+
+	app.Get("/api/versions/diff/:id", func(c *fiber.Ctx) error {
+		return routes.GetVersionDiff(c)
+	})
+
+	app.Get("/api/versions/download/:id", func(c *fiber.Ctx) error {
+		return routes.DownloadVersion(c)
+	})
+
+	app.Post("/api/versions/restore/:id", func(c *fiber.Ctx) error {
+		return routes.RestoreVersion(c)
+	})
+
+	app.Get("/api/jobs", func(c *fiber.Ctx) error {
+		return routes.GetJobs(c)
+	})
+
+	app.Post("/api/jobs/new", func(c *fiber.Ctx) error {
+		return routes.CreateJob(c)
+	})
+
+	app.Get("/api/jobs/:id", func(c *fiber.Ctx) error {
+		return routes.GetJob(c)
+	})
+
+	app.Post("/api/jobs/cancel/:id", func(c *fiber.Ctx) error {
+		return routes.CancelJob(c)
+	})
+
+	app.Post("/api/jobs/retry/:id", func(c *fiber.Ctx) error {
+		return routes.RetryJob(c)
+	})
+
+	app.Post("/api/batch", func(c *fiber.Ctx) error {
+		return routes.RunBatch(c)
+	})
+
+	app.Get("/api/archive/entries", func(c *fiber.Ctx) error {
+		return routes.GetArchiveEntries(c)
+	})
+
+	app.Get("/api/archive/read", func(c *fiber.Ctx) error {
+		return routes.ReadArchiveEntry(c)
+	})
+
 	app.Get("/api/logs", func(c *fiber.Ctx) error {
 		return routes.Logs(c)
 	})
@@ -256,3 +554,11 @@ func main() {
 		log.Fatalf("Server error: %v", err)
 	}
 }
+
+// isStreamedPath reports whether the route reads the request body as a stream, WebDAV and the S3 gateway do.
+func isStreamedPath(path string) bool {
+	if path == davfs.Prefix || strings.HasPrefix(path, davfs.Prefix+"/") {
+		return true
+	}
+	return config.Config.S3Gateway && (path == s3.Prefix || strings.HasPrefix(path, s3.Prefix+"/"))
+}
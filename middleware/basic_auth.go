@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/twofactor"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/bruteforce"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CheckBasicAuth authenticates WebDAV clients, they can only send a username and a password.
+// Accounts with two factor authentication can't use it.
+func CheckBasicAuth(c *fiber.Ctx) error {
+	username, plainPassword, ok := parseBasicAuth(c.Get("Authorization"))
+	if !ok {
+		return basicAuthRequired(c, "authorization required")
+	}
+
+	passwordSum := sha256.Sum256([]byte(plainPassword))
+
+	if credential, ok := cache.DavCredentialCache.Get(username); ok {
+		account, ok := cache.SessionCache.Get(username)
+		if ok && account.TokenVersion == credential.TokenVersion && !account.TwoFactorEnabled && !account.TwoFactorRequired &&
+			subtle.ConstantTimeCompare(passwordSum[:], credential.PasswordSum[:]) == 1 {
+			c.Locals("account", account)
+			return c.Next()
+		}
+	}
+
+	if bruteforce.Reject(c, username) {
+		return nil
+	}
+
+	account, err := users.GetUserByUsername(username)
+	if err != nil {
+		bruteforce.RegisterFailure(c.IP(), username, false, "account not found")
+		return basicAuthRequired(c, "wrong username or password")
+	}
+
+	if !users.VerifyPassword(&account, plainPassword) {
+		bruteforce.RegisterFailure(c.IP(), username, true, "wrong password")
+		return basicAuthRequired(c, "wrong username or password")
+	}
+
+	if err := twofactor.CheckSecondFactor(&account, ""); err != nil {
+		return c.Status(403).JSON(fiber.Map{"err": "WebDAV can't be used by accounts with two factor authentication"})
+	}
+
+	bruteforce.RegisterSuccess(account.Username)
+
+	cache.SessionCache.Set(account.Username, account, 30*time.Minute)
+	cache.DavCredentialCache.Set(account.Username, types.DavCredential{
+		PasswordSum:  passwordSum,
+		TokenVersion: account.TokenVersion,
+	}, 30*time.Minute)
+
+	c.Locals("account", account)
+	return c.Next()
+}
+
+func basicAuthRequired(c *fiber.Ctx, message string) error {
+	c.Set("WWW-Authenticate", `Basic realm="FolderHost", charset="UTF-8"`)
+	return c.Status(401).JSON(fiber.Map{"err": message})
+}
+
+func parseBasicAuth(header string) (string, string, bool) {
+	encoded, ok := strings.CutPrefix(header, "Basic ")
+	if !ok {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", "", false
+	}
+
+	username, plainPassword, ok := strings.Cut(string(decoded), ":")
+	if !ok || username == "" {
+		return "", "", false
+	}
+
+	return username, plainPassword, true
+}
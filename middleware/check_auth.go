@@ -1,8 +1,7 @@
 package middleware
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"strings"
 	"time"
 
 	"github.com/MertJSX/folder-host-go/database/users"
@@ -14,10 +13,6 @@ import (
 
 func CheckAuth(c *fiber.Ctx) error {
 	var body map[string]interface{}
-	var controlPassword bool = false
-	var username string = ""
-	var password string = ""
-	var err error
 	var token string
 
 	c.BodyParser(&body)
@@ -53,10 +48,11 @@ func CheckAuth(c *fiber.Ctx) error {
 		return c.Status(403).JSON(fiber.Map{"err": "forbidden"})
 	}
 
-	token = c.Get("Authorization")
+	token = strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
 
 	reqUsername, hasUsername := body["username"].(string)
 	reqPassword, hasPassword := body["password"].(string)
+	reqCode, _ := body["code"].(string)
 
 	if token == "" && (!hasUsername || !hasPassword || reqUsername == "" || reqPassword == "") {
 		token = c.Cookies("token")
@@ -65,43 +61,43 @@ func CheckAuth(c *fiber.Ctx) error {
 		}
 	}
 
-	if token != "" {
-		username, err = utils.VerifyToken(token, config.Config.SecretJwtKey)
-		if err != nil {
-			return c.Status(401).JSON(fiber.Map{"err": "invalid token"})
+	if token == "" {
+		account, ok := VerifyPasswordLogin(c, reqUsername, reqPassword)
+		if !ok || !VerifySecondFactor(c, &account, reqCode) {
+			return nil
 		}
-		c.Locals("username", username)
-	} else {
-		username = reqUsername
-		password = reqPassword
-		controlPassword = true
+
+		cache.SessionCache.Set(account.Username, account, 30*time.Minute)
+		c.Locals("account", account)
+		return c.Next()
+	}
+
+	tokenClaims, err := utils.VerifyToken(token, config.Config.SecretJwtKey)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"err": "invalid token"})
 	}
+	c.Locals("username", tokenClaims.Username)
 
-	if cacheAccount, ok := cache.SessionCache.Get(username); ok {
-		hash := sha256.Sum256([]byte(password))
-		hashString := hex.EncodeToString(hash[:])
-		if controlPassword && hashString != cacheAccount.Password {
-			return c.Status(401).JSON(fiber.Map{"err": "wrong password"})
+	if cacheAccount, ok := cache.SessionCache.Get(tokenClaims.Username); ok {
+		if !tokenClaims.Matches(cacheAccount) {
+			return c.Status(401).JSON(fiber.Map{"err": "invalid token"})
 		}
 
 		c.Locals("account", cacheAccount)
 		return c.Next()
 	}
 
-	foundAccount, err := users.GetUserByUsername(username)
+	foundAccount, err := users.GetUserByUsername(tokenClaims.Username)
 
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"err": "account not found"})
 	}
 
-	hash := sha256.Sum256([]byte(password))
-	hashString := hex.EncodeToString(hash[:])
-
-	if controlPassword && hashString != foundAccount.Password {
-		return c.Status(401).JSON(fiber.Map{"err": "wrong password"})
+	if !tokenClaims.Matches(foundAccount) {
+		return c.Status(401).JSON(fiber.Map{"err": "invalid token"})
 	}
 
-	cache.SessionCache.Set(username, foundAccount, 30*time.Minute)
+	cache.SessionCache.Set(tokenClaims.Username, foundAccount, 30*time.Minute)
 	c.Locals("account", foundAccount)
 	return c.Next()
 }
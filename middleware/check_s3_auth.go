@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/s3keys"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CheckS3Auth verifies the SigV4 signature of S3 requests with the access key of the user.
+// Keys are created after logging in, so they are allowed for accounts with two factor authentication too.
+func CheckS3Auth(c *fiber.Ctx) error {
+	signature, s3Err := s3.ParseSignature(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	key, err := s3keys.GetS3KeyByAccessKeyID(signature.AccessKeyID)
+	if err != nil {
+		return s3.SendError(c, s3.ErrInvalidAccessKeyID)
+	}
+
+	if s3Err := signature.Verify(c, key.SecretAccessKey, time.Now()); s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	account, ok := cache.SessionCache.Get(key.Username)
+	if !ok {
+		account, err = users.GetUserByUsername(key.Username)
+		if err != nil {
+			return s3.SendError(c, s3.ErrInvalidAccessKeyID)
+		}
+		cache.SessionCache.Set(key.Username, account, 30*time.Minute)
+	}
+
+	c.Locals("account", account)
+	c.Locals("s3Signature", signature)
+	c.Locals("s3Secret", key.SecretAccessKey)
+	return c.Next()
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LimitBody reads streamed request bodies into memory up to limit bytes. The server streams bodies for
+// WebDAV, every other route expects the whole body like before and gets 413 for bigger ones.
+func LimitBody(c *fiber.Ctx, limit int) error {
+	stream := c.Context().RequestBodyStream()
+	if stream == nil {
+		return c.Next()
+	}
+
+	if c.Request().Header.ContentLength() > limit {
+		// The rest of the body is still on the connection
+		c.Context().SetConnectionClose()
+		return c.Status(413).JSON(fiber.Map{"err": "Request body too large"})
+	}
+
+	body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+	if err != nil {
+		c.Context().SetConnectionClose()
+		return c.Status(400).JSON(fiber.Map{"err": "Error reading request body"})
+	}
+
+	if len(body) > limit {
+		c.Context().SetConnectionClose()
+		return c.Status(413).JSON(fiber.Map{"err": "Request body too large"})
+	}
+
+	c.Request().SetBodyRaw(body)
+	return c.Next()
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/MertJSX/folder-host-go/database/twofactor"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/bruteforce"
+	"github.com/gofiber/fiber/v2"
+)
+
+// VerifyPasswordLogin checks the password of the user with brute-force protection.
+// It writes the error response itself, the caller must stop if it returns false.
+func VerifyPasswordLogin(c *fiber.Ctx, username string, plainPassword string) (types.Account, bool) {
+	if bruteforce.Reject(c, username) {
+		return types.Account{}, false
+	}
+
+	account, err := users.GetUserByUsername(username)
+
+	if err != nil {
+		bruteforce.RegisterFailure(c.IP(), username, false, "account not found")
+		c.Status(404).JSON(fiber.Map{"err": "account not found"})
+		return types.Account{}, false
+	}
+
+	if !users.VerifyPassword(&account, plainPassword) {
+		bruteforce.RegisterFailure(c.IP(), username, true, "wrong password")
+		c.Status(401).JSON(fiber.Map{"err": "wrong password"})
+		return types.Account{}, false
+	}
+
+	return account, true
+}
+
+// VerifySecondFactor must follow VerifyPasswordLogin before a token is created or the request continues.
+func VerifySecondFactor(c *fiber.Ctx, account *types.Account, code string) bool {
+	if err := twofactor.CheckSecondFactor(account, code); err != nil {
+		if errors.Is(err, twofactor.ErrWrongCode) {
+			bruteforce.RegisterFailure(c.IP(), account.Username, true, "wrong two factor code")
+		}
+		c.Status(401).JSON(fiber.Map{"err": err.Error(), "twoFactor": true})
+		return false
+	}
+
+	bruteforce.RegisterSuccess(account.Username)
+	return true
+}
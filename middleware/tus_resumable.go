@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/MertJSX/folder-host-go/utils/tus"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CheckTusResumable rejects tus requests of other protocol versions. Every tus response has to carry
+// the Tus-Resumable header.
+func CheckTusResumable(c *fiber.Ctx) error {
+	c.Set("Tus-Resumable", tus.Version)
+
+	if c.Method() != fiber.MethodOptions && c.Get("Tus-Resumable") != tus.Version {
+		c.Set("Tus-Version", tus.Version)
+		return c.Status(412).JSON(fiber.Map{"err": "Unsupported tus version"})
+	}
+
+	return c.Next()
+}
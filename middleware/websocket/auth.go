@@ -29,17 +29,21 @@ func WsConnect(c *fiber.Ctx) error {
 		})
 	}
 
-	username, err := utils.VerifyToken(token, config.Config.SecretJwtKey)
+	tokenClaims, err := utils.VerifyToken(token, config.Config.SecretJwtKey)
 	if err != nil {
 		return c.Status(401).JSON(fiber.Map{"error": "invalid token"})
 	}
 
-	foundAccount, err := users.GetUserByUsername(username)
+	foundAccount, err := users.GetUserByUsername(tokenClaims.Username)
 
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"err": "account not found"})
 	}
 
+	if !tokenClaims.Matches(foundAccount) {
+		return c.Status(401).JSON(fiber.Map{"error": "invalid token"})
+	}
+
 	c.Locals("username", foundAccount.Username)
 	c.Locals("account", foundAccount)
 	c.Locals("token", token)
@@ -5,10 +5,13 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"time"
 
 	"github.com/MertJSX/folder-host-go/types"
 	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
 	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/MertJSX/folder-host-go/utils/collab"
 	"github.com/MertJSX/folder-host-go/utils/config"
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
@@ -42,6 +45,16 @@ func HandleWebsocket(c *websocket.Conn) {
 		return
 	}
 
+	permission := types.PermissionReadFiles
+	if fileStat.IsDir() {
+		permission = types.PermissionReadDirectories
+	}
+
+	if !acl.Allowed(account, permission, utils.ReplacePathPrefix(path, config.GetScopedFolder(account.Scope))) {
+		c.Close()
+		return
+	}
+
 	if utils.IsExistingWSConnectionPath(path) || fileStat.IsDir() {
 		utils.AddClient(c, path, fileStat.IsDir())
 	} else {
@@ -58,6 +71,7 @@ func HandleWebsocket(c *websocket.Conn) {
 	defer updateClientsCount(path)
 	defer utils.RemoveClient(c)
 	defer c.Close()
+	defer unsubscribeJobs(c)
 
 	var username string = account.Username
 	defer utils.TriggerPendingLog(username, path)
@@ -78,11 +92,36 @@ func HandleWebsocket(c *websocket.Conn) {
 		}
 	}
 
+	if !fileStat.IsDir() {
+		if _, err := collab.Open(path, saveDocument, checkDocumentSize); err != nil {
+			return
+		}
+		defer collab.Close(path)
+
+		// The content from /api/read-file can miss changes that aren't saved yet
+		if err := sendResync(path, c, websocket.TextMessage); err != nil {
+			return
+		}
+
+		joinPresence(c, path, account, acl.Allowed(account, types.PermissionChange, utils.ReplacePathPrefix(path, config.GetScopedFolder(account.Scope))))
+		defer leavePresence(c)
+	}
+
+	c.SetReadDeadline(time.Now().Add(PongWait))
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(PongWait))
+	})
+
+	stopPing := make(chan struct{})
+	defer close(stopPing)
+	go keepAlive(c, stopPing)
+
 	for {
 		mt, msg, err := c.ReadMessage()
 		if err != nil {
 			return
 		}
+		c.SetReadDeadline(time.Now().Add(PongWait))
 
 		if err := processWebSocketMessage(msg, path, c, mt); err != nil {
 			log.Println("Message processing error:", err)
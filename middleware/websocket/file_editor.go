@@ -2,16 +2,19 @@ package websocket
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"strings"
-	"unicode/utf8"
 
 	"github.com/MertJSX/folder-host-go/database/logs"
 	"github.com/MertJSX/folder-host-go/types"
 	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
 	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/MertJSX/folder-host-go/utils/collab"
 	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+	"github.com/MertJSX/folder-host-go/utils/versions"
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 )
@@ -26,7 +29,7 @@ func processWebSocketMessage(msg []byte, filePath string, c *websocket.Conn, mt
 
 	switch message.Type {
 	case "editor-change":
-		if !account.Permissions.Change {
+		if !acl.Allowed(account, types.PermissionChange, utils.ReplacePathPrefix(filePath, config.Config.GetScopedFolder(account.Scope))) {
 			permissionError, _ := json.Marshal(fiber.Map{
 				"type":  "error",
 				"error": "You don't have permission to change!",
@@ -38,10 +41,15 @@ func processWebSocketMessage(msg []byte, filePath string, c *websocket.Conn, mt
 
 		utils.ScheduleDebouncedLog(account.Username, filePath)
 
-		utils.SendToAllExclude(filePath, mt, msg, c)
-		return applyEditorChange(filePath, message.Change, &account)
+		return applyEditorChange(filePath, message, c, mt, account)
+	case "resync":
+		return sendResync(filePath, c, mt)
+	case "presence-update":
+		updatePresence(c, message)
+	case "subscribe-jobs":
+		subscribeJobs(c, account)
 	case "change-path":
-		if !account.Permissions.ReadDirectories {
+		if !acl.Allowed(account, types.PermissionReadDirectories, message.Path) {
 			permissionError, _ := json.Marshal(fiber.Map{
 				"type":  "error",
 				"error": "You don't have permission to read-directories!",
@@ -66,7 +74,7 @@ func processWebSocketMessage(msg []byte, filePath string, c *websocket.Conn, mt
 
 		return utils.ChangePath(c, path, fileStat.IsDir())
 	case "unzip":
-		if !account.Permissions.Extract {
+		if !acl.Allowed(account, types.PermissionExtract, message.Path) {
 			permissionError, _ := json.Marshal(fiber.Map{
 				"type":  "error",
 				"error": "You don't have permission to unzip!",
@@ -84,7 +92,7 @@ func processWebSocketMessage(msg []byte, filePath string, c *websocket.Conn, mt
 
 		HandleUnzip(c, mt, message)
 	case "zip":
-		if !account.Permissions.Archive {
+		if !acl.Allowed(account, types.PermissionArchive, message.Path) {
 			permissionError, _ := json.Marshal(fiber.Map{
 				"type":  "error",
 				"error": "You don't have permission to zip!",
@@ -106,157 +114,131 @@ func processWebSocketMessage(msg []byte, filePath string, c *websocket.Conn, mt
 	return nil
 }
 
-func applyEditorChange(filePath string, change types.ChangeData, account *types.Account) error {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-
-	startLine := change.Range.StartLineNumber - 1
-	startCol := change.Range.StartColumn - 1
-	endLine := change.Range.EndLineNumber - 1
-	endCol := change.Range.EndColumn - 1
-
-	startCol = adjustUTF8Position(lines, startLine, startCol)
-	endCol = adjustUTF8Position(lines, endLine, endCol)
-
-	switch change.Type {
-	case "insert":
-		return applyInsert(filePath, lines, startLine, startCol, change.Text, account)
-	case "delete":
-		return applyDelete(filePath, lines, startLine, startCol, endLine, endCol, account)
-	case "replace":
-		return applyReplace(filePath, lines, startLine, startCol, endLine, endCol, change.Text, account)
-	default:
-		return nil
-	}
-}
-
-func adjustUTF8Position(lines []string, lineNum, col int) int {
-	if lineNum < 0 || lineNum >= len(lines) {
-		return col
+// applyEditorChange submits the change to the document of the file. The sender gets an "editor-ack" with the new revision,
+// everyone else the transformed operation. Changes without a revision come from older clients and are based on the current one.
+func applyEditorChange(filePath string, message types.EditorChange, c *websocket.Conn, mt int, account types.Account) error {
+	document, ok := collab.Get(filePath)
+	if !ok {
+		return fmt.Errorf("document of %s isn't open", filePath)
 	}
 
-	line := lines[lineNum]
+	var operation collab.Operation
+	var revision int
 
-	if col > utf8.RuneCountInString(line) {
-		return utf8.RuneCountInString(line)
+	if message.Operation != nil {
+		if err := json.Unmarshal(message.Operation, &operation); err != nil {
+			return sendResync(filePath, c, mt)
+		}
+		if message.Revision != nil {
+			revision = *message.Revision
+		} else {
+			_, revision = document.Snapshot()
+		}
+	} else {
+		operation, revision = document.FromRange(message.Change.Range, message.Change.Text)
 	}
 
-	return col
-}
-
-func applyInsert(filePath string, lines []string, lineNum, col int, text string, account *types.Account) error {
-	if lineNum < 0 || lineNum >= len(lines) {
-		return fmt.Errorf("line number out of range: %d", lineNum)
-	}
+	err := document.Submit(revision, operation, account, func(applied collab.Operation, revision int) {
+		ack, _ := json.Marshal(fiber.Map{
+			"type":     "editor-ack",
+			"revision": revision,
+		})
+		change, _ := json.Marshal(fiber.Map{
+			"type":      "editor-change",
+			"revision":  revision,
+			"operation": applied,
+		})
 
-	line := lines[lineNum]
-	runes := []rune(line)
+		utils.SendTo(c, mt, ack)
+		utils.SendToAllExclude(filePath, mt, change, c)
+	})
 
-	if col < 0 {
-		col = 0
-	}
-	if col > len(runes) {
-		col = len(runes)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, utils.ErrQuotaExceeded):
+		sendEditorError(c, mt, "Storage quota exceeded! The change wasn't saved.")
+	case errors.Is(err, errFileTooLarge):
+		sendEditorError(c, mt, "File can't be larger than 200 KB! The change wasn't saved.")
+	case errors.Is(err, collab.ErrStaleRevision), errors.Is(err, collab.ErrInvalidOperation):
+	default:
+		return err
 	}
 
-	newRunes := make([]rune, 0, len(runes)+len([]rune(text)))
-	newRunes = append(newRunes, runes[:col]...)
-	newRunes = append(newRunes, []rune(text)...)
-	newRunes = append(newRunes, runes[col:]...)
-
-	lines[lineNum] = string(newRunes)
-	return writeFile(filePath, lines, account)
+	// The client already shows the rejected change, so it gets the content of the server again
+	return sendResync(filePath, c, mt)
 }
 
-func applyDelete(filePath string, lines []string, startLine, startCol, endLine, endCol int, account *types.Account) error {
-	if startLine < 0 || startLine >= len(lines) || endLine < 0 || endLine >= len(lines) {
-		return fmt.Errorf("line numbers out of range: %d-%d", startLine, endLine)
+func sendResync(filePath string, c *websocket.Conn, mt int) error {
+	document, ok := collab.Get(filePath)
+	if !ok {
+		return fmt.Errorf("document of %s isn't open", filePath)
 	}
 
-	if startLine == endLine {
-		line := lines[startLine]
-		runes := []rune(line)
-
-		startCol = utils.Clamp(startCol, 0, len(runes))
-		endCol = utils.Clamp(endCol, 0, len(runes))
-
-		if startCol >= endCol {
-			return nil
+	var err error
+	document.Resync(func(content string, revision int) {
+		var msg []byte
+		msg, err = json.Marshal(fiber.Map{
+			"type":     "editor-resync",
+			"content":  content,
+			"revision": revision,
+		})
+		if err == nil {
+			err = utils.SendTo(c, mt, msg)
 		}
+	})
 
-		newRunes := make([]rune, 0, len(runes)-(endCol-startCol))
-		newRunes = append(newRunes, runes[:startCol]...)
-		newRunes = append(newRunes, runes[endCol:]...)
-		lines[startLine] = string(newRunes)
-
-	} else {
-		firstLineRunes := []rune(lines[startLine])
-		lastLineRunes := []rune(lines[endLine])
+	return err
+}
 
-		startCol = utils.Clamp(startCol, 0, len(firstLineRunes))
-		endCol = utils.Clamp(endCol, 0, len(lastLineRunes))
+func sendEditorError(c *websocket.Conn, mt int, message string) {
+	msg, _ := json.Marshal(fiber.Map{
+		"type":  "error",
+		"error": message,
+	})
 
-		newFirstLine := string(firstLineRunes[:startCol])
+	utils.SendTo(c, mt, msg)
+}
 
-		newLastLine := string(lastLineRunes[endCol:])
+var errFileTooLarge = errors.New("file size exceeds 200 KB")
 
-		lines[startLine] = newFirstLine + newLastLine
+// checkDocumentSize runs before a change is applied to the document.
+// Only growing files can exceed the quota. The storage limit is guaranteed by the 200 KB reserved for each editor.
+func checkDocumentSize(filepath string, size int64, account types.Account) error {
+	if size > 200*1024 {
+		return errFileTooLarge
+	}
 
-		if startLine < endLine {
-			lines = append(lines[:startLine+1], lines[endLine+1:]...)
+	if account.Quota != "" {
+		if oldStat, err := os.Stat(filepath); err == nil && size > oldStat.Size() {
+			used, limit, err := utils.GetQuotaUsage(account)
+			if err != nil {
+				return err
+			}
+			if used+size-oldStat.Size() > limit {
+				return utils.ErrQuotaExceeded
+			}
 		}
 	}
 
-	return writeFile(filePath, lines, account)
+	return nil
 }
 
-func applyReplace(filePath string, lines []string, startLine, startCol, endLine, endCol int, text string, account *types.Account) error {
-	if startLine == endLine {
-		line := lines[startLine]
-		runes := []rune(line)
-
-		startCol = utils.Clamp(startCol, 0, len(runes))
-		endCol = utils.Clamp(endCol, 0, len(runes))
-
-		if startCol > endCol {
-			return fmt.Errorf("invalid range: startCol > endCol")
-		}
-
-		textRunes := []rune(text)
-		newRunes := make([]rune, 0, len(runes)-(endCol-startCol)+len(textRunes))
-		newRunes = append(newRunes, runes[:startCol]...)
-		newRunes = append(newRunes, textRunes...)
-		newRunes = append(newRunes, runes[endCol:]...)
-		lines[startLine] = string(newRunes)
-
-	} else {
-		firstLineRunes := []rune(lines[startLine])
-		startCol = utils.Clamp(startCol, 0, len(firstLineRunes))
-
-		firstPart := string(firstLineRunes[:startCol])
-		newFirstLine := firstPart + text
-
-		lastLineRunes := []rune(lines[endLine])
-		endCol = utils.Clamp(endCol, 0, len(lastLineRunes))
-		lastPart := string(lastLineRunes[endCol:])
-
-		lines[startLine] = newFirstLine + lastPart
-
-		if startLine < endLine {
-			lines = append(lines[:startLine+1], lines[endLine+1:]...)
-		}
+// saveDocument is called by the document after the debounce. Errors go to every client of the file,
+// because the change that failed to save might come from somebody else.
+func saveDocument(filepath string, content string, account types.Account) error {
+	err := writeFile(filepath, content, &account)
+	if err != nil {
+		msg, _ := json.Marshal(fiber.Map{
+			"type":  "error",
+			"error": "Error while saving the file! Latest changes weren't saved.",
+		})
+		utils.SendToAll(filepath, websocket.TextMessage, msg)
 	}
-
-	return writeFile(filePath, lines, account)
+	return err
 }
 
-func writeFile(filepath string, lines []string, account *types.Account) error {
-	content := strings.Join(lines, "\n")
-
+func writeFile(filepath string, content string, account *types.Account) error {
 	if len(content) > 200*1024 {
 		return fmt.Errorf("file size exceeds 200 KB: %d Bytes", len(content))
 	}
@@ -266,11 +248,30 @@ func writeFile(filepath string, lines []string, account *types.Account) error {
 		return nil
 	}
 
+	// Don't bring back a file that was deleted or moved while it was open
+	oldStat, err := os.Stat(filepath)
+	if err != nil {
+		return err
+	}
+
+	if account.Quota != "" && int64(len(content)) > oldStat.Size() {
+		used, limit, err := utils.GetQuotaUsage(*account)
+		if err != nil {
+			return err
+		}
+		if used+int64(len(content))-oldStat.Size() > limit {
+			return utils.ErrQuotaExceeded
+		}
+	}
+
 	watcherCache.IsWriting = true
 
 	cache.EditorWatcherCache.SetWithoutTTL(filepath, watcherCache)
 
-	err := os.WriteFile(filepath, []byte(content), 0644)
+	versions.BeforeEdit(account.Username, filepath)
+
+	err = os.WriteFile(filepath, []byte(content), 0644)
+	sizeindex.Refresh(filepath)
 
 	if err != nil {
 		watcherCache.IsWriting = false
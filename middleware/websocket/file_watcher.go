@@ -9,6 +9,7 @@ import (
 	"github.com/MertJSX/folder-host-go/types"
 	"github.com/MertJSX/folder-host-go/utils"
 	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/MertJSX/folder-host-go/utils/collab"
 	"github.com/fasthttp/websocket"
 	"github.com/fsnotify/fsnotify"
 	"github.com/gofiber/fiber/v2"
@@ -101,16 +102,33 @@ func sendFullContent(path string) {
 		return
 	}
 
-	msg, _ := json.Marshal(fiber.Map{
-		"type":      "full-content-update",
-		"content":   string(content),
-		"timestamp": time.Now().Unix(),
-	})
+	publish := func(content string, revision int) {
+		msg, _ := json.Marshal(fiber.Map{
+			"type":      "full-content-update",
+			"content":   content,
+			"revision":  revision,
+			"timestamp": time.Now().Unix(),
+		})
 
-	utils.SendToAll(path, websocket.TextMessage, msg)
+		utils.SendToAll(path, websocket.TextMessage, msg)
+	}
+
+	if document, ok := collab.Get(path); ok {
+		document.Reset(string(content), publish)
+		return
+	}
+
+	publish(string(content), 0)
 }
 
 func sendReloadNotification(path string) {
+	// Pending changes of the editor must not overwrite the new content
+	if document, ok := collab.Get(path); ok {
+		if content, err := os.ReadFile(path); err == nil {
+			document.Reset(string(content), nil)
+		}
+	}
+
 	msg, _ := json.Marshal(fiber.Map{
 		"type":      "file-changed-externally",
 		"timestamp": time.Now().Unix(),
@@ -0,0 +1,132 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/jobs"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+type jobWatcher struct {
+	conn *websocket.Conn
+	mt   int
+	// progressType is "zip-progress" or "unzip-progress", the messages older clients expect
+	progressType string
+}
+
+var (
+	jobSubscribersMu sync.Mutex
+	// Connections that sent "subscribe-jobs", they get every change of the jobs of their account
+	jobSubscribers = make(map[*websocket.Conn]string)
+	// Connections that started a job with the "zip" or "unzip" message
+	jobWatchers = make(map[int]jobWatcher)
+)
+
+// SendJobUpdate sends the job to the subscribers of its owner and to the connection that started it.
+// It's registered with jobs.OnUpdate.
+func SendJobUpdate(job types.Job) {
+	jobSubscribersMu.Lock()
+	defer jobSubscribersMu.Unlock()
+
+	msg, _ := json.Marshal(fiber.Map{
+		"type": "job-update",
+		"job":  job,
+	})
+
+	for conn, username := range jobSubscribers {
+		if username == job.Username {
+			utils.SendTo(conn, websocket.TextMessage, msg)
+		}
+	}
+
+	if watcher, ok := jobWatchers[job.ID]; ok {
+		sendJobProgress(watcher, job)
+	}
+}
+
+// sendJobProgress translates the job to the progress message of the watcher. Call it with jobSubscribersMu locked.
+func sendJobProgress(watcher jobWatcher, job types.Job) {
+	if job.Status == types.JobQueued {
+		return
+	}
+
+	abortMsg := ""
+	if job.Status == types.JobFailed {
+		abortMsg = job.Error
+	} else if job.Status == types.JobCancelled {
+		abortMsg = "Cancelled!"
+	}
+
+	progress, _ := json.Marshal(fiber.Map{
+		"type":        watcher.progressType,
+		"totalSize":   utils.ConvertBytesToString(job.Progress),
+		"isCompleted": job.Status == types.JobCompleted,
+		"abortMsg":    abortMsg,
+	})
+	utils.SendTo(watcher.conn, watcher.mt, progress)
+
+	if job.IsFinished() {
+		delete(jobWatchers, job.ID)
+	}
+}
+
+func subscribeJobs(c *websocket.Conn, account types.Account) {
+	jobSubscribersMu.Lock()
+	defer jobSubscribersMu.Unlock()
+
+	jobSubscribers[c] = account.Username
+}
+
+// unsubscribeJobs forgets the connection. Its jobs keep running.
+func unsubscribeJobs(c *websocket.Conn) {
+	jobSubscribersMu.Lock()
+	defer jobSubscribersMu.Unlock()
+
+	delete(jobSubscribers, c)
+	for id, watcher := range jobWatchers {
+		if watcher.conn == c {
+			delete(jobWatchers, id)
+		}
+	}
+}
+
+// startJob queues a job for the "zip" and "unzip" messages and reports its progress to the connection.
+func startJob(c *websocket.Conn, mt int, job types.Job, progressType string) {
+	account := c.Locals("account").(types.Account)
+
+	sendAbort := func(message string) {
+		progress, _ := json.Marshal(fiber.Map{
+			"type":        progressType,
+			"totalSize":   utils.ConvertBytesToString(0),
+			"isCompleted": false,
+			"abortMsg":    message,
+		})
+		utils.SendTo(c, mt, progress)
+	}
+
+	if err := jobs.Validate(account, &job); err != nil {
+		sendAbort(err.Error())
+		return
+	}
+
+	job, err := jobs.Enqueue(job)
+	if err != nil {
+		sendAbort("Unknown server error!")
+		return
+	}
+
+	jobSubscribersMu.Lock()
+	defer jobSubscribersMu.Unlock()
+
+	watcher := jobWatcher{conn: c, mt: mt, progressType: progressType}
+	jobWatchers[job.ID] = watcher
+
+	// A worker could take the job before the watcher was registered, the updates it sent are covered by the latest state
+	if current, err := jobs.Get(job.ID); err == nil {
+		sendJobProgress(watcher, current)
+	}
+}
@@ -1,57 +1,28 @@
 package websocket
 
 import (
-	"encoding/json"
-	"fmt"
-
 	"github.com/MertJSX/folder-host-go/types"
-	"github.com/MertJSX/folder-host-go/utils"
-	"github.com/MertJSX/folder-host-go/utils/config"
 	"github.com/gofiber/contrib/websocket"
-	"github.com/gofiber/fiber/v2"
 )
 
+// HandleUnzip extracts the archive next to it as a background job, the connection gets "unzip-progress" messages.
+// The format is detected from the name if the message has none.
 func HandleUnzip(c *websocket.Conn, mt int, message types.EditorChange) {
-	var account types.Account = c.Locals("account").(types.Account)
-
-	src := config.Config.GetScopedFolder(account.Scope) + message.Path
-	dest := fmt.Sprintf("%s%s/%s", config.Config.GetScopedFolder(account.Scope), utils.GetParentPath(message.Path), utils.GetPureFileName(message.Path))
-
-	for index := 1; utils.IsExistingPath(dest); index++ {
-		dest = fmt.Sprintf("%s (%d)", dest, index)
-	}
-
-	utils.Unzip(src, dest, func(totalSize int64, isCompleted bool, abortMsg string) {
-		unzipProgress, _ := json.Marshal(fiber.Map{
-			"type":        "unzip-progress",
-			"totalSize":   utils.ConvertBytesToString(totalSize),
-			"isCompleted": isCompleted,
-			"abortMsg":    abortMsg,
-		})
-
-		c.WriteMessage(mt, unzipProgress)
-	})
+	startJob(c, mt, types.Job{
+		Type:     types.JobExtract,
+		Username: c.Locals("account").(types.Account).Username,
+		Source:   message.Path,
+		Format:   message.Format,
+	}, "unzip-progress")
 }
 
+// HandleZip archives the item next to it as a background job, the connection gets "zip-progress" messages.
+// The message can choose the format of the archive, zip by default.
 func HandleZip(c *websocket.Conn, mt int, message types.EditorChange) {
-	var account types.Account = c.Locals("account").(types.Account)
-
-	src := config.Config.GetScopedFolder(account.Scope) + message.Path
-	baseDest := fmt.Sprintf("%s%s/%s", config.Config.GetScopedFolder(account.Scope), utils.GetParentPath(message.Path), utils.GetPureFileName(message.Path))
-	dest := baseDest + ".zip"
-
-	for index := 1; utils.IsExistingPath(dest); index++ {
-		dest = fmt.Sprintf("%s (%d).zip", baseDest, index)
-	}
-
-	utils.Zip(src, dest, func(totalSize int64, isCompleted bool, abortMsg string) {
-		zipProgress, _ := json.Marshal(fiber.Map{
-			"type":        "zip-progress",
-			"totalSize":   utils.ConvertBytesToString(totalSize),
-			"isCompleted": isCompleted,
-			"abortMsg":    abortMsg,
-		})
-
-		c.WriteMessage(mt, zipProgress)
-	})
+	startJob(c, mt, types.Job{
+		Type:     types.JobArchive,
+		Username: c.Locals("account").(types.Account).Username,
+		Source:   message.Path,
+		Format:   message.Format,
+	}, "zip-progress")
 }
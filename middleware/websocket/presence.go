@@ -0,0 +1,139 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Clients that don't answer pings for PongWait are treated as disconnected and leave the presence list.
+// They are variables so tests can shorten them.
+var (
+	PingInterval = 30 * time.Second
+	PongWait     = 75 * time.Second
+)
+
+const maxPresenceSelections = 32
+
+type presenceEntry struct {
+	path     string
+	presence types.EditorPresence
+}
+
+var (
+	// Every presence message is sent while presenceMu is locked, so join, update and leave arrive in order.
+	presenceMu sync.Mutex
+	presences  = make(map[*websocket.Conn]*presenceEntry)
+)
+
+// joinPresence sends the roster of the file to the new client and announces it to the others.
+func joinPresence(c *websocket.Conn, path string, account types.Account, canEdit bool) {
+	presenceMu.Lock()
+	defer presenceMu.Unlock()
+
+	entry := &presenceEntry{
+		path: path,
+		presence: types.EditorPresence{
+			ID:         utils.GenerateUniqueString(),
+			Username:   account.Username,
+			CanEdit:    canEdit,
+			Selections: []types.ChangeRange{},
+		},
+	}
+	presences[c] = entry
+
+	roster := []types.EditorPresence{}
+	for _, other := range presences {
+		if other.path == path {
+			roster = append(roster, other.presence)
+		}
+	}
+	sort.Slice(roster, func(i, j int) bool {
+		return roster[i].Username < roster[j].Username
+	})
+
+	rosterMessage, _ := json.Marshal(fiber.Map{
+		"type":  "presence-roster",
+		"self":  entry.presence.ID,
+		"users": roster,
+	})
+	joinMessage, _ := json.Marshal(fiber.Map{
+		"type": "presence-join",
+		"user": entry.presence,
+	})
+
+	utils.SendTo(c, websocket.TextMessage, rosterMessage)
+	utils.SendToAllExclude(path, websocket.TextMessage, joinMessage, c)
+}
+
+// updatePresence stores the cursor and selections of the client and sends them to the others.
+func updatePresence(c *websocket.Conn, message types.EditorChange) {
+	presenceMu.Lock()
+	defer presenceMu.Unlock()
+
+	entry, ok := presences[c]
+	if !ok {
+		return
+	}
+
+	selections := message.Selections
+	if len(selections) > maxPresenceSelections {
+		selections = selections[:maxPresenceSelections]
+	}
+	if selections == nil {
+		selections = []types.ChangeRange{}
+	}
+
+	entry.presence.Cursor = message.Cursor
+	entry.presence.Selections = selections
+
+	msg, _ := json.Marshal(fiber.Map{
+		"type": "presence-update",
+		"user": entry.presence,
+	})
+
+	utils.SendToAllExclude(entry.path, websocket.TextMessage, msg, c)
+}
+
+// leavePresence removes the client and tells the others.
+func leavePresence(c *websocket.Conn) {
+	presenceMu.Lock()
+	defer presenceMu.Unlock()
+
+	entry, ok := presences[c]
+	if !ok {
+		return
+	}
+	delete(presences, c)
+
+	msg, _ := json.Marshal(fiber.Map{
+		"type": "presence-leave",
+		"id":   entry.presence.ID,
+	})
+
+	utils.SendToAllExclude(entry.path, websocket.TextMessage, msg, c)
+}
+
+// keepAlive pings the client until stop is closed. The pong handler moves the read deadline,
+// so ReadMessage fails for clients that disappeared without closing the connection.
+func keepAlive(c *websocket.Conn, stop chan struct{}) {
+	ticker := time.NewTicker(PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/jobs"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CancelJob removes a queued job from the queue or stops a running one. A running job is cancelled
+// when it cleaned up what it created, the job-update event or GET /api/jobs/:id tells when.
+func CancelJob(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	job, status, message := getOwnJob(account, c.Params("id"))
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"err": message})
+	}
+
+	job, err := jobs.Cancel(job.ID)
+	if errors.Is(err, jobs.ErrNotCancellable) {
+		return c.Status(409).JSON(fiber.Map{"err": "The job is already finished!"})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Cancel job",
+		Description: fmt.Sprintf("%s cancelled the %s job for %s.", account.Username, job.Type, job.Source),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"response": "Job cancelled!",
+		"job":      job,
+	})
+}
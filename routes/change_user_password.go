@@ -60,6 +60,12 @@ func ChangeUserPassword(c *fiber.Ctx) error {
 		cache.SessionCache.Delete(username)
 	}
 
+	if err := revokeUserSessions(*requestBody.User.ID, username); err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Password was changed, but old sessions couldn't be revoked."},
+		)
+	}
+
 	logs.CreateLog(types.AuditLog{
 		Username:    c.Locals("account").(types.Account).Username,
 		Action:      "Change Pass",
@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/bruteforce"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClearLockouts unlocks the given key like "user:mert" or "ip:127.0.0.1". Without a key, everything is unlocked.
+func ClearLockouts(c *fiber.Ctx) error {
+	if !c.Locals("account").(types.Account).Permissions.EditUsers {
+		return c.Status(403).JSON(
+			fiber.Map{"err": "No permission!"},
+		)
+	}
+
+	key := c.Query("key")
+	description := fmt.Sprintf("%s cleared all login lockouts", c.Locals("account").(types.Account).Username)
+
+	if key == "" {
+		bruteforce.ClearAll()
+	} else {
+		if !bruteforce.Clear(key) {
+			return c.Status(404).JSON(
+				fiber.Map{"err": "Lockout doesn't exist."},
+			)
+		}
+		description = fmt.Sprintf("%s cleared the login lockout of %s", c.Locals("account").(types.Account).Username, key)
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    c.Locals("account").(types.Account).Username,
+		Action:      "Clear lockout",
+		Description: description,
+	})
+
+	return c.Status(200).JSON(
+		fiber.Map{"response": "Lockouts cleared!"},
+	)
+}
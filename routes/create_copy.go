@@ -1,100 +1,55 @@
 package routes
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
+	"time"
 
-	"github.com/MertJSX/folder-host-go/database/logs"
 	"github.com/MertJSX/folder-host-go/types"
-	"github.com/MertJSX/folder-host-go/utils"
-	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/jobs"
 	"github.com/gofiber/fiber/v2"
 )
 
+// copyWait is how long CreateCopy waits for the copy job. Bigger copies continue in the background.
+const copyWait = 5 * time.Second
+
 func CreateCopy(c *fiber.Ctx) error {
-	var (
-		path       string = c.Query("path")
-		parentPath string
-		basename   string
-		copyPath   string
-		extname    string            = ""
-		account    types.Account     = c.Locals("account").(types.Account)
-		config     *types.ConfigFile = &config.Config
-		scope      string            = c.Locals("account").(types.Account).Scope
-	)
+	account := c.Locals("account").(types.Account)
 
-	if !account.Permissions.Copy {
-		return c.Status(403).JSON(fiber.Map{"err": "No permission"})
+	job := types.Job{
+		Type:     types.JobCopy,
+		Username: account.Username,
+		Source:   c.Query("path"),
 	}
 
-	pathStat, err := os.Stat(fmt.Sprintf("%s%s", config.GetScopedFolder(scope), path))
-
-	if os.IsNotExist(err) {
-		return c.Status(400).JSON(fiber.Map{"err": "The item doesn't exist!"})
+	if err := jobs.Validate(account, &job); err != nil {
+		return c.Status(400).JSON(fiber.Map{"err": err.Error()})
 	}
 
-	parentPath = utils.GetParentPath(path)
-	basename = fmt.Sprintf("%s - Copy", utils.GetPureFileName(path))
-	var index int = 0
-	if !pathStat.IsDir() {
-		extname = filepath.Ext(path)
-		copyPath = fmt.Sprintf("%s/%s%s", parentPath, basename, extname)
-		if config.StorageLimit != "" {
-			fileSize := pathStat.Size()
-			remainingFreeSpace, err := utils.GetRemainingFolderSpace()
-			if err != nil {
-				return c.Status(520).JSON(fiber.Map{"err": "Internal server error!"})
-			}
-
-			if fileSize > remainingFreeSpace {
-				return c.Status(507).JSON(fiber.Map{"err": "Not enough space!"})
-			}
-		}
-
-		for utils.IsExistingPath(config.GetScopedFolder(scope) + copyPath) {
-			index++
-			copyPath = fmt.Sprintf("%s/%s (%d)%s", parentPath, basename, index, extname)
-		}
-
-		err := utils.CopyFile(config.GetScopedFolder(scope)+path, config.GetScopedFolder(scope)+copyPath)
-
-		if err != nil {
-			return c.Status(520).JSON(fiber.Map{"err": "Internal server error!"})
-		}
-	} else {
-		if config.StorageLimit != "" {
-			folderSize, _, err := utils.GetDirectorySize(config.GetScopedFolder(scope) + path)
-			if err != nil {
-				return c.Status(520).JSON(fiber.Map{"err": "Internal server error!"})
-			}
-			remainingFreeSpace, err := utils.GetRemainingFolderSpace()
-			if err != nil {
-				return c.Status(520).JSON(fiber.Map{"err": "Internal server error!"})
-			}
-
-			if folderSize > remainingFreeSpace {
-				return c.Status(507).JSON(fiber.Map{"err": "Not enough space!"})
-			}
-		}
-
-		copyPath := fmt.Sprintf("%s/%s", parentPath, basename)
-
-		for utils.IsExistingPath(config.GetScopedFolder(scope) + copyPath) {
-			index++
-			copyPath = fmt.Sprintf("%s/%s (%d)", parentPath, basename, index)
-		}
+	if !jobs.Allowed(account, job) {
+		return c.Status(403).JSON(fiber.Map{"err": "No permission"})
+	}
 
-		if err := utils.CopyDirectory(config.GetScopedFolder(scope)+path, config.GetScopedFolder(scope)+copyPath); err != nil {
-			return c.Status(520).JSON(fiber.Map{"err": "Internal server error!"})
-		}
+	job, err := jobs.Enqueue(job)
+	if err != nil {
+		return c.Status(520).JSON(fiber.Map{"err": "Internal server error!"})
+	}
 
-		logs.CreateLog(types.AuditLog{
-			Username:    c.Locals("account").(types.Account).Username,
-			Action:      "Create copy",
-			Description: fmt.Sprintf("%s created a copy of %s", c.Locals("account").(types.Account).Username, path),
+	job, finished := jobs.Wait(job.ID, copyWait)
+	if !finished {
+		return c.Status(202).JSON(fiber.Map{
+			"response": "Copying in the background!",
+			"job":      job,
 		})
 	}
 
-	return c.Status(200).JSON(fiber.Map{"err": "Copied!"})
+	switch job.Status {
+	case types.JobCompleted:
+		return c.Status(200).JSON(fiber.Map{"response": "Copied!", "job": job})
+	case types.JobFailed:
+		if job.Error == "Not enough space!" {
+			return c.Status(507).JSON(fiber.Map{"err": job.Error})
+		}
+		return c.Status(520).JSON(fiber.Map{"err": job.Error})
+	default:
+		return c.Status(409).JSON(fiber.Map{"err": "The copy was cancelled!"})
+	}
 }
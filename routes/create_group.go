@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/database/groups"
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	aclUtils "github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/gofiber/fiber/v2"
+)
+
+func CreateGroup(c *fiber.Ctx) error {
+	if !c.Locals("account").(types.Account).Permissions.EditUsers {
+		return c.Status(403).JSON(
+			fiber.Map{"err": "No permission!"},
+		)
+	}
+
+	var requestBody struct {
+		Group types.Group `json:"group"`
+	}
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	if err := validateGroup(&requestBody.Group); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": err.Error()},
+		)
+	}
+
+	err := groups.CreateGroup(&requestBody.Group)
+
+	if err != nil {
+		if errors.Is(err, groups.ErrGroupExists) {
+			return c.Status(400).JSON(
+				fiber.Map{"err": "Group already exists."},
+			)
+		}
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Unknown server error."},
+		)
+	}
+
+	if err := groups.ReplaceRules(*requestBody.Group.ID, requestBody.Group.ACL); err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Unknown server error."},
+		)
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    c.Locals("account").(types.Account).Username,
+		Action:      "Create group",
+		Description: fmt.Sprintf("%s created a new group %s", c.Locals("account").(types.Account).Username, requestBody.Group.Name),
+	})
+
+	return c.Status(200).JSON(
+		fiber.Map{"response": "Group successfully created!", "group": requestBody.Group},
+	)
+}
+
+// validateGroup checks the group and normalizes the paths of its rules.
+func validateGroup(group *types.Group) error {
+	if strings.TrimSpace(group.Name) == "" {
+		return fmt.Errorf("Group name is missing.")
+	}
+
+	if strings.Contains(group.Scope, "..") {
+		return fmt.Errorf("Invalid scope.")
+	}
+
+	if group.ACL != nil {
+		rules, err := aclUtils.ValidateRules(group.ACL)
+		if err != nil {
+			return fmt.Errorf("Bad request! %s", err.Error())
+		}
+		group.ACL = rules
+	}
+
+	return nil
+}
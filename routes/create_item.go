@@ -8,7 +8,9 @@ import (
 	"github.com/MertJSX/folder-host-go/database/logs"
 	"github.com/MertJSX/folder-host-go/types"
 	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
 	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -22,7 +24,7 @@ func CreateItem(c *fiber.Ctx) error {
 		scope    string = c.Locals("account").(types.Account).Scope
 	)
 
-	if !account.Permissions.Create {
+	if !acl.Allowed(account, types.PermissionCreate, itemPath+"/"+itemName) {
 		return c.Status(403).JSON(
 			fiber.Map{"err": "No permission!"},
 		)
@@ -50,6 +52,8 @@ func CreateItem(c *fiber.Ctx) error {
 			)
 		}
 
+		sizeindex.Refresh(fmt.Sprintf("%s%s/%s", config.GetScopedFolder(scope), itemPath, itemName))
+
 		logs.CreateLog(types.AuditLog{
 			Username:    c.Locals("account").(types.Account).Username,
 			Action:      "Create folder",
@@ -67,6 +71,8 @@ func CreateItem(c *fiber.Ctx) error {
 			)
 		}
 
+		sizeindex.Refresh(fmt.Sprintf("%s%s/%s", config.GetScopedFolder(scope), itemPath, itemName))
+
 		logs.CreateLog(types.AuditLog{
 			Username:    c.Locals("account").(types.Account).Username,
 			Action:      "Create file",
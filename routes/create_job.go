@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/jobs"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateJob queues an archive, extract, copy, move or delete job. The destination is a folder,
+// the folder of the source if it's empty. Deletes don't use it. format is the archive format to create, zip if
+// it's empty, or the format of the archive to extract, detected from the name if it's empty. Extract jobs with
+// entries only extract these entries of the archive, see GetArchiveEntries.
+func CreateJob(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	var requestBody struct {
+		Type        string   `json:"type"`
+		Path        string   `json:"path"`
+		Destination string   `json:"destination"`
+		Format      string   `json:"format"`
+		Entries     []string `json:"entries"`
+	}
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	job := types.Job{
+		Type:        requestBody.Type,
+		Username:    account.Username,
+		Source:      requestBody.Path,
+		Destination: requestBody.Destination,
+		Format:      requestBody.Format,
+		Entries:     requestBody.Entries,
+	}
+
+	if err := jobs.Validate(account, &job); err != nil {
+		return c.Status(400).JSON(fiber.Map{"err": err.Error()})
+	}
+
+	if !jobs.Allowed(account, job) {
+		return c.Status(403).JSON(fiber.Map{"err": "No permission!"})
+	}
+
+	job, err := jobs.Enqueue(job)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Create job",
+		Description: fmt.Sprintf("%s started a %s job for %s.", account.Username, job.Type, job.Source),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"response": "Job queued!",
+		"job":      job,
+	})
+}
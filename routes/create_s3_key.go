@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/s3keys"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateS3Key issues an access key for the account. The secret is only returned here.
+func CreateS3Key(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	if !config.Config.S3Gateway {
+		return c.Status(403).JSON(fiber.Map{"err": "The S3 gateway is disabled!"})
+	}
+
+	key, err := s3keys.CreateS3Key(account.Username)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Create S3 key",
+		Description: fmt.Sprintf("%s created S3 access key %s", account.Username, key.AccessKeyID),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"response": "S3 access key created!",
+		"key":      key,
+	})
+}
@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/sftpkeys"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/ssh"
+)
+
+// CreateSFTPKey adds a public key the account can log in to the SFTP server with. The key is a line
+// of an authorized_keys file, like "ssh-ed25519 AAAA... mert@laptop".
+func CreateSFTPKey(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	var requestBody struct {
+		PublicKey string `json:"publicKey"`
+	}
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	publicKey, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(requestBody.PublicKey))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"err": "Invalid public key!"})
+	}
+
+	key := types.SFTPKey{
+		Username:    account.Username,
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))),
+		Fingerprint: ssh.FingerprintSHA256(publicKey),
+		Comment:     comment,
+	}
+
+	if _, err := sftpkeys.GetSFTPKeyByFingerprint(key.Username, key.Fingerprint); err == nil {
+		return c.Status(409).JSON(fiber.Map{"err": "This key is already added!"})
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	if err := sftpkeys.CreateSFTPKey(&key); err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Create SFTP key",
+		Description: fmt.Sprintf("%s added SFTP key %s", account.Username, key.Fingerprint),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"response": "SFTP key added!",
+		"key":      key,
+	})
+}
@@ -0,0 +1,107 @@
+package routes
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/shares"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/password"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateShareLink creates a public /s/:token link for a file or folder. Read links need the download permission,
+// file drops need the upload permission on the folder.
+func CreateShareLink(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	var requestBody struct {
+		Path      string     `json:"path"`
+		Mode      string     `json:"mode"`
+		Password  string     `json:"password"`
+		ExpiresAt *time.Time `json:"expiresAt"`
+		MaxUses   int        `json:"maxUses"`
+	}
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	if requestBody.Path == "" {
+		return c.Status(400).JSON(fiber.Map{"err": "Path is missing!"})
+	}
+
+	if requestBody.Mode == "" {
+		requestBody.Mode = types.ShareModeRead
+	}
+
+	if requestBody.ExpiresAt != nil && !requestBody.ExpiresAt.After(time.Now()) {
+		return c.Status(400).JSON(fiber.Map{"err": "Expiry date must be in the future!"})
+	}
+
+	if requestBody.MaxUses < 0 {
+		return c.Status(400).JSON(fiber.Map{"err": "Max uses can't be negative!"})
+	}
+
+	// Normalizing cleans ".." segments, the body isn't checked by CheckAuth like the path query
+	requestBody.Path = acl.NormalizePath(requestBody.Path)
+
+	pathStat, err := os.Stat(config.Config.GetScopedFolder(account.Scope) + requestBody.Path)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"err": "The item doesn't exist!"})
+	}
+
+	switch requestBody.Mode {
+	case types.ShareModeRead:
+		if !acl.Allowed(account, types.PermissionDownloadFiles, requestBody.Path) {
+			return c.Status(403).JSON(fiber.Map{"err": "No permission!"})
+		}
+	case types.ShareModeUpload:
+		if !acl.Allowed(account, types.PermissionUploadFiles, requestBody.Path) {
+			return c.Status(403).JSON(fiber.Map{"err": "No permission!"})
+		}
+		if !pathStat.IsDir() {
+			return c.Status(400).JSON(fiber.Map{"err": "File drops must be folders!"})
+		}
+	default:
+		return c.Status(400).JSON(fiber.Map{"err": "Mode must be \"read\" or \"upload\"!"})
+	}
+
+	link := types.ShareLink{
+		Username:    account.Username,
+		Path:        requestBody.Path,
+		IsDirectory: pathStat.IsDir(),
+		Mode:        requestBody.Mode,
+		ExpiresAt:   requestBody.ExpiresAt,
+		MaxUses:     requestBody.MaxUses,
+	}
+
+	if requestBody.Password != "" {
+		link.PasswordHash, err = password.Hash(requestBody.Password)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+		}
+	}
+
+	if err := shares.CreateShareLink(&link); err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Create share link",
+		Description: fmt.Sprintf("%s created a %s share link %d for %s", account.Username, link.Mode, link.ID, link.Path),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"response": "Share link created!",
+		"link":     link,
+		"url":      "/s/" + link.Token,
+	})
+}
@@ -3,9 +3,11 @@ package routes
 import (
 	"fmt"
 
+	"github.com/MertJSX/folder-host-go/database/acl"
 	"github.com/MertJSX/folder-host-go/database/logs"
 	"github.com/MertJSX/folder-host-go/database/users"
 	"github.com/MertJSX/folder-host-go/types"
+	aclUtils "github.com/MertJSX/folder-host-go/utils/acl"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -38,7 +40,21 @@ func CreateUser(c *fiber.Ctx) error {
 		)
 	}
 
-	err := users.CreateUser(&requestBody.User)
+	rules, err := aclUtils.ValidateRules(requestBody.User.ACL)
+
+	if err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	if err := validateUserGroups(requestBody.User); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	err = users.CreateUser(&requestBody.User)
 
 	if err != nil {
 		if err.Error() == "username already exists" {
@@ -51,6 +67,20 @@ func CreateUser(c *fiber.Ctx) error {
 		)
 	}
 
+	if err := saveUserGroups(requestBody.User); err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "User was created, but groups couldn't be saved."},
+		)
+	}
+
+	if len(rules) > 0 {
+		if err := acl.ReplaceRules(requestBody.User.Username, rules); err != nil {
+			return c.Status(500).JSON(
+				fiber.Map{"err": "User was created, but path rules couldn't be saved."},
+			)
+		}
+	}
+
 	logs.CreateLog(types.AuditLog{
 		Username:    c.Locals("account").(types.Account).Username,
 		Action:      "Create user",
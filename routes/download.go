@@ -1,11 +1,19 @@
 package routes
 
 import (
+	"bufio"
 	"fmt"
+	"log"
+	"mime"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/users"
 	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
 	"github.com/MertJSX/folder-host-go/utils/cache"
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,6 +27,10 @@ func Download(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"err": "ID not found"})
 	}
 
+	if downloadLinkCache.ArchiveFormat != "" {
+		return downloadArchive(c, downloadLinkCache)
+	}
+
 	fileinfo, err := os.Stat(downloadLinkCache.Path)
 
 	// Validation to avoid errors
@@ -34,15 +46,65 @@ func Download(c *fiber.Ctx) error {
 		)
 	}
 
+	// Links stay valid while they are used, resumed downloads come back with ranges
+	cache.DownloadLinkCache.Set(id, downloadLinkCache, 1*time.Minute)
+
+	if utils.IsDownloadStart(c) {
+		logs.CreateLog(types.AuditLog{
+			Username:    downloadLinkCache.Username,
+			Action:      "Download",
+			Description: fmt.Sprintf("%s downloaded a %s file.", downloadLinkCache.Username, downloadLinkCache.Path),
+		})
+	}
+
+	disposition := utils.DispositionAttachment
+	if c.QueryBool("inline") {
+		disposition = utils.DispositionInline
+	}
+
+	return utils.SendFile(c, downloadLinkCache.Path, fileinfo, disposition)
+}
+
+// downloadArchive streams the archive straight into the response. Entries are checked against the current ACL
+// of the user, rules added after the link was created apply too.
+func downloadArchive(c *fiber.Ctx, downloadLinkCache types.DownloadLinkCache) error {
+	account, err := users.GetUserByUsername(downloadLinkCache.Username)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"err": "account not found"})
+	}
+
 	logs.CreateLog(types.AuditLog{
 		Username:    downloadLinkCache.Username,
 		Action:      "Download",
-		Description: fmt.Sprintf("%s downloaded a %s file.", downloadLinkCache.Username, downloadLinkCache.Path),
+		Description: fmt.Sprintf("%s downloaded %s as %s.", downloadLinkCache.Username, archiveSourceNames(downloadLinkCache.Paths), downloadLinkCache.ArchiveName),
 	})
 
-	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileinfo.Name()))
-	c.Set("Content-Type", "application/octet-stream")
-	c.Set("Content-Length", fmt.Sprintf("%d", fileinfo.Size()))
+	contentType := "application/zip"
+	if downloadLinkCache.ArchiveFormat == utils.ArchiveFormatTarGz {
+		contentType = "application/gzip"
+	}
+
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition", mime.FormatMediaType(utils.DispositionAttachment, map[string]string{"filename": downloadLinkCache.ArchiveName}))
 
-	return c.SendFile(downloadLinkCache.Path)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		allowed := func(scopePath string) bool {
+			return acl.Allowed(account, types.PermissionDownloadFiles, scopePath)
+		}
+
+		// The status is already sent, a failed archive can only be cut off
+		if err := utils.StreamArchive(w, downloadLinkCache.ArchiveFormat, downloadLinkCache.Paths, allowed); err != nil {
+			log.Printf("Archive download error: %v\n", err)
+		}
+	})
+
+	return nil
+}
+
+func archiveSourceNames(sources []types.ArchiveSource) string {
+	names := make([]string, 0, len(sources))
+	for _, source := range sources {
+		names = append(names, source.ScopePath)
+	}
+	return strings.Join(names, ", ")
 }
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// versionFileInfo names the download like the file, the stored version has a unique prefix
+type versionFileInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (v versionFileInfo) Name() string {
+	return v.name
+}
+
+func DownloadVersion(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	version, itemPath, status, message := getOwnVersion(account, c.Params("id"), types.PermissionDownloadFiles)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"err": message})
+	}
+
+	stat, err := os.Stat(version.Location)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"err": "Version doesn't exist."})
+	}
+
+	if utils.IsDownloadStart(c) {
+		logs.CreateLog(types.AuditLog{
+			Username:    account.Username,
+			Action:      "Download",
+			Description: fmt.Sprintf("%s downloaded version %d of a %s file.", account.Username, version.ID, itemPath),
+		})
+	}
+
+	return utils.SendFile(c, version.Location, versionFileInfo{stat, path.Base(itemPath)}, utils.DispositionAttachment)
+}
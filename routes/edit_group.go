@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database/groups"
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/gofiber/fiber/v2"
+)
+
+func EditGroup(c *fiber.Ctx) error {
+	if !c.Locals("account").(types.Account).Permissions.EditUsers {
+		return c.Status(403).JSON(
+			fiber.Map{"err": "No permission!"},
+		)
+	}
+
+	var requestBody struct {
+		Group types.Group `json:"group"`
+	}
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	if requestBody.Group.ID == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"err": "Bad request. Group's ID is missing!",
+		})
+	}
+
+	if err := validateGroup(&requestBody.Group); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": err.Error()},
+		)
+	}
+
+	err := groups.UpdateGroup(*requestBody.Group.ID, &requestBody.Group)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Group doesn't exist."},
+		)
+	} else if err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Unknown server error."},
+		)
+	}
+
+	// Older clients don't send the rules, they are kept then
+	if requestBody.Group.ACL != nil {
+		if err := groups.ReplaceRules(*requestBody.Group.ID, requestBody.Group.ACL); err != nil {
+			return c.Status(500).JSON(
+				fiber.Map{"err": "Unknown server error."},
+			)
+		}
+	}
+
+	// Every member has to resolve the permissions again
+	cache.SessionCache.Clear()
+
+	logs.CreateLog(types.AuditLog{
+		Username:    c.Locals("account").(types.Account).Username,
+		Action:      "Edit group",
+		Description: fmt.Sprintf("%s modified group %s", c.Locals("account").(types.Account).Username, requestBody.Group.Name),
+	})
+
+	return c.Status(200).JSON(
+		fiber.Map{"response": "Group successfully edited!"},
+	)
+}
@@ -3,9 +3,11 @@ package routes
 import (
 	"fmt"
 
+	"github.com/MertJSX/folder-host-go/database/groups"
 	"github.com/MertJSX/folder-host-go/database/logs"
 	"github.com/MertJSX/folder-host-go/database/users"
 	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
 	"github.com/MertJSX/folder-host-go/utils/cache"
 	"github.com/gofiber/fiber/v2"
 )
@@ -47,6 +49,12 @@ func EditUser(c *fiber.Ctx) error {
 		)
 	}
 
+	if err := validateUserGroups(requestBody.User); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
 	err = users.UpdateUser(*requestBody.User.ID, &requestBody.User)
 
 	if err != nil {
@@ -55,10 +63,23 @@ func EditUser(c *fiber.Ctx) error {
 		)
 	}
 
+	if err := saveUserGroups(requestBody.User); err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "User was edited, but groups couldn't be saved."},
+		)
+	}
+
 	if _, ok := cache.SessionCache.Get(username); ok {
 		cache.SessionCache.Delete(username)
 	}
 
+	// The refresh tokens still have the old username
+	if err := revokeUserSessions(*requestBody.User.ID, username); err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "User was edited, but old sessions couldn't be revoked."},
+		)
+	}
+
 	logs.CreateLog(types.AuditLog{
 		Username:    c.Locals("account").(types.Account).Username,
 		Action:      "Edit user",
@@ -69,3 +90,45 @@ func EditUser(c *fiber.Ctx) error {
 		fiber.Map{"response": "User successfully edited!"},
 	)
 }
+
+// validateUserGroups checks the quota, groups and permission overrides of the request before anything is saved.
+func validateUserGroups(user types.Account) error {
+	if _, err := utils.ParseQuota(user.Quota); err != nil {
+		return err
+	}
+
+	for permission := range user.PermissionOverrides {
+		if _, ok := user.Permissions.Get(permission); !ok {
+			return fmt.Errorf("unknown permission %q", permission)
+		}
+	}
+
+	for _, name := range user.Groups {
+		exists, err := groups.CheckIfGroupExists(name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", groups.ErrUnknownGroup, name)
+		}
+	}
+
+	return nil
+}
+
+// saveUserGroups stores the groups and permission overrides only if the request contains them.
+func saveUserGroups(user types.Account) error {
+	if user.Groups != nil {
+		if err := groups.SetUserGroups(user.Username, user.Groups); err != nil {
+			return err
+		}
+	}
+
+	if user.PermissionOverrides != nil {
+		if err := users.SetPermissionOverrides(user.Username, user.PermissionOverrides); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
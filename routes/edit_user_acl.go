@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/MertJSX/folder-host-go/database/acl"
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/types"
+	aclUtils "github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/gofiber/fiber/v2"
+)
+
+// EditUserACL replaces all path rules of the user with the rules in the request.
+func EditUserACL(c *fiber.Ctx) error {
+	if !c.Locals("account").(types.Account).Permissions.EditUsers {
+		return c.Status(403).JSON(
+			fiber.Map{"err": "No permission!"},
+		)
+	}
+
+	idToInt, err := strconv.Atoi(c.Params("id"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"err": "Bad request",
+		})
+	}
+
+	if idToInt == 1 {
+		return c.Status(400).JSON(fiber.Map{
+			"err": "You can't update admin account from the web panel.",
+		})
+	}
+
+	var requestBody struct {
+		Rules []types.ACLRule `json:"rules"`
+	}
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	rules, err := aclUtils.ValidateRules(requestBody.Rules)
+
+	if err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	username, err := users.GetUsername(idToInt)
+
+	if err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "User doesn't exist."},
+		)
+	}
+
+	if err := acl.ReplaceRules(username, rules); err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Unknown server error."},
+		)
+	}
+
+	cache.SessionCache.Delete(username)
+
+	logs.CreateLog(types.AuditLog{
+		Username:    c.Locals("account").(types.Account).Username,
+		Action:      "Edit user ACL",
+		Description: fmt.Sprintf("%s set %d path rules for %s", c.Locals("account").(types.Account).Username, len(rules), username),
+	})
+
+	return c.Status(200).JSON(
+		fiber.Map{"response": "Path rules saved!"},
+	)
+}
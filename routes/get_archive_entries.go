@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"errors"
+	"os"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetArchiveEntries lists the files and folders in an archive without extracting it. The paths of the items
+// are paths inside the archive, they can be read with /api/archive/read or extracted with an extract job.
+func GetArchiveEntries(c *fiber.Ctx) error {
+	diskPath, format, status, message := getArchive(c.Locals("account").(types.Account), c.Query("path"), c.Query("format"))
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"err": message})
+	}
+
+	items, err := utils.ListArchive(format, diskPath)
+	if errors.Is(err, utils.ErrTooManyEntries) {
+		return c.Status(413).JSON(fiber.Map{"err": "The archive has too many files!"})
+	} else if err != nil {
+		return c.Status(400).JSON(fiber.Map{"err": "The archive is damaged!"})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"items":  items,
+		"format": format,
+	})
+}
+
+// getArchive checks the archive at the path in the scope of the account, the format is detected from the
+// name if it's empty. Otherwise it returns the status and the error message.
+func getArchive(account types.Account, path string, format string) (string, string, int, string) {
+	if path == "" {
+		return "", "", 400, "Bad request!"
+	}
+
+	path = acl.NormalizePath(path)
+	if !acl.Allowed(account, types.PermissionReadFiles, path) {
+		return "", "", 403, "No permission!"
+	}
+
+	diskPath := config.Config.GetScopedFolder(account.Scope) + path
+	if !utils.IsSafePath(diskPath) {
+		return "", "", 400, "Bad request!"
+	}
+
+	info, err := os.Stat(diskPath)
+	if err != nil {
+		return "", "", 404, "The archive doesn't exist!"
+	}
+
+	if format == "" {
+		format, _ = utils.DetectArchiveFormat(path)
+	}
+	if info.IsDir() || !utils.IsArchiveFormat(format) {
+		return "", "", 400, "Only zip and tar archives can be opened!"
+	}
+
+	return diskPath, format, 0, ""
+}
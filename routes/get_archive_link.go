@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetArchiveLink creates a download link for a multi-selection. The items are streamed as one ZIP or tar.gz
+// by Download, like folders from GetDownloadLink.
+func GetArchiveLink(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	var requestBody struct {
+		Paths  []string `json:"paths"`
+		Format string   `json:"format"`
+	}
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	if len(requestBody.Paths) == 0 {
+		return c.Status(400).JSON(fiber.Map{"err": "Paths are missing!"})
+	}
+
+	if requestBody.Format == "" {
+		requestBody.Format = utils.ArchiveFormatZip
+	}
+	if requestBody.Format != utils.ArchiveFormatZip && requestBody.Format != utils.ArchiveFormatTarGz {
+		return c.Status(400).JSON(fiber.Map{"err": "Format must be \"zip\" or \"tar.gz\""})
+	}
+
+	sources := make([]types.ArchiveSource, 0, len(requestBody.Paths))
+	names := make(map[string]bool, len(requestBody.Paths))
+
+	for _, path := range requestBody.Paths {
+		// The body isn't checked by CheckAuth like the path queries
+		if !utils.IsSafePath(path) {
+			return c.Status(403).JSON(fiber.Map{"err": "forbidden"})
+		}
+
+		path = acl.NormalizePath(path)
+
+		if !acl.Allowed(account, types.PermissionDownloadFiles, path) {
+			return c.Status(403).JSON(fiber.Map{"err": "No permission!"})
+		}
+
+		diskPath := config.Config.GetScopedFolder(account.Scope) + path
+		fileinfo, err := os.Stat(diskPath)
+		if err != nil || path == "/" {
+			return c.Status(400).JSON(fiber.Map{"err": fmt.Sprintf("Wrong path %s!", path)})
+		}
+
+		// Every item is a top-level entry, two items with the same name would mix up
+		if names[fileinfo.Name()] {
+			return c.Status(400).JSON(fiber.Map{"err": fmt.Sprintf("%s is selected twice!", fileinfo.Name())})
+		}
+		names[fileinfo.Name()] = true
+
+		sources = append(sources, types.ArchiveSource{DiskPath: diskPath, ScopePath: path})
+	}
+
+	return c.Status(200).JSON(
+		fiber.Map{"id": createArchiveLink(account, sources, requestBody.Format)},
+	)
+}
+
+// createArchiveLink stores a download link for the items streamed as one archive and returns its id.
+func createArchiveLink(account types.Account, sources []types.ArchiveSource, format string) string {
+	randomID := utils.GenerateUniqueString()
+
+	cache.DownloadLinkCache.Set(randomID, types.DownloadLinkCache{
+		Username:      account.Username,
+		ArchiveFormat: format,
+		ArchiveName:   "download." + format,
+		Paths:         sources,
+	}, 1*time.Minute)
+
+	return randomID
+}
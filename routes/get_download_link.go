@@ -7,6 +7,7 @@ import (
 
 	"github.com/MertJSX/folder-host-go/types"
 	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
 	"github.com/MertJSX/folder-host-go/utils/cache"
 	"github.com/MertJSX/folder-host-go/utils/config"
 	"github.com/gofiber/fiber/v2"
@@ -14,7 +15,7 @@ import (
 
 func GetDownloadLink(c *fiber.Ctx) error {
 
-	if !c.Locals("account").(types.Account).Permissions.DownloadFiles {
+	if !acl.Allowed(c.Locals("account").(types.Account), types.PermissionDownloadFiles, c.Query("filepath")) {
 		return c.Status(403).JSON(
 			fiber.Map{"err": "No permission!"},
 		)
@@ -31,15 +32,27 @@ func GetDownloadLink(c *fiber.Ctx) error {
 		return c.JSON(
 			fiber.Map{"err": "Wrong filepath!"},
 		)
-	} else if fileinfo.IsDir() {
-		return c.JSON(
-			fiber.Map{"err": "You can't download a directory!"},
-		)
+	}
+
+	downloadLink := types.DownloadLinkCache{Path: filepath, Username: c.Locals("account").(types.Account).Username}
+
+	// Directories are streamed as an archive, nothing is written to the disk
+	if fileinfo.IsDir() {
+		format := c.Query("format", utils.ArchiveFormatZip)
+		if format != utils.ArchiveFormatZip && format != utils.ArchiveFormatTarGz {
+			return c.Status(400).JSON(
+				fiber.Map{"err": "Format must be \"zip\" or \"tar.gz\""},
+			)
+		}
+
+		downloadLink.ArchiveFormat = format
+		downloadLink.ArchiveName = fileinfo.Name() + "." + format
+		downloadLink.Paths = []types.ArchiveSource{{DiskPath: filepath, ScopePath: c.Query("filepath")}}
 	}
 
 	randomID := utils.GenerateUniqueString()
 
-	cache.DownloadLinkCache.Set(randomID, types.DownloadLinkCache{Path: filepath, Username: c.Locals("account").(types.Account).Username}, 1*time.Minute)
+	cache.DownloadLinkCache.Set(randomID, downloadLink, 1*time.Minute)
 
 	return c.Status(200).JSON(
 		fiber.Map{"id": randomID},
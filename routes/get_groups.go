@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/MertJSX/folder-host-go/database/groups"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+func GetGroups(c *fiber.Ctx) error {
+	if !c.Locals("account").(types.Account).Permissions.ReadUsers {
+		return c.Status(403).JSON(
+			fiber.Map{"err": "No permission!"},
+		)
+	}
+
+	allGroups, err := groups.GetAll()
+
+	if err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Unknown error!"},
+		)
+	}
+
+	return c.Status(200).JSON(
+		fiber.Map{"groups": allGroups},
+	)
+}
@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/jobs"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetJobs lists the latest jobs of the account, the newest first. Admins can list the jobs of everyone with ?all=true.
+func GetJobs(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	username := account.Username
+	if c.QueryBool("all") {
+		if !account.Permissions.EditUsers {
+			return c.Status(403).JSON(
+				fiber.Map{"err": "No permission!"},
+			)
+		}
+		username = ""
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	list, err := jobs.List(username, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	return c.Status(200).JSON(fiber.Map{"jobs": list})
+}
+
+func GetJob(c *fiber.Ctx) error {
+	job, status, message := getOwnJob(c.Locals("account").(types.Account), c.Params("id"))
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"err": message})
+	}
+
+	return c.Status(200).JSON(fiber.Map{"job": job})
+}
+
+// getOwnJob returns the job with the id if it belongs to the account, admins can access every job.
+// Otherwise it returns the status and the error message.
+func getOwnJob(account types.Account, jobID string) (types.Job, int, string) {
+	id, err := strconv.Atoi(jobID)
+	if err != nil {
+		return types.Job{}, 400, "Bad request"
+	}
+
+	job, err := jobs.Get(id)
+	if errors.Is(err, jobs.ErrNotFound) {
+		return types.Job{}, 404, "Job doesn't exist."
+	} else if err != nil {
+		return types.Job{}, 500, "Internal server error"
+	}
+
+	// Jobs of other users answer like missing ones
+	if job.Username != account.Username && !account.Permissions.EditUsers {
+		return types.Job{}, 404, "Job doesn't exist."
+	}
+
+	return job, 0, ""
+}
@@ -2,14 +2,19 @@ package routes
 
 import (
 	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/acl"
 	"github.com/gofiber/fiber/v2"
 )
 
 func GetPermissions(c *fiber.Ctx) error {
+	response := fiber.Map{
+		"permissions": c.Locals("account").(types.Account).Permissions,
+	}
 
-	return c.JSON(
-		fiber.Map{
-			"permissions": c.Locals("account").(types.Account).Permissions,
-		},
-	)
+	// With a path, the permissions are resolved with the ACL rules of the account
+	if path := c.Query("path"); path != "" {
+		response["pathPermissions"] = acl.Effective(c.Locals("account").(types.Account), path)
+	}
+
+	return c.JSON(response)
 }
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetQuota reports the usage of the account's scope, like "3.2 GB of 5 GB".
+func GetQuota(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	used, limit, err := utils.GetQuotaUsage(account)
+
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	limitDisplay := "UNLIMITED"
+	if limit > 0 {
+		limitDisplay = utils.ConvertBytesToString(limit)
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"used":       utils.ConvertBytesToString(used),
+		"usedBytes":  used,
+		"limit":      limitDisplay,
+		"limitBytes": limit,
+	})
+}
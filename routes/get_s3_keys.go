@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/MertJSX/folder-host-go/database/s3keys"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetS3Keys lists the access keys of the account without secrets. Admins can list every key with ?all=true.
+func GetS3Keys(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	username := account.Username
+	if c.QueryBool("all") {
+		if !account.Permissions.EditUsers {
+			return c.Status(403).JSON(
+				fiber.Map{"err": "No permission!"},
+			)
+		}
+		username = ""
+	}
+
+	keys, err := s3keys.GetS3Keys(username)
+	if err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Unknown server error!"},
+		)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"keys": keys})
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/MertJSX/folder-host-go/database/sftpkeys"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetSFTPKeys lists the public keys of the account. Admins can list every key with ?all=true.
+func GetSFTPKeys(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	username := account.Username
+	if c.QueryBool("all") {
+		if !account.Permissions.EditUsers {
+			return c.Status(403).JSON(
+				fiber.Map{"err": "No permission!"},
+			)
+		}
+		username = ""
+	}
+
+	keys, err := sftpkeys.GetSFTPKeys(username)
+	if err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Unknown server error!"},
+		)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"keys": keys})
+}
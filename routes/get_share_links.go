@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/MertJSX/folder-host-go/database/shares"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetShareLinks lists the links of the account. Admins can list the links of everyone with ?all=true.
+func GetShareLinks(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	username := account.Username
+	if c.QueryBool("all") {
+		if !account.Permissions.EditUsers {
+			return c.Status(403).JSON(
+				fiber.Map{"err": "No permission!"},
+			)
+		}
+		username = ""
+	}
+
+	links, err := shares.GetShareLinks(username)
+	if err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Unknown server error!"},
+		)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"links": links})
+}
@@ -23,10 +23,7 @@ func GetUser(c *fiber.Ctx) error {
 	}
 
 	if cacheUser, ok := cache.SessionCache.Get(username); ok {
-		cacheUser.Password = ""
-		return c.Status(200).JSON(
-			fiber.Map{"user": cacheUser},
-		)
+		return c.Status(200).JSON(userResponse(cacheUser))
 	}
 
 	isExist, err := users.CheckIfUsernameExists(username)
@@ -51,9 +48,21 @@ func GetUser(c *fiber.Ctx) error {
 		)
 	}
 
+	return c.Status(200).JSON(userResponse(user))
+}
+
+// userResponse returns the values stored on the user, so they can be edited and sent back.
+// The permissions, scope and path rules resolved with the groups are returned next to them.
+func userResponse(user types.Account) fiber.Map {
+	effectivePermissions, effectiveScope, effectiveACL := user.Permissions, user.Scope, user.ACL
+
 	user.Password = ""
+	user.Permissions, user.Scope, user.ACL = user.OwnPermissions, user.OwnScope, user.OwnACL
 
-	return c.Status(200).JSON(
-		fiber.Map{"user": user},
-	)
+	return fiber.Map{
+		"user":                 user,
+		"effectivePermissions": effectivePermissions,
+		"effectiveScope":       effectiveScope,
+		"effectiveACL":         effectiveACL,
+	}
 }
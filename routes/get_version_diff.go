@@ -0,0 +1,116 @@
+package routes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"unicode/utf8"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/textdiff"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Bigger files aren't compared, the diff is built in memory
+const maxDiffFileSize = 1024 * 1024
+
+var (
+	errNotText      = errors.New("not a text file")
+	errTooBigToDiff = errors.New("file is too big")
+)
+
+// GetVersionDiff returns the unified diff from the version in :id to the version in ?to, or to the current
+// file when ?to is missing or "current". Both must be versions of the same file.
+func GetVersionDiff(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	from, itemPath, status, message := getOwnVersion(account, c.Params("id"), types.PermissionReadFiles)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"err": message})
+	}
+
+	toLocation := config.Config.GetScopedFolder(account.Scope) + itemPath
+	toName := fmt.Sprintf("%s (current)", itemPath)
+
+	if to := c.Query("to", "current"); to != "current" {
+		toVersion, _, status, message := getOwnVersion(account, to, types.PermissionReadFiles)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"err": message})
+		}
+
+		if toVersion.Path != from.Path {
+			return c.Status(400).JSON(fiber.Map{"err": "Versions of different files can't be compared!"})
+		}
+
+		toLocation = toVersion.Location
+		toName = versionName(itemPath, toVersion)
+	}
+
+	fromText, err := readTextForDiff(from.Location)
+	if err != nil {
+		return diffReadError(c, err)
+	}
+
+	toText, err := readTextForDiff(toLocation)
+	if err != nil {
+		return diffReadError(c, err)
+	}
+
+	diff, err := textdiff.Unified(versionName(itemPath, from), toName, fromText, toText)
+	if errors.Is(err, textdiff.ErrTooManyChanges) {
+		return c.Status(422).JSON(fiber.Map{"err": "The versions are too different to compare!"})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Internal server error"})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"name": path.Base(itemPath),
+		"diff": diff,
+	})
+}
+
+func versionName(itemPath string, version types.FileVersion) string {
+	return fmt.Sprintf("%s (version %d, %s)", itemPath, version.ID, version.CreatedAt.Format("2006-01-02 15:04:05"))
+}
+
+// readTextForDiff reads a text file, a missing file is compared as empty.
+func readTextForDiff(location string) (string, error) {
+	stat, err := os.Stat(location)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	if stat.IsDir() {
+		return "", errNotText
+	}
+
+	if stat.Size() > maxDiffFileSize {
+		return "", errTooBigToDiff
+	}
+
+	data, err := os.ReadFile(location)
+	if err != nil {
+		return "", err
+	}
+
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) != -1 {
+		return "", errNotText
+	}
+
+	return string(data), nil
+}
+
+func diffReadError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errNotText):
+		return c.Status(400).JSON(fiber.Map{"err": "Only text files can be compared!"})
+	case errors.Is(err, errTooBigToDiff):
+		return c.Status(413).JSON(fiber.Map{"err": "Files bigger than 1 MB can't be compared!"})
+	}
+	return c.Status(500).JSON(fiber.Map{"err": "Internal server error"})
+}
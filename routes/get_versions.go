@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/database/versions"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	fileVersions "github.com/MertJSX/folder-host-go/utils/versions"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetVersions lists the old versions of ?path, the newest first.
+func GetVersions(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	itemPath := acl.NormalizePath(c.Query("path"))
+	if itemPath == "/" || !utils.IsSafePath(itemPath) {
+		return c.Status(400).JSON(fiber.Map{"err": "Invalid path!"})
+	}
+
+	if !acl.Allowed(account, types.PermissionReadFiles, itemPath) {
+		return c.Status(403).JSON(fiber.Map{"err": "No permission!"})
+	}
+
+	relPath, ok := fileVersions.PathOf(config.Config.GetScopedFolder(account.Scope) + itemPath)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"err": "Invalid path!"})
+	}
+
+	list, err := versions.GetVersionsByPath(relPath)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	return c.Status(200).JSON(fiber.Map{"versions": list})
+}
+
+// getOwnVersion returns the version with the id and the path of its file in the scope of the account. The account
+// needs the permission on the file, otherwise it returns the status and the error message.
+func getOwnVersion(account types.Account, versionID string, permission string) (types.FileVersion, string, int, string) {
+	id, err := strconv.Atoi(versionID)
+	if err != nil {
+		return types.FileVersion{}, "", 400, "Bad request"
+	}
+
+	version, err := versions.GetVersionByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.FileVersion{}, "", 404, "Version doesn't exist."
+	} else if err != nil {
+		return types.FileVersion{}, "", 500, "Internal server error"
+	}
+
+	// Versions outside of the scope answer like missing ones
+	relPath, err := filepath.Rel(
+		filepath.Clean(config.Config.GetScopedFolder(account.Scope)),
+		filepath.Join(config.Config.Folder, version.Path),
+	)
+	if err != nil || relPath == ".." || strings.HasPrefix(filepath.ToSlash(relPath), "../") {
+		return types.FileVersion{}, "", 404, "Version doesn't exist."
+	}
+
+	itemPath := "/" + filepath.ToSlash(relPath)
+	if !acl.Allowed(account, permission, itemPath) {
+		return types.FileVersion{}, "", 403, "No permission!"
+	}
+
+	return version, itemPath, 0, ""
+}
@@ -8,23 +8,26 @@ import (
 	"strings"
 
 	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
 	"github.com/MertJSX/folder-host-go/utils/config"
 	"github.com/gofiber/fiber/v2"
 )
 
 func Image(c *fiber.Ctx) error {
-	if !c.Locals("account").(types.Account).Permissions.DownloadFiles {
-		return c.Status(403).JSON(
-			fiber.Map{"err": "No permission!"},
-		)
-	}
-
 	var path string = c.Params("path")
 	scope := c.Locals("account").(types.Account).Scope
 	path, err := url.QueryUnescape(path)
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"err": "Invalid path encoding"})
 	}
+
+	if !acl.Allowed(c.Locals("account").(types.Account), types.PermissionDownloadFiles, path) {
+		return c.Status(403).JSON(
+			fiber.Map{"err": "No permission!"},
+		)
+	}
+
 	path = fmt.Sprintf("%s%s", config.Config.GetScopedFolder(scope), path)
 	fileinfo, err := os.Stat(path)
 
@@ -69,5 +72,5 @@ func Image(c *fiber.Ctx) error {
 		)
 	}
 
-	return c.SendFile(path)
+	return utils.SendFile(c, path, fileinfo, utils.DispositionInline)
 }
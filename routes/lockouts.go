@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/bruteforce"
+	"github.com/gofiber/fiber/v2"
+)
+
+func Lockouts(c *fiber.Ctx) error {
+	if !c.Locals("account").(types.Account).Permissions.EditUsers {
+		return c.Status(403).JSON(
+			fiber.Map{"err": "No permission!"},
+		)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"lockouts": bruteforce.List()})
+}
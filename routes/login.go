@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/tokens"
+	"github.com/MertJSX/folder-host-go/middleware"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+func Login(c *fiber.Ctx) error {
+	var requestBody struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	if requestBody.Username == "" || requestBody.Password == "" {
+		return c.Status(400).JSON(fiber.Map{"err": "authorization required"})
+	}
+
+	account, ok := middleware.VerifyPasswordLogin(c, requestBody.Username, requestBody.Password)
+	if !ok || !middleware.VerifySecondFactor(c, &account, requestBody.Code) {
+		return nil
+	}
+
+	cache.SessionCache.Set(account.Username, account, 30*time.Minute)
+
+	session, err := issueSession(account, utils.GenerateUniqueString())
+
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "unknown error while getting token"})
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Login",
+		Description: fmt.Sprintf("%s logged in to his account.", account.Username),
+	})
+
+	session["res"] = "Verified!"
+	session["permissions"] = account.Permissions
+
+	return c.Status(200).JSON(session)
+}
+
+// issueSession creates a short lived access token and a refresh token belonging to familyID.
+func issueSession(account types.Account, familyID string) (fiber.Map, error) {
+	accessTokenLifetime := config.Config.GetAccessTokenLifetime()
+
+	accessToken, err := utils.CreateToken(account, config.Config.SecretJwtKey, accessTokenLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshTokenExpiresAt := time.Now().Add(config.Config.GetRefreshTokenLifetime())
+	refreshToken, err := tokens.CreateRefreshToken(account, familyID, refreshTokenExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return fiber.Map{
+		"token":                 accessToken,
+		"expiresIn":             int(accessTokenLifetime.Seconds()),
+		"refreshToken":          refreshToken,
+		"refreshTokenExpiresAt": refreshTokenExpiresAt.Unix(),
+	}, nil
+}
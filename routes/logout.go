@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/tokens"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Logout revokes the refresh token chain of the current login.
+// With "all" set, every session of the user is killed, including access tokens that haven't expired yet.
+func Logout(c *fiber.Ctx) error {
+	var requestBody struct {
+		RefreshToken string `json:"refreshToken"`
+		All          bool   `json:"all"`
+	}
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	if requestBody.RefreshToken == "" {
+		return c.Status(400).JSON(fiber.Map{"err": "Refresh token is missing."})
+	}
+
+	refreshToken, err := tokens.GetRefreshToken(requestBody.RefreshToken)
+
+	if err != nil {
+		// Unknown tokens can't be used anyway
+		return c.Status(200).JSON(fiber.Map{"response": "Logged out!"})
+	}
+
+	if err := tokens.RevokeRefreshTokenFamily(refreshToken.FamilyID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error."})
+	}
+
+	if requestBody.All && !refreshToken.Revoked {
+		account, err := users.GetUserByUsername(refreshToken.Username)
+		if err != nil || account.ID == nil {
+			return c.Status(404).JSON(fiber.Map{"err": "account not found"})
+		}
+
+		if err := revokeUserSessions(*account.ID, account.Username); err != nil {
+			return c.Status(500).JSON(fiber.Map{"err": "Unknown server error."})
+		}
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    refreshToken.Username,
+		Action:      "Logout",
+		Description: fmt.Sprintf("%s logged out.", refreshToken.Username),
+	})
+
+	return c.Status(200).JSON(fiber.Map{"response": "Logged out!"})
+}
+
+// revokeUserSessions makes every access and refresh token of the user invalid.
+func revokeUserSessions(id int, username string) error {
+	if err := users.IncrementTokenVersion(id); err != nil {
+		return err
+	}
+
+	if err := tokens.RevokeUserRefreshTokens(id, username); err != nil {
+		return err
+	}
+
+	cache.SessionCache.Delete(username)
+	return nil
+}
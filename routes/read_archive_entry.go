@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"errors"
+	"path"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReadArchiveEntry returns the content of a small file in an archive for the editor, like ReadFile.
+// It's always read-only.
+func ReadArchiveEntry(c *fiber.Ctx) error {
+	diskPath, format, status, message := getArchive(c.Locals("account").(types.Account), c.Query("path"), c.Query("format"))
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"err": message})
+	}
+
+	entry := utils.CleanEntryName(c.Query("entry"))
+	if entry == "" {
+		return c.Status(400).JSON(fiber.Map{"err": "Bad request!"})
+	}
+
+	content, err := utils.ReadArchiveEntry(format, diskPath, entry, 200*1024)
+	switch {
+	case errors.Is(err, utils.ErrEntryNotFound):
+		return c.Status(404).JSON(fiber.Map{"err": "The entry doesn't exist!"})
+	case errors.Is(err, utils.ErrEntryIsDirectory):
+		return c.Status(400).JSON(fiber.Map{"err": "Entry is directory!"})
+	case errors.Is(err, utils.ErrEntryTooLarge):
+		return c.Status(413).JSON(fiber.Map{"err": "File is too large!"})
+	case err != nil:
+		return c.Status(400).JSON(fiber.Map{"err": "The archive is damaged!"})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"data":            string(content),
+		"res":             "Successfully readed!",
+		"title":           path.Base(entry),
+		"writePermission": false,
+	})
+}
@@ -10,13 +10,14 @@ import (
 
 	"github.com/MertJSX/folder-host-go/types"
 	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
 	"github.com/MertJSX/folder-host-go/utils/cache"
 	"github.com/MertJSX/folder-host-go/utils/config"
 	"github.com/gofiber/fiber/v2"
 )
 
 func ReadDirectory(c *fiber.Ctx) error {
-	if !c.Locals("account").(types.Account).Permissions.ReadDirectories {
+	if !acl.Allowed(c.Locals("account").(types.Account), types.PermissionReadDirectories, c.Query("folder")) {
 		return c.Status(403).JSON(
 			fiber.Map{"err": "No permission!"},
 		)
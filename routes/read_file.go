@@ -6,6 +6,7 @@ import (
 
 	"github.com/MertJSX/folder-host-go/types"
 	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
 	"github.com/MertJSX/folder-host-go/utils/config"
 	"github.com/gofiber/fiber/v2"
 )
@@ -18,7 +19,7 @@ func ReadFile(c *fiber.Ctx) error {
 	var err error
 	config := &config.Config
 
-	if !c.Locals("account").(types.Account).Permissions.ReadFiles {
+	if !acl.Allowed(c.Locals("account").(types.Account), types.PermissionReadFiles, c.Query("filepath")) {
 		return c.Status(403).JSON(fiber.Map{"err": "No permission!"})
 	}
 
@@ -66,6 +67,6 @@ func ReadFile(c *fiber.Ctx) error {
 		"res":             "Successfully readed!",
 		"title":           fileName,
 		"lastModified":    lastModified,
-		"writePermission": c.Locals("account").(types.Account).Permissions.Change,
+		"writePermission": acl.Allowed(c.Locals("account").(types.Account), types.PermissionChange, path),
 	})
 }
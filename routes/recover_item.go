@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -11,10 +12,10 @@ import (
 	"github.com/MertJSX/folder-host-go/types"
 	"github.com/MertJSX/folder-host-go/utils"
 	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
 	"github.com/gofiber/fiber/v2"
 )
 
-// Missing: Check for space requirements
 func RecoverItem(c *fiber.Ctx) error {
 	if !c.Locals("account").(types.Account).Permissions.UseRecovery {
 		return c.Status(403).JSON(
@@ -52,16 +53,10 @@ func RecoverItem(c *fiber.Ctx) error {
 		})
 	}
 
-	if config.Config.StorageLimit != "UNLIMITED" {
-		remainingFreeSpace, err := utils.GetRemainingFolderSpace()
-
-		if err != nil {
-			return c.Status(520).JSON(fiber.Map{"err": "Internal server error!"})
-		}
-
-		if currentRecord.SizeBytes > remainingFreeSpace {
-			return c.Status(413).JSON(fiber.Map{"err": "This item exceeds the storage limit!"})
-		}
+	if err := utils.CheckQuota(account, currentRecord.SizeBytes); errors.Is(err, utils.ErrQuotaExceeded) {
+		return c.Status(413).JSON(fiber.Map{"err": "This item exceeds the storage limit!"})
+	} else if err != nil {
+		return c.Status(520).JSON(fiber.Map{"err": "Internal server error!"})
 	}
 
 	if err = os.Rename(currentRecord.BinLocation, currentRecord.OldLocation); err != nil {
@@ -70,6 +65,8 @@ func RecoverItem(c *fiber.Ctx) error {
 		})
 	}
 
+	sizeindex.Refresh(currentRecord.OldLocation)
+
 	if utils.IsNotExistingPath(currentRecord.OldLocation) {
 		return c.Status(500).JSON(fiber.Map{
 			"err": "Unknown error! Moved item is not in the right place.",
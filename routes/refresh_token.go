@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/tokens"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+func RefreshToken(c *fiber.Ctx) error {
+	var requestBody struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	if requestBody.RefreshToken == "" {
+		return c.Status(400).JSON(fiber.Map{"err": "Refresh token is missing."})
+	}
+
+	refreshToken, err := tokens.GetRefreshToken(requestBody.RefreshToken)
+
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"err": "invalid refresh token"})
+	}
+
+	// A revoked token is only presented again if it was stolen (or the client is broken),
+	// so the whole rotation chain is killed.
+	if refreshToken.Revoked {
+		return revokeReusedToken(c, refreshToken)
+	}
+
+	if refreshToken.ExpiresAt.Before(time.Now()) {
+		return c.Status(401).JSON(fiber.Map{"err": "refresh token expired"})
+	}
+
+	account, err := users.GetUserByUsername(refreshToken.Username)
+
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"err": "account not found"})
+	}
+
+	// A new user can get the name of a renamed one, the token must belong to the same account.
+	// Tokens from before the user_id column have 0 and need a new login.
+	if account.ID == nil || *account.ID != refreshToken.UserID || account.TokenVersion != refreshToken.TokenVersion {
+		return c.Status(401).JSON(fiber.Map{"err": "invalid refresh token"})
+	}
+
+	// The check above can pass for two requests at the same time, only the one that revokes the token wins
+	revoked, err := tokens.RevokeRefreshToken(refreshToken.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error."})
+	}
+	if !revoked {
+		return revokeReusedToken(c, refreshToken)
+	}
+
+	session, err := issueSession(account, refreshToken.FamilyID)
+
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "unknown error while getting token"})
+	}
+
+	return c.Status(200).JSON(session)
+}
+
+// revokeReusedToken kills the whole rotation chain of a token that was already used.
+func revokeReusedToken(c *fiber.Ctx, refreshToken types.RefreshToken) error {
+	if err := tokens.RevokeRefreshTokenFamily(refreshToken.FamilyID); err != nil {
+		log.Printf("Error while revoking refresh token family: %v\n", err)
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    refreshToken.Username,
+		Action:      "Token reuse",
+		Description: fmt.Sprintf("A revoked refresh token of %s was reused. All sessions of that login were revoked.", refreshToken.Username),
+	})
+
+	return c.Status(401).JSON(fiber.Map{"err": "invalid refresh token"})
+}
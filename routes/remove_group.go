@@ -0,0 +1,54 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/MertJSX/folder-host-go/database/groups"
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/gofiber/fiber/v2"
+)
+
+func RemoveGroup(c *fiber.Ctx) error {
+	if !c.Locals("account").(types.Account).Permissions.EditUsers {
+		return c.Status(403).JSON(
+			fiber.Map{"err": "No permission!"},
+		)
+	}
+
+	idToInt, err := strconv.Atoi(c.Params("id"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"err": "Bad request",
+		})
+	}
+
+	err = groups.RemoveGroup(idToInt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Group doesn't exist."},
+		)
+	} else if err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Internal server error"},
+		)
+	}
+
+	cache.SessionCache.Clear()
+
+	logs.CreateLog(types.AuditLog{
+		Username:    c.Locals("account").(types.Account).Username,
+		Action:      "Remove group",
+		Description: fmt.Sprintf("%s removed group %d", c.Locals("account").(types.Account).Username, idToInt),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"res": "Successfully removed!",
+	})
+}
@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/s3keys"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RemoveS3Key revokes an access key. Owners can revoke their keys, admins can revoke every key.
+func RemoveS3Key(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	idToInt, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"err": "Bad request",
+		})
+	}
+
+	key, err := s3keys.GetS3KeyByID(idToInt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(404).JSON(
+			fiber.Map{"err": "S3 key doesn't exist."},
+		)
+	} else if err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Internal server error"},
+		)
+	}
+
+	if key.Username != account.Username && !account.Permissions.EditUsers {
+		return c.Status(403).JSON(
+			fiber.Map{"err": "No permission!"},
+		)
+	}
+
+	if err := s3keys.DeleteS3Key(key.ID); err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Internal server error"},
+		)
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Remove S3 key",
+		Description: fmt.Sprintf("%s revoked S3 access key %s of %s", account.Username, key.AccessKeyID, key.Username),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"res": "Successfully removed!",
+	})
+}
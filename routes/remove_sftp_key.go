@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/sftpkeys"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RemoveSFTPKey removes a public key. Owners can remove their keys, admins can remove every key.
+func RemoveSFTPKey(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	idToInt, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"err": "Bad request",
+		})
+	}
+
+	key, err := sftpkeys.GetSFTPKeyByID(idToInt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(404).JSON(
+			fiber.Map{"err": "SFTP key doesn't exist."},
+		)
+	} else if err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Internal server error"},
+		)
+	}
+
+	if key.Username != account.Username && !account.Permissions.EditUsers {
+		return c.Status(403).JSON(
+			fiber.Map{"err": "No permission!"},
+		)
+	}
+
+	if err := sftpkeys.DeleteSFTPKey(key.ID); err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Internal server error"},
+		)
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Remove SFTP key",
+		Description: fmt.Sprintf("%s removed SFTP key %s of %s", account.Username, key.Fingerprint, key.Username),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"res": "Successfully removed!",
+	})
+}
@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/shares"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RemoveShareLink revokes a link. Owners can revoke their links, admins can revoke every link.
+func RemoveShareLink(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	idToInt, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"err": "Bad request",
+		})
+	}
+
+	link, err := shares.GetShareLinkByID(idToInt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(404).JSON(
+			fiber.Map{"err": "Share link doesn't exist."},
+		)
+	} else if err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Internal server error"},
+		)
+	}
+
+	if link.Username != account.Username && !account.Permissions.EditUsers {
+		return c.Status(403).JSON(
+			fiber.Map{"err": "No permission!"},
+		)
+	}
+
+	if err := shares.DeleteShareLink(link.ID); err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Internal server error"},
+		)
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Remove share link",
+		Description: fmt.Sprintf("%s revoked share link %d of %s for %s", account.Username, link.ID, link.Username, link.Path),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"res": "Successfully removed!",
+	})
+}
@@ -40,6 +40,7 @@ func RemoveUser(c *fiber.Ctx) error {
 
 	cache.SessionCache.Delete(username)
 
+	// Tokens are bound to the user ID, so they die with the user. Refresh tokens are removed with it.
 	err = users.RemoveUser(idToInt)
 
 	if err != nil {
@@ -8,7 +8,9 @@ import (
 	"github.com/MertJSX/folder-host-go/database/logs"
 	"github.com/MertJSX/folder-host-go/types"
 	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
 	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -19,18 +21,20 @@ func Rename(c *fiber.Ctx) error {
 	newFilepath := c.Query("newFilepath")
 	requestType := c.Query("type")
 
-	// Check permissions
-	if requestType == "rename" && !c.Locals("account").(types.Account).Permissions.Rename {
+	account := c.Locals("account").(types.Account)
+
+	// Check permissions, the item has to be allowed on both the old and the new path
+	if requestType == "rename" && (!acl.Allowed(account, types.PermissionRename, oldFilepath) || !acl.Allowed(account, types.PermissionRename, newFilepath)) {
 		return c.Status(403).JSON(
 			fiber.Map{"err": "No permission!"},
 		)
-	} else if requestType == "move" && !c.Locals("account").(types.Account).Permissions.Move {
+	} else if requestType == "move" && (!acl.Allowed(account, types.PermissionMove, oldFilepath) || !acl.Allowed(account, types.PermissionMove, newFilepath+"/"+filepath.Base(oldFilepath))) {
 		return c.Status(403).JSON(
 			fiber.Map{"err": "No permission!"},
 		)
 	}
 
-	scope := c.Locals("account").(types.Account).Scope
+	scope := account.Scope
 
 	if requestType != "move" && requestType != "rename" {
 		return c.Status(400).JSON(fiber.Map{"err": "Bad request!"})
@@ -69,6 +73,8 @@ func Rename(c *fiber.Ctx) error {
 		}
 
 		err := os.Rename(oldPathPlaceholder, newPathPlaceholder)
+		sizeindex.Refresh(oldPathPlaceholder)
+		sizeindex.Refresh(newPathPlaceholder)
 
 		if err != nil {
 			return c.Status(520).JSON(fiber.Map{"err": "Unknown error while moving item"})
@@ -87,6 +93,8 @@ func Rename(c *fiber.Ctx) error {
 		}
 
 		err := os.Rename(oldPathPlaceholder, newPathPlaceholder)
+		sizeindex.Refresh(oldPathPlaceholder)
+		sizeindex.Refresh(newPathPlaceholder)
 
 		if err != nil {
 			fmt.Printf("Error while renaming item: %s\n", err)
@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/twofactor"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ResetUserTwoFactor removes the second factor of a user who lost their device.
+// If 2FA is required for the user, they have to enroll again on the next login.
+func ResetUserTwoFactor(c *fiber.Ctx) error {
+	if !c.Locals("account").(types.Account).Permissions.EditUsers {
+		return c.Status(403).JSON(
+			fiber.Map{"err": "No permission!"},
+		)
+	}
+
+	idToInt, err := strconv.Atoi(c.Params("id"))
+
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"err": "Bad request",
+		})
+	}
+
+	if idToInt == 1 {
+		return c.Status(400).JSON(fiber.Map{
+			"err": "You can't update admin account from the web panel.",
+		})
+	}
+
+	username, err := users.GetUsername(idToInt)
+
+	if err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "User doesn't exist."},
+		)
+	}
+
+	if err := twofactor.Disable(idToInt, username); err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Unknown server error."},
+		)
+	}
+
+	if err := revokeUserSessions(idToInt, username); err != nil {
+		return c.Status(500).JSON(
+			fiber.Map{"err": "Two factor was reset, but old sessions couldn't be revoked."},
+		)
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    c.Locals("account").(types.Account).Username,
+		Action:      "Reset 2FA",
+		Description: fmt.Sprintf("%s reset two factor authentication of %s", c.Locals("account").(types.Account).Username, username),
+	})
+
+	return c.Status(200).JSON(
+		fiber.Map{"response": "Two factor authentication was reset!"},
+	)
+}
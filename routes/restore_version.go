@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/versions"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RestoreVersion puts an old version back in place of the file. The replaced content becomes a version too.
+func RestoreVersion(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	version, itemPath, status, message := getOwnVersion(account, c.Params("id"), types.PermissionChange)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"err": message})
+	}
+
+	diskPath := config.Config.GetScopedFolder(account.Scope) + itemPath
+
+	var currentSize int64
+	if stat, err := os.Stat(diskPath); err == nil && stat.IsDir() {
+		return c.Status(409).JSON(fiber.Map{"err": "A directory is at the path of the file!"})
+	} else if err == nil {
+		currentSize = stat.Size()
+	}
+
+	if err := utils.CheckQuota(account, version.SizeBytes-currentSize); errors.Is(err, utils.ErrQuotaExceeded) {
+		return c.Status(507).JSON(fiber.Map{"err": "Not enough space!"})
+	} else if err != nil {
+		log.Printf("Error: %v\n", err)
+		return c.Status(500).JSON(fiber.Map{"err": "Internal server error"})
+	}
+
+	if err := versions.Restore(account.Username, version, diskPath); err != nil {
+		log.Printf("Error: %v\n", err)
+		return c.Status(500).JSON(fiber.Map{"err": "Error restoring version"})
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Restore version",
+		Description: fmt.Sprintf("%s restored a %s file to version %d from %s.", account.Username, itemPath, version.ID, version.CreatedAt.Format("2006-01-02 15:04:05")),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"res": "Successfully restored!",
+	})
+}
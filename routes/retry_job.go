@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/jobs"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RetryJob queues a failed or cancelled job again. It starts from the beginning.
+func RetryJob(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	job, status, message := getOwnJob(account, c.Params("id"))
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"err": message})
+	}
+
+	job, err := jobs.Retry(job.ID)
+	if errors.Is(err, jobs.ErrNotRetryable) {
+		return c.Status(409).JSON(fiber.Map{"err": "Only failed and cancelled jobs can be retried!"})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Retry job",
+		Description: fmt.Sprintf("%s retried the %s job for %s.", account.Username, job.Type, job.Source),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"response": "Job queued!",
+		"job":      job,
+	})
+}
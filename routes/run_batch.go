@@ -0,0 +1,155 @@
+package routes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/jobs"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RunBatch deletes, moves, copies and downloads a multi-selection with one request. Mode is "best-effort" to
+// run every operation that can run, or "atomic" to run all of them or none, see jobs.RunBatch.
+// The done downloads are streamed as one archive from the returned downloadId.
+func RunBatch(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	var requestBody struct {
+		Mode       string                 `json:"mode"`
+		Format     string                 `json:"format"`
+		Operations []types.BatchOperation `json:"operations"`
+	}
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(400).JSON(
+			fiber.Map{"err": "Bad request! " + err.Error()},
+		)
+	}
+
+	if len(requestBody.Operations) == 0 {
+		return c.Status(400).JSON(fiber.Map{"err": "Operations are missing!"})
+	}
+	if len(requestBody.Operations) > jobs.MaxBatchOperations {
+		return c.Status(413).JSON(fiber.Map{"err": fmt.Sprintf("A batch can have up to %d operations!", jobs.MaxBatchOperations)})
+	}
+
+	if requestBody.Mode == "" {
+		requestBody.Mode = "best-effort"
+	}
+	if requestBody.Mode != "best-effort" && requestBody.Mode != "atomic" {
+		return c.Status(400).JSON(fiber.Map{"err": "Mode must be \"best-effort\" or \"atomic\""})
+	}
+
+	if requestBody.Format == "" {
+		requestBody.Format = utils.ArchiveFormatZip
+	}
+	if requestBody.Format != utils.ArchiveFormatZip && requestBody.Format != utils.ArchiveFormatTarGz {
+		return c.Status(400).JSON(fiber.Map{"err": "Format must be \"zip\" or \"tar.gz\""})
+	}
+
+	// Copies stop with the context of the request. fasthttp cancels it on shutdown, not when a client leaves
+	results := jobs.RunBatch(c.Context(), account, requestBody.Operations, requestBody.Mode == "atomic")
+
+	scopedFolder := config.Config.GetScopedFolder(account.Scope)
+	var (
+		changedFolders []string
+		sources        []types.ArchiveSource
+		failed         *types.BatchResult
+	)
+
+	for index, result := range results {
+		switch {
+		case result.Status == types.BatchFailed && failed == nil:
+			failed = &results[index]
+		case result.Status == types.BatchDone && result.Type == types.BatchDownload:
+			sources = append(sources, types.ArchiveSource{DiskPath: scopedFolder + result.Path, ScopePath: result.Path})
+		case result.Status == types.BatchDone || result.Status == types.BatchReverted:
+			if result.Type != types.JobCopy {
+				changedFolders = append(changedFolders, scopedFolder+utils.GetParentPath(result.Path))
+			}
+			if result.Type != types.JobDelete {
+				changedFolders = append(changedFolders, scopedFolder+result.Destination)
+			}
+		}
+	}
+
+	// One invalidation for all changed folders instead of one per item
+	if len(changedFolders) > 0 {
+		cache.DirectoryCache.DeleteDirCacheItemsByPaths(changedFolders)
+	}
+
+	if description := describeBatch(results); description != "" {
+		logs.CreateLog(types.AuditLog{
+			Username:    account.Username,
+			Action:      "Batch",
+			Description: fmt.Sprintf("%s %s", account.Username, description),
+		})
+	}
+
+	response := fiber.Map{"results": results}
+	if len(sources) > 0 {
+		response["downloadId"] = createArchiveLink(account, sources, requestBody.Format)
+	}
+
+	switch {
+	case failed == nil:
+		response["response"] = "Done!"
+		return c.Status(200).JSON(response)
+	case requestBody.Mode == "best-effort":
+		response["response"] = "Some operations failed!"
+		return c.Status(207).JSON(response)
+	case failed.Error == "Not enough space!":
+		response["err"] = failed.Error
+		return c.Status(507).JSON(response)
+	default:
+		response["err"] = fmt.Sprintf("%s: %s", failed.Path, failed.Error)
+		return c.Status(400).JSON(response)
+	}
+}
+
+// describeBatch lists the done deletes, moves and copies for the audit log. Downloads are logged by Download.
+func describeBatch(results []types.BatchResult) string {
+	var deleted, moved, copied []string
+	failed := 0
+
+	for _, result := range results {
+		switch {
+		case result.Status == types.BatchFailed:
+			failed++
+		case result.Status != types.BatchDone:
+		case result.Type == types.JobDelete:
+			deleted = append(deleted, result.Path)
+		case result.Type == types.JobMove:
+			moved = append(moved, fmt.Sprintf("%s -> %s", result.Path, result.Result))
+		case result.Type == types.JobCopy:
+			copied = append(copied, fmt.Sprintf("%s -> %s", result.Path, result.Result))
+		}
+	}
+
+	var parts []string
+	if len(deleted) > 0 && config.Config.RecoveryBin {
+		parts = append(parts, "moved "+strings.Join(deleted, ", ")+" to recovery_bin")
+	} else if len(deleted) > 0 {
+		parts = append(parts, "permanently deleted "+strings.Join(deleted, ", "))
+	}
+	if len(moved) > 0 {
+		parts = append(parts, "moved "+strings.Join(moved, ", "))
+	}
+	if len(copied) > 0 {
+		parts = append(parts, "copied "+strings.Join(copied, ", "))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	description := fmt.Sprintf("ran a batch of %d operations: %s", len(results), strings.Join(parts, "; "))
+	if failed > 0 {
+		description += fmt.Sprintf(" (%d failed)", failed)
+	}
+	return description
+}
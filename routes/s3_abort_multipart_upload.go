@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"log"
+
+	"github.com/MertJSX/folder-host-go/database/multipart"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/MertJSX/folder-host-go/utils/tus"
+	"github.com/gofiber/fiber/v2"
+)
+
+func S3AbortMultipartUpload(c *fiber.Ctx) error {
+	account, s3Err := s3Bucket(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if _, s3Err := getOwnMultipartUpload(c, account); s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	// Locks are only taken for existing uploads, the upload may be gone when it's our turn
+	unlock := tus.Lock(c.Query("uploadId"))
+	defer unlock()
+
+	upload, s3Err := getOwnMultipartUpload(c, account)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if err := multipart.DeleteMultipartUpload(upload.ID); err != nil {
+		log.Printf("Error: %v\n", err)
+		return s3.SendError(c, s3.ErrInternal)
+	}
+
+	s3.RemoveUpload(upload.ID)
+	return c.SendStatus(204)
+}
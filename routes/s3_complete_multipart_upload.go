@@ -0,0 +1,124 @@
+package routes
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/database/multipart"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+	"github.com/MertJSX/folder-host-go/utils/tus"
+	"github.com/MertJSX/folder-host-go/utils/versions"
+	"github.com/gofiber/fiber/v2"
+)
+
+// S3CompleteMultipartUpload joins the listed parts into a temp file next to the target and moves it into place.
+func S3CompleteMultipartUpload(c *fiber.Ctx) error {
+	account, s3Err := s3Bucket(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if _, s3Err := getOwnMultipartUpload(c, account); s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	// Locks are only taken for existing uploads, the upload may be gone when it's our turn
+	unlock := tus.Lock(c.Query("uploadId"))
+	defer unlock()
+
+	upload, s3Err := getOwnMultipartUpload(c, account)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if !acl.Allowed(account, types.PermissionUploadFiles, upload.Path) {
+		return s3.SendError(c, s3.ErrAccessDenied)
+	}
+
+	body, s3Err := readS3XMLBody(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	var request s3.CompleteMultipartUpload
+	if err := xml.Unmarshal(body, &request); err != nil || len(request.Parts) == 0 || len(request.Parts) > s3.MaxPartNumber {
+		return s3.SendError(c, s3.ErrMalformedXML)
+	}
+
+	diskPath := config.Config.GetScopedFolder(account.Scope) + upload.Path
+	if stat, err := os.Stat(diskPath); err == nil && stat.IsDir() {
+		return s3.SendError(c, s3.ErrKeyExists)
+	}
+
+	// The parts were reserved while they were uploaded, the space may be used up since then
+	remaining, s3Err := s3RemainingSpace(account, 0)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if s3.PendingSize(upload.ID, 0) > remaining {
+		return s3.SendError(c, s3.ErrInsufficientStorage)
+	}
+
+	if s3Err := s3CreateFolder(account, path.Dir(upload.Path)); s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	tempFile, err := utils.CreateTempFile(diskPath)
+	if err != nil {
+		log.Printf("Error: %v\n", err)
+		return s3.SendError(c, s3.ErrInternal)
+	}
+
+	s3Err = s3.AssembleParts(upload.ID, request.Parts, tempFile)
+	if err := tempFile.Close(); err != nil && s3Err == nil {
+		s3Err = s3.ErrInternal
+	}
+
+	if s3Err != nil {
+		os.Remove(tempFile.Name())
+		return s3.SendError(c, s3Err)
+	}
+
+	if _, err := versions.Replace(account.Username, tempFile.Name(), diskPath, utils.ConflictOverwrite); err != nil {
+		os.Remove(tempFile.Name())
+		log.Printf("Error: %v\n", err)
+		return s3.SendError(c, s3.ErrInternal)
+	}
+
+	if err := multipart.DeleteMultipartUpload(upload.ID); err == nil {
+		s3.RemoveUpload(upload.ID)
+	}
+
+	sizeindex.Refresh(diskPath)
+	logS3(account, "Upload", fmt.Sprintf("uploaded a %s file", upload.Path))
+
+	return s3.SendXML(c, 200, s3.CompleteMultipartUploadResult{
+		Location: c.BaseURL() + c.Path(),
+		Bucket:   account.Username,
+		Key:      strings.TrimPrefix(upload.Path, "/"),
+		ETag:     multipartETag(request.Parts),
+	})
+}
+
+// multipartETag is the MD5 of the part MD5s with the part count, what S3 returns for multipart objects.
+// AssembleParts verified the ETags already.
+func multipartETag(parts []s3.CompletedPart) string {
+	hash := md5.New()
+	for _, part := range parts {
+		sum, _ := hex.DecodeString(strings.Trim(part.ETag, `"`))
+		hash.Write(sum)
+	}
+	return fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(hash.Sum(nil)), len(parts))
+}
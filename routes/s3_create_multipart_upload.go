@@ -0,0 +1,71 @@
+package routes
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/multipart"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/MertJSX/folder-host-go/utils/tus"
+	"github.com/gofiber/fiber/v2"
+)
+
+func S3CreateMultipartUpload(c *fiber.Ctx) error {
+	account, s3Err := s3Bucket(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	itemPath, isFolder, s3Err := s3ObjectKey(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if isFolder {
+		return s3.SendError(c, s3.ErrInvalidArgument)
+	}
+
+	if !acl.Allowed(account, types.PermissionUploadFiles, itemPath) {
+		return s3.SendError(c, s3.ErrAccessDenied)
+	}
+
+	if stat, err := os.Stat(config.Config.GetScopedFolder(account.Scope) + itemPath); err == nil && stat.IsDir() {
+		return s3.SendError(c, s3.ErrKeyExists)
+	}
+
+	id, err := tus.NewID()
+	if err != nil {
+		log.Printf("Error: %v\n", err)
+		return s3.SendError(c, s3.ErrInternal)
+	}
+
+	upload := types.MultipartUpload{
+		ID:        id,
+		Username:  account.Username,
+		Path:      itemPath,
+		ExpiresAt: time.Now().Add(config.Config.GetUploadExpiration()),
+	}
+
+	// The record is saved before the folder, so the cleanup task never sees a folder without a record
+	if err := multipart.CreateMultipartUpload(upload); err != nil {
+		log.Printf("Error: %v\n", err)
+		return s3.SendError(c, s3.ErrInternal)
+	}
+
+	if err := os.MkdirAll(s3.UploadDir(id), 0700); err != nil {
+		multipart.DeleteMultipartUpload(id)
+		log.Printf("Error: %v\n", err)
+		return s3.SendError(c, s3.ErrInternal)
+	}
+
+	return s3.SendXML(c, 200, s3.InitiateMultipartUploadResult{
+		Bucket:   account.Username,
+		Key:      strings.TrimPrefix(itemPath, "/"),
+		UploadID: id,
+	})
+}
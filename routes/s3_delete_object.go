@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/recoverybin"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/gofiber/fiber/v2"
+)
+
+func S3DeleteObject(c *fiber.Ctx) error {
+	account, s3Err := s3Bucket(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	itemPath, isFolder, s3Err := s3ObjectKey(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if s3Err := deleteS3Object(account, itemPath, isFolder); s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	return c.SendStatus(204)
+}
+
+// deleteS3Object moves a file to the recovery bin like the delete route. Folder keys only remove empty
+// folders, S3 clients delete the objects in them one by one. Missing keys are deleted already.
+func deleteS3Object(account types.Account, itemPath string, isFolder bool) *s3.Error {
+	if itemPath == "/" || !acl.Allowed(account, types.PermissionDelete, itemPath) {
+		return s3.ErrAccessDenied
+	}
+
+	diskPath := config.Config.GetScopedFolder(account.Scope) + itemPath
+	stat, err := os.Stat(diskPath)
+	if err != nil || stat.IsDir() != isFolder {
+		return nil
+	}
+
+	if isFolder {
+		if entries, err := os.ReadDir(diskPath); err != nil || len(entries) > 0 {
+			return nil
+		}
+
+		if err := os.Remove(diskPath); err != nil {
+			log.Printf("Error: %v\n", err)
+			return s3.ErrInternal
+		}
+
+		logS3(account, "Delete", fmt.Sprintf("permanently deleted a %s directory", itemPath))
+		return nil
+	}
+
+	movedToBin, err := recoverybin.Delete(account.Username, diskPath)
+	if errors.Is(err, recoverybin.ErrBinFull) {
+		return s3.ErrRecoveryBinFull
+	}
+
+	if err != nil && !errors.Is(err, recoverybin.ErrRecordFailed) {
+		log.Printf("Error: %v\n", err)
+		return s3.ErrInternal
+	}
+
+	if movedToBin {
+		logS3(account, "Delete", fmt.Sprintf("moved %s to recovery_bin", itemPath))
+	} else {
+		logS3(account, "Delete", fmt.Sprintf("permanently deleted a %s file", itemPath))
+	}
+
+	if err != nil {
+		// The file is in the bin already, retrying would report a missing key
+		log.Printf("Error: %v\n", err)
+		return s3.ErrInternal
+	}
+
+	return nil
+}
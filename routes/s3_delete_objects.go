@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Delete and complete requests list at most 1000 keys or 10000 parts, 1 MB is plenty for their XML
+const s3MaxXMLBody = 1024 * 1024
+
+// S3DeleteObjects deletes the keys of a POST ?delete request, every key is checked on its own.
+func S3DeleteObjects(c *fiber.Ctx) error {
+	account, s3Err := s3Bucket(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if !c.Request().URI().QueryArgs().Has("delete") {
+		return s3.SendError(c, s3.ErrNotImplemented)
+	}
+
+	body, s3Err := readS3XMLBody(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	var request s3.Delete
+	if err := xml.Unmarshal(body, &request); err != nil || len(request.Objects) == 0 || len(request.Objects) > s3MaxKeys {
+		return s3.SendError(c, s3.ErrMalformedXML)
+	}
+
+	result := s3.DeleteResult{}
+	for _, object := range request.Objects {
+		s3Err := checkS3Key(object.Key)
+		if s3Err == nil {
+			s3Err = deleteS3Object(account, acl.NormalizePath(object.Key), strings.HasSuffix(object.Key, "/"))
+		}
+
+		if s3Err != nil {
+			result.Errors = append(result.Errors, s3.DeleteError{Key: object.Key, Code: s3Err.Code, Message: s3Err.Message})
+		} else if !request.Quiet {
+			result.Deleted = append(result.Deleted, s3.DeletedObject{Key: object.Key})
+		}
+	}
+
+	return s3.SendXML(c, 200, result)
+}
+
+// readS3XMLBody reads the small XML bodies of delete and complete requests. Signed payloads are verified,
+// the upload routes do that while streaming.
+func readS3XMLBody(c *fiber.Ctx) ([]byte, *s3.Error) {
+	var body bytes.Buffer
+	if _, s3Err := receiveS3Body(c, &body, s3MaxXMLBody); s3Err != nil {
+		if s3Err == s3.ErrInsufficientStorage {
+			return nil, s3.ErrEntityTooLarge
+		}
+		return nil, s3Err
+	}
+
+	return body.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/gofiber/fiber/v2"
+)
+
+// S3GetObject answers GetObject and HeadObject. Folders exist as keys ending with "/", like the empty
+// objects other clients create for them.
+func S3GetObject(c *fiber.Ctx) error {
+	account, s3Err := s3Bucket(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	itemPath, isFolder, s3Err := s3ObjectKey(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if !acl.Allowed(account, types.PermissionDownloadFiles, itemPath) {
+		return s3.SendError(c, s3.ErrAccessDenied)
+	}
+
+	diskPath := config.Config.GetScopedFolder(account.Scope) + itemPath
+	stat, err := os.Stat(diskPath)
+	if err != nil || stat.IsDir() != isFolder {
+		return s3.SendError(c, s3.ErrNoSuchKey)
+	}
+
+	if isFolder {
+		c.Set("Content-Type", "application/x-directory")
+		c.Set("Last-Modified", stat.ModTime().UTC().Format(http.TimeFormat))
+		return c.SendStatus(200)
+	}
+
+	if utils.IsDownloadStart(c) {
+		logS3(account, "Download", fmt.Sprintf("downloaded a %s file", itemPath))
+	}
+
+	return utils.SendFile(c, diskPath, stat, utils.DispositionInline)
+}
+
+// s3ObjectKey returns the path of the requested key in the scope and whether the key names a folder.
+func s3ObjectKey(c *fiber.Ctx) (string, bool, *s3.Error) {
+	key, err := url.PathUnescape(c.Params("*"))
+	if err != nil || key == "" {
+		return "", false, s3.ErrInvalidArgument
+	}
+
+	// Without strict routing the parameter loses the trailing slash of folder keys
+	if strings.HasSuffix(c.Path(), "/") && !strings.HasSuffix(key, "/") {
+		key += "/"
+	}
+
+	if s3Err := checkS3Key(key); s3Err != nil {
+		return "", false, s3Err
+	}
+
+	return acl.NormalizePath(key), strings.HasSuffix(key, "/"), nil
+}
+
+// checkS3Key refuses keys and prefixes that leave the scope, S3 itself would store "../" as part of the name.
+func checkS3Key(key string) *s3.Error {
+	if slices.Contains(strings.Split(key, "/"), "..") || !utils.IsSafePath(key) {
+		return s3.ErrAccessDenied
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/gofiber/fiber/v2"
+)
+
+func S3HeadBucket(c *fiber.Ctx) error {
+	if _, s3Err := s3Bucket(c); s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	return c.SendStatus(200)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"os"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/gofiber/fiber/v2"
+)
+
+// S3ListBuckets lists the only bucket of the user, its scope named like the user.
+func S3ListBuckets(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	creationDate := time.Now()
+	if stat, err := os.Stat(config.Config.GetScopedFolder(account.Scope)); err == nil {
+		creationDate = stat.ModTime()
+	}
+
+	return s3.SendXML(c, 200, s3.ListAllMyBucketsResult{
+		Owner: s3.Owner{ID: account.Username, DisplayName: account.Username},
+		Buckets: []s3.Bucket{
+			{Name: account.Username, CreationDate: s3.FormatTime(creationDate)},
+		},
+	})
+}
@@ -0,0 +1,232 @@
+package routes
+
+import (
+	"encoding/base64"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/gofiber/fiber/v2"
+)
+
+const s3MaxKeys = 1000
+
+type s3Entry struct {
+	key      string
+	isPrefix bool
+	info     os.FileInfo
+}
+
+// S3ListObjects answers ListObjects and ListObjectsV2 (list-type=2). Only "/" is supported as delimiter,
+// folders are the only prefixes that can be listed without walking the whole scope.
+func S3ListObjects(c *fiber.Ctx) error {
+	account, s3Err := s3Bucket(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if c.Request().URI().QueryArgs().Has("location") {
+		return s3.SendXML(c, 200, s3.LocationConstraint{})
+	}
+
+	var (
+		isV2      bool   = c.Query("list-type") == "2"
+		prefix    string = c.Query("prefix")
+		delimiter string = c.Query("delimiter")
+		encodeURL bool   = c.Query("encoding-type") == "url"
+		maxKeys   int    = s3MaxKeys
+		marker    string
+	)
+
+	if value := c.Query("max-keys"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return s3.SendError(c, s3.ErrInvalidArgument)
+		}
+		maxKeys = min(parsed, s3MaxKeys)
+	}
+
+	if delimiter != "" && delimiter != "/" {
+		return s3.SendError(c, s3.ErrNotImplemented)
+	}
+
+	if s3Err := checkS3Key(prefix); s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if isV2 {
+		marker = c.Query("start-after")
+		if token := c.Query("continuation-token"); token != "" {
+			decoded, err := base64.RawURLEncoding.DecodeString(token)
+			if err != nil {
+				return s3.SendError(c, s3.ErrInvalidArgument)
+			}
+			marker = string(decoded)
+		}
+	} else {
+		marker = c.Query("marker")
+	}
+
+	entries, s3Err := listS3Entries(account, prefix, delimiter)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	start := sort.Search(len(entries), func(i int) bool {
+		return entries[i].key > marker
+	})
+	entries = entries[start:]
+
+	isTruncated := len(entries) > maxKeys
+	if isTruncated {
+		entries = entries[:maxKeys]
+	}
+
+	encode := func(value string) string {
+		if encodeURL {
+			return url.QueryEscape(value)
+		}
+		return value
+	}
+
+	result := s3.ListBucketResult{
+		Name:        account.Username,
+		Prefix:      encode(prefix),
+		Delimiter:   encode(delimiter),
+		MaxKeys:     maxKeys,
+		IsTruncated: isTruncated,
+	}
+	if encodeURL {
+		result.EncodingType = "url"
+	}
+
+	for _, entry := range entries {
+		if entry.isPrefix {
+			result.CommonPrefixes = append(result.CommonPrefixes, s3.CommonPrefix{Prefix: encode(entry.key)})
+			continue
+		}
+
+		result.Contents = append(result.Contents, s3.Object{
+			Key:          encode(entry.key),
+			LastModified: s3.FormatTime(entry.info.ModTime()),
+			ETag:         utils.ETag(entry.info),
+			Size:         entry.info.Size(),
+			StorageClass: "STANDARD",
+		})
+	}
+
+	var lastKey string
+	if len(entries) > 0 {
+		lastKey = entries[len(entries)-1].key
+	}
+
+	if isV2 {
+		keyCount := len(entries)
+		result.KeyCount = &keyCount
+		result.StartAfter = encode(c.Query("start-after"))
+		result.ContinuationToken = c.Query("continuation-token")
+		if isTruncated {
+			result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(lastKey))
+		}
+	} else {
+		encodedMarker := encode(marker)
+		result.Marker = &encodedMarker
+		if isTruncated {
+			result.NextMarker = encode(lastKey)
+		}
+	}
+
+	return s3.SendXML(c, 200, result)
+}
+
+// listS3Entries returns the keys starting with prefix in order. Items the account can't see are left out
+// like in the explorer, unfinished uploads too.
+func listS3Entries(account types.Account, prefix string, delimiter string) ([]s3Entry, *s3.Error) {
+	// Keys are listed from the deepest folder the prefix names, "docs/re" is listed from "docs/"
+	folderKey := prefix[:strings.LastIndex(prefix, "/")+1]
+	if !acl.Allowed(account, types.PermissionReadDirectories, "/"+folderKey) {
+		return nil, s3.ErrAccessDenied
+	}
+
+	folder := config.Config.GetScopedFolder(account.Scope) + "/" + folderKey
+	var entries []s3Entry
+
+	if delimiter == "/" {
+		dirEntries, err := os.ReadDir(folder)
+		if err != nil {
+			return entries, nil
+		}
+
+		for _, dirEntry := range dirEntries {
+			key := folderKey + dirEntry.Name()
+			if !strings.HasPrefix(key, prefix) || utils.IsTempFile(dirEntry.Name()) ||
+				!acl.Allowed(account, types.PermissionReadDirectories, "/"+key) {
+				continue
+			}
+
+			if dirEntry.IsDir() {
+				entries = append(entries, s3Entry{key: key + "/", isPrefix: true})
+			} else if dirEntry.Type().IsRegular() {
+				if info, err := dirEntry.Info(); err == nil {
+					entries = append(entries, s3Entry{key: key, info: info})
+				}
+			}
+		}
+	} else {
+		filepath.WalkDir(folder, func(path string, dirEntry fs.DirEntry, err error) error {
+			if err != nil || path == folder {
+				return nil
+			}
+
+			relative, err := filepath.Rel(folder, path)
+			if err != nil {
+				return nil
+			}
+			key := folderKey + filepath.ToSlash(relative)
+
+			if !acl.Allowed(account, types.PermissionReadDirectories, "/"+key) {
+				if dirEntry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if !dirEntry.Type().IsRegular() || !strings.HasPrefix(key, prefix) || utils.IsTempFile(dirEntry.Name()) {
+				return nil
+			}
+
+			if info, err := dirEntry.Info(); err == nil {
+				entries = append(entries, s3Entry{key: key, info: info})
+			}
+			return nil
+		})
+	}
+
+	// S3 orders keys by their bytes, "a-b" comes before "a/b" although the walk visits "a/" first
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	return entries, nil
+}
+
+// s3Bucket returns the account if the bucket of the request is its own. Other buckets don't exist for it.
+func s3Bucket(c *fiber.Ctx) (types.Account, *s3.Error) {
+	account := c.Locals("account").(types.Account)
+
+	bucket, err := url.PathUnescape(c.Params("bucket"))
+	if err != nil || bucket != account.Username {
+		return types.Account{}, s3.ErrNoSuchBucket
+	}
+
+	return account, nil
+}
@@ -0,0 +1,229 @@
+package routes
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"syscall"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/uploads"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+	"github.com/MertJSX/folder-host-go/utils/versions"
+	"github.com/gofiber/fiber/v2"
+)
+
+// S3PutObject writes the body to a temp file and moves it into place like the other uploads.
+// Keys ending with "/" create folders.
+func S3PutObject(c *fiber.Ctx) error {
+	account, s3Err := s3Bucket(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	itemPath, isFolder, s3Err := s3ObjectKey(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if c.Get("X-Amz-Copy-Source") != "" {
+		return s3.SendError(c, s3.ErrNotImplemented)
+	}
+
+	diskPath := config.Config.GetScopedFolder(account.Scope) + itemPath
+
+	if isFolder {
+		if !acl.Allowed(account, types.PermissionCreate, itemPath) {
+			return s3.SendError(c, s3.ErrAccessDenied)
+		}
+
+		if s3Err := s3CreateFolder(account, itemPath); s3Err != nil {
+			return s3.SendError(c, s3Err)
+		}
+
+		logS3(account, "Create folder", fmt.Sprintf("created a %s folder", itemPath))
+		c.Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+		return c.SendStatus(200)
+	}
+
+	if !acl.Allowed(account, types.PermissionUploadFiles, itemPath) {
+		return s3.SendError(c, s3.ErrAccessDenied)
+	}
+
+	if stat, err := os.Stat(diskPath); err == nil && stat.IsDir() {
+		return s3.SendError(c, s3.ErrKeyExists)
+	}
+
+	remaining, s3Err := s3RemainingSpace(account, 0)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	if s3Err := s3CreateFolder(account, path.Dir(itemPath)); s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	tempFile, err := utils.CreateTempFile(diskPath)
+	if err != nil {
+		log.Printf("Error: %v\n", err)
+		return s3.SendError(c, s3.ErrInternal)
+	}
+
+	md5sum, s3Err := receiveS3Body(c, tempFile, remaining)
+	if err := tempFile.Close(); err != nil && s3Err == nil {
+		s3Err = s3.ErrInternal
+	}
+
+	if s3Err != nil {
+		os.Remove(tempFile.Name())
+		return s3.SendError(c, s3Err)
+	}
+
+	if _, err := versions.Replace(account.Username, tempFile.Name(), diskPath, utils.ConflictOverwrite); err != nil {
+		os.Remove(tempFile.Name())
+		log.Printf("Error: %v\n", err)
+		return s3.SendError(c, s3.ErrInternal)
+	}
+
+	sizeindex.Refresh(diskPath)
+	logS3(account, "Upload", fmt.Sprintf("uploaded a %s file", itemPath))
+
+	c.Set("ETag", `"`+md5sum+`"`)
+	return c.SendStatus(200)
+}
+
+// receiveS3Body copies the body to w and returns its MD5 in hex. Bodies longer than limit are cut off with
+// InsufficientStorage, the payload hash and Content-MD5 are checked when the client sent them.
+func receiveS3Body(c *fiber.Ctx, w io.Writer, limit int64) (string, *s3.Error) {
+	signature := c.Locals("s3Signature").(*s3.Signature)
+
+	var body io.Reader = c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+
+	expectedLength := int64(c.Request().Header.ContentLength())
+	if signature.IsStreaming() {
+		body = s3.NewChunkReader(body, signature, c.Locals("s3Secret").(string))
+
+		expectedLength = -1
+		if decodedLength := c.Get("X-Amz-Decoded-Content-Length"); decodedLength != "" {
+			parsed, err := strconv.ParseInt(decodedLength, 10, 64)
+			if err != nil || parsed < 0 {
+				return "", s3.ErrInvalidArgument
+			}
+			expectedLength = parsed
+		}
+	}
+
+	if expectedLength > limit {
+		return "", s3.ErrInsufficientStorage
+	}
+
+	// One more byte than allowed tells a body at the limit from a longer one
+	readLimit := limit
+	if readLimit < math.MaxInt64 {
+		readLimit++
+	}
+
+	md5Hash := md5.New()
+	sha256Hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(w, md5Hash, sha256Hash), io.LimitReader(body, readLimit))
+	if errors.Is(err, s3.ErrChunkSignature) {
+		return "", s3.ErrSignatureDoesNotMatch
+	} else if err != nil {
+		return "", s3.ErrIncompleteBody
+	}
+
+	if written > limit {
+		return "", s3.ErrInsufficientStorage
+	}
+
+	if expectedLength >= 0 && written != expectedLength {
+		return "", s3.ErrIncompleteBody
+	}
+
+	if !signature.IsStreaming() && signature.PayloadHash != s3.UnsignedPayload &&
+		signature.PayloadHash != hex.EncodeToString(sha256Hash.Sum(nil)) {
+		return "", s3.ErrContentSHA256Mismatch
+	}
+
+	if contentMD5 := c.Get("Content-MD5"); contentMD5 != "" {
+		expected, err := base64.StdEncoding.DecodeString(contentMD5)
+		if err != nil || !bytes.Equal(expected, md5Hash.Sum(nil)) {
+			return "", s3.ErrBadDigest
+		}
+	}
+
+	return hex.EncodeToString(md5Hash.Sum(nil)), nil
+}
+
+// s3RemainingSpace returns how many bytes the account can write. Unfinished tus uploads and the reserved bytes,
+// the parts of a multipart upload, are taken off like the tus routes do.
+func s3RemainingSpace(account types.Account, reserved int64) (int64, *s3.Error) {
+	remaining, err := utils.GetRemainingSpace(account)
+	if err != nil {
+		log.Printf("Error: %v\n", err)
+		return 0, s3.ErrInternal
+	}
+
+	pendingSize, err := uploads.GetPendingSize(account.Username)
+	if err != nil {
+		log.Printf("Error: %v\n", err)
+		return 0, s3.ErrInternal
+	}
+
+	return max(remaining-pendingSize-reserved, 0), nil
+}
+
+// s3CreateFolder creates the folder with its parents. S3 has no folders, clients write "a/b/c.txt" directly,
+// so missing folders are created with the create permission of the folder.
+func s3CreateFolder(account types.Account, itemPath string) *s3.Error {
+	diskPath := config.Config.GetScopedFolder(account.Scope) + itemPath
+
+	stat, err := os.Stat(diskPath)
+	if err == nil {
+		if !stat.IsDir() {
+			return s3.ErrKeyExists
+		}
+		return nil
+	}
+
+	if !acl.Allowed(account, types.PermissionCreate, itemPath) {
+		return s3.ErrAccessDenied
+	}
+
+	if err := os.MkdirAll(diskPath, 0777); err != nil {
+		if errors.Is(err, os.ErrExist) || errors.Is(err, syscall.ENOTDIR) {
+			return s3.ErrKeyExists
+		}
+		log.Printf("Error: %v\n", err)
+		return s3.ErrInternal
+	}
+
+	sizeindex.Refresh(diskPath)
+	return nil
+}
+
+func logS3(account types.Account, action string, description string) {
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      action,
+		Description: fmt.Sprintf("%s %s over S3.", account.Username, description),
+	})
+}
@@ -0,0 +1,103 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/multipart"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/gofiber/fiber/v2"
+)
+
+// S3UploadPart stores a part next to the others. Clients upload parts in parallel, each one goes through
+// its own temp file, so a part is replaced as a whole when it's sent again.
+func S3UploadPart(c *fiber.Ctx) error {
+	account, s3Err := s3Bucket(c)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	upload, s3Err := getOwnMultipartUpload(c, account)
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	partNumber, err := strconv.Atoi(c.Query("partNumber"))
+	if err != nil || partNumber < 1 || partNumber > s3.MaxPartNumber {
+		return s3.SendError(c, s3.ErrInvalidArgument)
+	}
+
+	if c.Get("X-Amz-Copy-Source") != "" {
+		return s3.SendError(c, s3.ErrNotImplemented)
+	}
+
+	if !acl.Allowed(account, types.PermissionUploadFiles, upload.Path) {
+		return s3.SendError(c, s3.ErrAccessDenied)
+	}
+
+	remaining, s3Err := s3RemainingSpace(account, s3.PendingSize(upload.ID, partNumber))
+	if s3Err != nil {
+		return s3.SendError(c, s3Err)
+	}
+
+	partPath := s3.PartPath(upload.ID, partNumber)
+	tempFile, err := utils.CreateTempFile(partPath)
+	if err != nil {
+		// The upload was completed or aborted in the meantime
+		return s3.SendError(c, s3.ErrNoSuchUpload)
+	}
+
+	md5sum, s3Err := receiveS3Body(c, tempFile, remaining)
+	if err := tempFile.Close(); err != nil && s3Err == nil {
+		s3Err = s3.ErrInternal
+	}
+
+	if s3Err != nil {
+		os.Remove(tempFile.Name())
+		return s3.SendError(c, s3Err)
+	}
+
+	if err := os.Rename(tempFile.Name(), partPath); err != nil {
+		os.Remove(tempFile.Name())
+		return s3.SendError(c, s3.ErrNoSuchUpload)
+	}
+
+	// Uploads expire when they get no data, like tus uploads
+	if err := multipart.UpdateMultipartUploadExpiry(upload.ID, time.Now().Add(config.Config.GetUploadExpiration())); err != nil {
+		log.Printf("Error: %v\n", err)
+	}
+
+	c.Set("ETag", `"`+md5sum+`"`)
+	return c.SendStatus(200)
+}
+
+// getOwnMultipartUpload returns the upload of the uploadId query. Uploads of other users and other keys
+// don't exist for the request.
+func getOwnMultipartUpload(c *fiber.Ctx, account types.Account) (types.MultipartUpload, *s3.Error) {
+	itemPath, _, s3Err := s3ObjectKey(c)
+	if s3Err != nil {
+		return types.MultipartUpload{}, s3Err
+	}
+
+	upload, err := multipart.GetMultipartUpload(c.Query("uploadId"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.MultipartUpload{}, s3.ErrNoSuchUpload
+	} else if err != nil {
+		log.Printf("Error: %v\n", err)
+		return types.MultipartUpload{}, s3.ErrInternal
+	}
+
+	if upload.Username != account.Username || upload.Path != itemPath || upload.ExpiresAt.Before(time.Now()) {
+		return types.MultipartUpload{}, s3.ErrNoSuchUpload
+	}
+
+	return upload, nil
+}
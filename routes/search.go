@@ -0,0 +1,170 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/search"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+	"github.com/gofiber/fiber/v2"
+)
+
+const maxSearchLimit = 200
+
+// Search finds items below ?path in the index. Results the account can't see are left out, items found by
+// their text also need the read_files permission.
+func Search(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	folder := acl.NormalizePath(c.Query("path"))
+	if !utils.IsSafePath(folder) {
+		return c.Status(400).JSON(fiber.Map{"err": "Invalid path!"})
+	}
+
+	if !acl.Allowed(account, types.PermissionReadDirectories, folder) {
+		return c.Status(403).JSON(fiber.Map{"err": "No permission!"})
+	}
+
+	if !search.Ready() {
+		return c.Status(503).JSON(fiber.Map{"err": "Search index is not ready yet!"})
+	}
+
+	query, err := parseSearchQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"err": err.Error()})
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+	if page < 1 || limit < 1 || limit > maxSearchLimit {
+		return c.Status(400).JSON(fiber.Map{
+			"err": fmt.Sprintf("Page must be at least 1 and limit between 1 and %d!", maxSearchLimit),
+		})
+	}
+
+	if folder == "/" {
+		folder = ""
+	}
+	dirPath := config.Config.GetScopedFolder(account.Scope) + folder
+
+	results, total, err := search.Search(dirPath, query, func(result search.Result) bool {
+		itemPath := folder + result.Path
+		if result.ContentMatched && !acl.Allowed(account, types.PermissionReadFiles, itemPath) {
+			return false
+		}
+		return acl.Allowed(account, types.PermissionReadDirectories, itemPath)
+	}, (page-1)*limit, limit)
+
+	if errors.Is(err, search.ErrNotFound) {
+		return c.Status(400).JSON(fiber.Map{"err": "Wrong dirpath!"})
+	} else if errors.Is(err, search.ErrInvalidQuery) {
+		return c.Status(400).JSON(fiber.Map{"err": err.Error()})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown error!"})
+	}
+
+	items := make([]types.DirectoryItem, 0, len(results))
+	for i, result := range results {
+		itemPath := folder + result.Path
+
+		size := result.Size
+		if result.IsDirectory {
+			size, _ = sizeindex.Size(dirPath + result.Path)
+		}
+
+		parentPath := path.Dir(itemPath)
+		if parentPath != "/" {
+			parentPath += "/"
+		}
+
+		items = append(items, types.DirectoryItem{
+			Id:           i,
+			Name:         path.Base(itemPath),
+			ParentPath:   parentPath,
+			Path:         itemPath,
+			IsDirectory:  result.IsDirectory,
+			DateModified: result.ModTime,
+			Size:         utils.ConvertBytesToString(size),
+			SizeBytes:    size,
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"items":  items,
+		"total":  total,
+		"isLast": page*limit >= total,
+	})
+}
+
+func parseSearchQuery(c *fiber.Ctx) (search.Query, error) {
+	query := search.Query{
+		Pattern: c.Query("q"),
+		Mode:    c.Query("mode"),
+		Content: c.QueryBool("content"),
+		Type:    c.Query("type"),
+	}
+
+	var err error
+	if query.MinSize, err = parseSearchSize(c.Query("minSize")); err != nil {
+		return query, err
+	}
+	if query.MaxSize, err = parseSearchSize(c.Query("maxSize")); err != nil {
+		return query, err
+	}
+	if query.ModifiedAfter, err = parseSearchDate(c.Query("modifiedAfter")); err != nil {
+		return query, err
+	}
+	if query.ModifiedBefore, err = parseSearchDate(c.Query("modifiedBefore")); err != nil {
+		return query, err
+	}
+
+	if query.Content && !config.Config.Search.IndexContent {
+		return query, errors.New("Content search is disabled!")
+	}
+
+	return query, nil
+}
+
+// parseSearchSize accepts bytes or sizes like "10 MB".
+func parseSearchSize(size string) (int64, error) {
+	if size == "" {
+		return 0, nil
+	}
+
+	if bytes, err := strconv.ParseInt(size, 10, 64); err == nil && bytes >= 0 {
+		return bytes, nil
+	}
+
+	if len(strings.Split(size, " ")) == 2 {
+		if bytes := utils.ConvertStringToBytes(size); bytes > 0 {
+			return bytes, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Invalid size %q!", size)
+}
+
+// parseSearchDate accepts RFC 3339 dates like "2025-06-01T00:00:00Z" or days like "2025-06-01".
+func parseSearchDate(date string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, date); err == nil {
+		return parsed, nil
+	}
+
+	if parsed, err := time.Parse(time.DateOnly, date); err == nil {
+		return parsed, nil
+	}
+
+	return time.Time{}, fmt.Errorf("Invalid date %q!", date)
+}
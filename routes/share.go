@@ -0,0 +1,190 @@
+package routes
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/shares"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/bruteforce"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/password"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Share serves public /s/:token links without authentication. Shared files are downloaded directly,
+// shared folders are listed and their files are downloaded with the path query. File drops only return
+// their name, they are uploaded to with ShareUpload.
+func Share(c *fiber.Ctx) error {
+	link, owner, ok := openShareLink(c)
+	if !ok {
+		return nil
+	}
+
+	if link.Mode == types.ShareModeUpload {
+		return c.Status(200).JSON(fiber.Map{
+			"mode": link.Mode,
+			"name": path.Base(link.Path),
+		})
+	}
+
+	// Visitors get the permissions the owner has now, not when the link was created
+	if !acl.Allowed(owner, types.PermissionDownloadFiles, link.Path) {
+		return c.Status(403).JSON(fiber.Map{"err": "No permission!"})
+	}
+
+	subPath := "/"
+	if link.IsDirectory {
+		subPath = acl.NormalizePath(c.Query("path"))
+	}
+	itemPath := path.Join(link.Path, subPath)
+	fullPath := config.Config.GetScopedFolder(owner.Scope) + itemPath
+
+	fileinfo, err := os.Stat(fullPath)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"err": "Wrong path!"})
+	}
+
+	// Denied items look like missing ones, visitors shouldn't learn what the owner can't share
+	if fileinfo.IsDir() && !acl.Allowed(owner, types.PermissionReadDirectories, itemPath) {
+		return c.Status(404).JSON(fiber.Map{"err": "Wrong path!"})
+	}
+	if !fileinfo.IsDir() && !acl.Allowed(owner, types.PermissionDownloadFiles, itemPath) {
+		return c.Status(404).JSON(fiber.Map{"err": "Wrong path!"})
+	}
+
+	if fileinfo.IsDir() {
+		logs.CreateLog(types.AuditLog{
+			Username:    owner.Username,
+			Action:      "Share link",
+			Description: fmt.Sprintf("%s listed %s via share link %d", c.IP(), itemPath, link.ID),
+		})
+
+		return c.Status(200).JSON(fiber.Map{
+			"mode":  link.Mode,
+			"name":  path.Base(link.Path),
+			"path":  subPath,
+			"items": shareDirectoryItems(owner, itemPath, fullPath, subPath),
+		})
+	}
+
+	// Resumed downloads come back with ranges, only new downloads are counted
+	if utils.IsDownloadStart(c) {
+		if used, err := shares.UseShareLink(link.ID); err != nil {
+			return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+		} else if !used {
+			return c.Status(410).JSON(fiber.Map{"err": "Share link expired"})
+		}
+
+		logs.CreateLog(types.AuditLog{
+			Username:    owner.Username,
+			Action:      "Share link",
+			Description: fmt.Sprintf("%s downloaded %s via share link %d", c.IP(), itemPath, link.ID),
+		})
+	}
+
+	disposition := utils.DispositionAttachment
+	if c.QueryBool("inline") {
+		disposition = utils.DispositionInline
+	}
+
+	return utils.SendFile(c, fullPath, fileinfo, disposition)
+}
+
+// openShareLink finds the link in the token param, checks its password and loads its owner.
+// The password is sent in the X-Share-Password header or the password query.
+// It writes the error response itself, the caller must stop if it returns false.
+func openShareLink(c *fiber.Ctx) (types.ShareLink, types.Account, bool) {
+	link, err := shares.GetShareLinkByToken(c.Params("token"))
+	if err == sql.ErrNoRows {
+		c.Status(404).JSON(fiber.Map{"err": "Share link not found"})
+		return types.ShareLink{}, types.Account{}, false
+	} else if err != nil {
+		c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+		return types.ShareLink{}, types.Account{}, false
+	}
+
+	// Used up links still let their last downloads resume until the expiry date
+	if link.Expired(time.Now()) && (utils.IsDownloadStart(c) || (link.ExpiresAt != nil && !time.Now().Before(*link.ExpiresAt))) {
+		c.Status(410).JSON(fiber.Map{"err": "Share link expired"})
+		return types.ShareLink{}, types.Account{}, false
+	}
+
+	if link.HasPassword {
+		// Only the IP is limited, a locked link would let anyone block it for everyone
+		if bruteforce.Reject(c, "") {
+			return types.ShareLink{}, types.Account{}, false
+		}
+
+		sharePassword := c.Get("X-Share-Password", c.Query("password"))
+		if ok, _ := password.Verify(sharePassword, link.PasswordHash); !ok {
+			bruteforce.RegisterFailure(c.IP(), "", false, fmt.Sprintf("wrong password for share link %d", link.ID))
+			logs.CreateLog(types.AuditLog{
+				Username:    link.Username,
+				Action:      "Share link",
+				Description: fmt.Sprintf("%s sent a wrong password for share link %d", c.IP(), link.ID),
+			})
+			c.Status(401).JSON(fiber.Map{"err": "password required"})
+			return types.ShareLink{}, types.Account{}, false
+		}
+	}
+
+	owner, err := users.GetUserByUsername(link.Username)
+	if err != nil {
+		c.Status(404).JSON(fiber.Map{"err": "Share link not found"})
+		return types.ShareLink{}, types.Account{}, false
+	}
+
+	return link, owner, true
+}
+
+// shareDirectoryItems lists the folder at itemPath, leaving out folders the owner can't read and files the owner
+// can't download.
+func shareDirectoryItems(owner types.Account, itemPath string, fullPath string, subPath string) []types.DirectoryItem {
+	items := []types.DirectoryItem{}
+
+	entries, err := os.ReadDir(fullPath)
+	if err != nil {
+		return items
+	}
+
+	for _, entry := range entries {
+		permission := types.PermissionDownloadFiles
+		if entry.IsDir() {
+			permission = types.PermissionReadDirectories
+		}
+		if !acl.Allowed(owner, permission, path.Join(itemPath, entry.Name())) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		var sizeBytes int64
+		if !entry.IsDir() {
+			sizeBytes = info.Size()
+		}
+
+		items = append(items, types.DirectoryItem{
+			Id:           len(items),
+			Name:         entry.Name(),
+			ParentPath:   subPath,
+			Path:         path.Join(subPath, entry.Name()),
+			IsDirectory:  entry.IsDir(),
+			DateModified: info.ModTime(),
+			Size:         utils.ConvertBytesToString(sizeBytes),
+			SizeBytes:    sizeBytes,
+		})
+	}
+
+	return items
+}
@@ -0,0 +1,98 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/shares"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ShareUpload receives a file in the "file" form field for a file drop. Existing files are never replaced,
+// uploads with the same name are renamed like copies.
+func ShareUpload(c *fiber.Ctx) error {
+	link, owner, ok := openShareLink(c)
+	if !ok {
+		return nil
+	}
+
+	if link.Mode != types.ShareModeUpload {
+		return c.Status(405).JSON(fiber.Map{"err": "This link doesn't accept uploads"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"err": "Missing file"})
+	}
+
+	fileName := filepath.Base(strings.ReplaceAll(fileHeader.Filename, "\\", "/"))
+	if fileName == "." || fileName == "/" || fileName == ".." {
+		return c.Status(400).JSON(fiber.Map{"err": "Invalid file name"})
+	}
+
+	uploadPath := path.Join(link.Path, fileName)
+	if !acl.Allowed(owner, types.PermissionUploadFiles, uploadPath) {
+		return c.Status(403).JSON(fiber.Map{"err": "No permission!"})
+	}
+
+	// Dropped files count to the owner's quota
+	if err := utils.CheckQuota(owner, fileHeader.Size); errors.Is(err, utils.ErrQuotaExceeded) {
+		return c.Status(507).JSON(fiber.Map{"err": "Not enough space!"})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't check storage space"})
+	}
+
+	if used, err := shares.UseShareLink(link.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	} else if !used {
+		return c.Status(410).JSON(fiber.Map{"err": "Share link expired"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't open file"})
+	}
+	defer file.Close()
+
+	finalPath := filepath.Join(config.Config.GetScopedFolder(owner.Scope), uploadPath)
+	tempFile, err := utils.CreateTempFile(finalPath)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't create file"})
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = io.Copy(tempFile, file)
+	tempFile.Close()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't save file"})
+	}
+
+	placedPath, err := utils.PlaceFile(tempFile.Name(), finalPath, utils.ConflictRename)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Error uploading file"})
+	}
+
+	sizeindex.Refresh(placedPath)
+
+	logs.CreateLog(types.AuditLog{
+		Username:    owner.Username,
+		Action:      "Share link",
+		Description: fmt.Sprintf("%s uploaded %s via share link %d", c.IP(), path.Join(link.Path, filepath.Base(placedPath)), link.ID),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"response": "Successfully uploaded!",
+		"fileName": filepath.Base(placedPath),
+	})
+}
@@ -0,0 +1,121 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/uploads"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/checksum"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/tus"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TusCreateUpload starts a resumable upload into the directory in the path query.
+// The file name comes from the "filename" metadata like tus clients send it. Optional "checksum" metadata
+// like "sha256:<hex>" is checked against the whole file and "conflict" selects the conflict policy.
+func TusCreateUpload(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+	targetPath := c.Query("path")
+
+	if targetPath == "" {
+		return c.Status(400).JSON(fiber.Map{"err": "Missing path query"})
+	}
+
+	length, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return c.Status(400).JSON(fiber.Map{"err": "Invalid Upload-Length"})
+	}
+
+	metadata, err := tus.ParseMetadata(c.Get("Upload-Metadata"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"err": "Invalid Upload-Metadata"})
+	}
+
+	fileName := metadata["filename"]
+	if fileName == "" {
+		fileName = metadata["name"]
+	}
+	if fileName == "" || fileName == "." || fileName == ".." || strings.ContainsAny(fileName, `/\`) {
+		return c.Status(400).JSON(fiber.Map{"err": "Missing or invalid filename metadata"})
+	}
+
+	conflictPolicy, err := utils.ParseConflictPolicy(metadata["conflict"])
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"err": err.Error()})
+	}
+
+	if _, err := checksum.Parse(metadata["checksum"]); err != nil {
+		return c.Status(400).JSON(fiber.Map{"err": err.Error()})
+	}
+
+	uploadPath := strings.TrimSuffix(targetPath, "/") + "/" + fileName
+
+	if !acl.Allowed(account, types.PermissionUploadFiles, uploadPath) {
+		return c.Status(403).JSON(fiber.Map{"err": "No permission!"})
+	}
+
+	dirInfo, err := os.Stat(filepath.Join(config.Config.GetScopedFolder(account.Scope), targetPath))
+	if err != nil || !dirInfo.IsDir() {
+		return c.Status(404).JSON(fiber.Map{"err": "Directory not found"})
+	}
+
+	// Failing early saves the client from sending the whole file, the final move checks again
+	if conflictPolicy == utils.ConflictFail && utils.IsExistingPath(filepath.Join(config.Config.GetScopedFolder(account.Scope), uploadPath)) {
+		return c.Status(409).JSON(fiber.Map{"err": "File already exists!"})
+	}
+
+	// Unfinished uploads are stored outside of the host folder, they are reserved with their full length
+	pendingSize, err := uploads.GetPendingSize(account.Username)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't check storage space"})
+	}
+
+	if err := utils.CheckQuota(account, pendingSize+length); errors.Is(err, utils.ErrQuotaExceeded) {
+		return c.Status(507).JSON(fiber.Map{"err": "Not enough space!"})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't check storage space"})
+	}
+
+	id, err := tus.NewID()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	upload := types.Upload{
+		ID:        id,
+		Username:  account.Username,
+		Path:      uploadPath,
+		Length:    length,
+		Metadata:  c.Get("Upload-Metadata"),
+		ExpiresAt: time.Now().Add(config.Config.GetUploadExpiration()),
+	}
+
+	// The record is saved before the data file, so the cleanup task never sees a file without a record
+	if err := uploads.CreateUpload(upload); err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't create upload"})
+	}
+
+	if err := tus.Create(id); err != nil {
+		uploads.DeleteUpload(id)
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't create upload"})
+	}
+
+	c.Set("Location", "/api/tus/"+id)
+
+	// Empty files are complete right away, clients don't send a PATCH for them
+	if length == 0 {
+		return finishTusUpload(c, account, upload, 201)
+	}
+
+	c.Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	return c.SendStatus(201)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/utils/checksum"
+	"github.com/MertJSX/folder-host-go/utils/tus"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TusOptions lets tus clients discover the supported version and extensions without authentication.
+func TusOptions(c *fiber.Ctx) error {
+	c.Set("Tus-Resumable", tus.Version)
+	c.Set("Tus-Version", tus.Version)
+	c.Set("Tus-Extension", tus.Extensions)
+	c.Set("Tus-Checksum-Algorithm", strings.Join(checksum.Algorithms, ","))
+	return c.SendStatus(204)
+}
@@ -0,0 +1,136 @@
+package routes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/uploads"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/checksum"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+	"github.com/MertJSX/folder-host-go/utils/tus"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TusPatchUpload appends the request body to the upload. The upload is moved to its target path
+// when the last byte arrives.
+func TusPatchUpload(c *fiber.Ctx) error {
+	account := c.Locals("account").(types.Account)
+
+	unlock := tus.Lock(c.Params("id"))
+	defer unlock()
+
+	upload, ok := getOwnTusUpload(c)
+	if !ok {
+		return nil
+	}
+
+	if c.Get("Content-Type") != "application/offset+octet-stream" {
+		return c.Status(415).JSON(fiber.Map{"err": "Content-Type must be application/offset+octet-stream"})
+	}
+
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return c.Status(400).JSON(fiber.Map{"err": "Invalid Upload-Offset"})
+	}
+
+	if offset != upload.Offset {
+		return c.Status(409).JSON(fiber.Map{"err": "Upload-Offset doesn't match the upload"})
+	}
+
+	chunkChecksum, err := checksum.ParseTusHeader(c.Get("Upload-Checksum"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"err": err.Error()})
+	}
+
+	body := c.Body()
+	if offset+int64(len(body)) > upload.Length {
+		return c.Status(413).JSON(fiber.Map{"err": "Upload is longer than Upload-Length"})
+	}
+
+	if !acl.Allowed(account, types.PermissionUploadFiles, upload.Path) {
+		return c.Status(403).JSON(fiber.Map{"err": "No permission!"})
+	}
+
+	// The space may be used up since the upload was created, check again before writing anything
+	pendingSize, err := uploads.GetPendingSize(account.Username)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't check storage space"})
+	}
+
+	if err := utils.CheckQuota(account, pendingSize); errors.Is(err, utils.ErrQuotaExceeded) {
+		return c.Status(507).JSON(fiber.Map{"err": "Not enough space!"})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't check storage space"})
+	}
+
+	if err := chunkChecksum.Verify(body); err != nil {
+		return c.Status(460).JSON(fiber.Map{"err": "Checksum mismatch"})
+	}
+
+	upload.Offset, err = tus.Append(upload, bytes.NewReader(body))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't save upload"})
+	}
+
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+
+	if upload.Offset == upload.Length {
+		return finishTusUpload(c, account, upload, 204)
+	}
+
+	upload.ExpiresAt = time.Now().Add(config.Config.GetUploadExpiration())
+	if err := uploads.UpdateUploadOffset(upload.ID, upload.Offset, upload.ExpiresAt); err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't save upload"})
+	}
+
+	c.Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	return c.SendStatus(204)
+}
+
+// finishTusUpload moves a complete upload into place and answers with status. The upload is removed
+// when the file fails verification or conflicts, the client has to start over.
+func finishTusUpload(c *fiber.Ctx, account types.Account, upload types.Upload, status int) error {
+	metadata, _ := tus.ParseMetadata(upload.Metadata)
+	conflictPolicy, _ := utils.ParseConflictPolicy(metadata["conflict"])
+	fileChecksum, _ := checksum.Parse(metadata["checksum"])
+
+	finalPath := filepath.Join(config.Config.GetScopedFolder(account.Scope), upload.Path)
+	placedPath, err := tus.Finish(upload, finalPath, conflictPolicy, fileChecksum)
+
+	if err != nil {
+		uploads.DeleteUpload(upload.ID)
+		tus.Remove(upload.ID)
+
+		if errors.Is(err, checksum.ErrMismatch) {
+			return c.Status(460).JSON(fiber.Map{"err": "Checksum mismatch"})
+		}
+		if errors.Is(err, utils.ErrFileExists) {
+			return c.Status(409).JSON(fiber.Map{"err": "File already exists!"})
+		}
+		return c.Status(500).JSON(fiber.Map{"err": "Error uploading file"})
+	}
+
+	if err := uploads.DeleteUpload(upload.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Error uploading file"})
+	}
+
+	sizeindex.Refresh(placedPath)
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Upload",
+		Description: fmt.Sprintf("%s uploaded a %s file.", account.Username, filepath.Base(placedPath)),
+	})
+
+	return c.SendStatus(status)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/MertJSX/folder-host-go/database/uploads"
+	"github.com/MertJSX/folder-host-go/utils/tus"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TusTerminateUpload cancels an unfinished upload and frees its data.
+func TusTerminateUpload(c *fiber.Ctx) error {
+	unlock := tus.Lock(c.Params("id"))
+	defer unlock()
+
+	upload, ok := getOwnTusUpload(c)
+	if !ok {
+		return nil
+	}
+
+	if err := uploads.DeleteUpload(upload.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	if err := tus.Remove(upload.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+	}
+
+	return c.SendStatus(204)
+}
@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/uploads"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TusUploadOffset answers HEAD requests, clients use it to find where an interrupted upload continues.
+func TusUploadOffset(c *fiber.Ctx) error {
+	upload, ok := getOwnTusUpload(c)
+	if !ok {
+		return nil
+	}
+
+	c.Set("Cache-Control", "no-store")
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	c.Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	if upload.Metadata != "" {
+		c.Set("Upload-Metadata", upload.Metadata)
+	}
+
+	return c.SendStatus(200)
+}
+
+// getOwnTusUpload finds the upload in the id param. Uploads of other users look like missing ones.
+// It writes the error response itself, the caller must stop if it returns false.
+func getOwnTusUpload(c *fiber.Ctx) (types.Upload, bool) {
+	upload, err := uploads.GetUpload(c.Params("id"))
+
+	if err == sql.ErrNoRows || (err == nil && upload.Username != c.Locals("account").(types.Account).Username) {
+		c.Status(404).JSON(fiber.Map{"err": "Upload not found"})
+		return types.Upload{}, false
+	}
+
+	if err != nil {
+		c.Status(500).JSON(fiber.Map{"err": "Unknown server error!"})
+		return types.Upload{}, false
+	}
+
+	if upload.ExpiresAt.Before(time.Now()) {
+		c.Status(410).JSON(fiber.Map{"err": "Upload expired"})
+		return types.Upload{}, false
+	}
+
+	return upload, true
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/twofactor"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TwoFactorDisable(c *fiber.Ctx) error {
+	account, requestBody, ok := parseTwoFactorRequest(c)
+	if !ok {
+		return nil
+	}
+
+	if account.TwoFactorRequired {
+		return c.Status(403).JSON(fiber.Map{"err": "Two factor authentication is required for this account."})
+	}
+
+	if !account.TwoFactorEnabled {
+		return c.Status(400).JSON(fiber.Map{"err": "Two factor authentication is not enabled."})
+	}
+
+	if !twofactor.VerifyCode(&account, requestBody.Code) {
+		return rejectWrongCode(c, account)
+	}
+
+	if err := twofactor.Disable(*account.ID, account.Username); err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error."})
+	}
+
+	cache.SessionCache.Delete(account.Username)
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Disable 2FA",
+		Description: fmt.Sprintf("%s disabled two factor authentication.", account.Username),
+	})
+
+	return c.Status(200).JSON(fiber.Map{"response": "Two factor authentication disabled!"})
+}
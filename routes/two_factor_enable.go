@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/twofactor"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/MertJSX/folder-host-go/utils/totp"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TwoFactorEnable(c *fiber.Ctx) error {
+	account, requestBody, ok := parseTwoFactorRequest(c)
+	if !ok {
+		return nil
+	}
+
+	if account.TOTPPendingSecret == "" {
+		return c.Status(400).JSON(fiber.Map{"err": "Two factor setup wasn't started."})
+	}
+
+	// Only a code of the pending secret confirms it, recovery codes of the old secret don't
+	step, ok := totp.Validate(account.TOTPPendingSecret, requestBody.Code, time.Now(), 0)
+	if !ok {
+		return rejectWrongCode(c, account)
+	}
+
+	enabled, err := twofactor.Enable(*account.ID, account.TOTPPendingSecret, step)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error."})
+	}
+	if !enabled {
+		return c.Status(409).JSON(fiber.Map{"err": "Two factor setup was started again, use the new secret."})
+	}
+
+	recoveryCodes, err := twofactor.ReplaceRecoveryCodes(account.Username)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Two factor authentication was enabled, but recovery codes couldn't be created."})
+	}
+
+	cache.SessionCache.Delete(account.Username)
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "Enable 2FA",
+		Description: fmt.Sprintf("%s enabled two factor authentication.", account.Username),
+	})
+
+	return c.Status(200).JSON(fiber.Map{
+		"response":      "Two factor authentication enabled!",
+		"recoveryCodes": recoveryCodes,
+	})
+}
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"fmt"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/twofactor"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TwoFactorRecoveryCodes replaces all recovery codes of the user. The old ones stop working.
+func TwoFactorRecoveryCodes(c *fiber.Ctx) error {
+	account, requestBody, ok := parseTwoFactorRequest(c)
+	if !ok {
+		return nil
+	}
+
+	if !account.TwoFactorEnabled {
+		return c.Status(400).JSON(fiber.Map{"err": "Two factor authentication is not enabled."})
+	}
+
+	if !twofactor.VerifyCode(&account, requestBody.Code) {
+		return rejectWrongCode(c, account)
+	}
+
+	recoveryCodes, err := twofactor.ReplaceRecoveryCodes(account.Username)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error."})
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      "2FA recovery codes",
+		Description: fmt.Sprintf("%s generated new two factor recovery codes.", account.Username),
+	})
+
+	return c.Status(200).JSON(fiber.Map{"recoveryCodes": recoveryCodes})
+}
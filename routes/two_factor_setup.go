@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"encoding/base64"
+
+	"github.com/MertJSX/folder-host-go/database/twofactor"
+	"github.com/MertJSX/folder-host-go/middleware"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/bruteforce"
+	"github.com/MertJSX/folder-host-go/utils/totp"
+	"github.com/gofiber/fiber/v2"
+	"github.com/skip2/go-qrcode"
+)
+
+type twoFactorRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// TwoFactorSetup creates a new pending TOTP secret. It isn't used for logins until TwoFactorEnable confirms a code,
+// an active secret stays active until then.
+// The 2FA routes authenticate with the password, because users who must enroll can't get a token before that.
+func TwoFactorSetup(c *fiber.Ctx) error {
+	account, requestBody, ok := parseTwoFactorRequest(c)
+	if !ok {
+		return nil
+	}
+
+	// Replacing an active secret needs the current second factor
+	if account.TwoFactorEnabled && !twofactor.VerifyCode(&account, requestBody.Code) {
+		return rejectWrongCode(c, account)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error."})
+	}
+
+	if err := twofactor.SavePendingSecret(*account.ID, secret); err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Unknown server error."})
+	}
+
+	provisioningURI := totp.ProvisioningURI("FolderHost", account.Username, secret)
+
+	qrCode, err := qrcode.Encode(provisioningURI, qrcode.Medium, 256)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Error while creating QR code."})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"secret":          secret,
+		"provisioningUri": provisioningURI,
+		"qrCode":          "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode),
+	})
+}
+
+// parseTwoFactorRequest writes the error response itself and returns false if the request can't continue.
+func parseTwoFactorRequest(c *fiber.Ctx) (types.Account, twoFactorRequest, bool) {
+	var requestBody twoFactorRequest
+
+	if err := c.BodyParser(&requestBody); err != nil {
+		c.Status(400).JSON(fiber.Map{"err": "Bad request! " + err.Error()})
+		return types.Account{}, requestBody, false
+	}
+
+	if requestBody.Username == "" || requestBody.Password == "" {
+		c.Status(400).JSON(fiber.Map{"err": "authorization required"})
+		return types.Account{}, requestBody, false
+	}
+
+	account, ok := middleware.VerifyPasswordLogin(c, requestBody.Username, requestBody.Password)
+	if !ok {
+		return types.Account{}, requestBody, false
+	}
+
+	if account.ID == nil {
+		c.Status(404).JSON(fiber.Map{"err": "account not found"})
+		return types.Account{}, requestBody, false
+	}
+
+	return account, requestBody, true
+}
+
+// rejectWrongCode counts the failed attempt like a wrong password, codes can be brute forced too.
+func rejectWrongCode(c *fiber.Ctx, account types.Account) error {
+	bruteforce.RegisterFailure(c.IP(), account.Username, true, "wrong two factor code")
+	return c.Status(401).JSON(fiber.Map{"err": twofactor.ErrWrongCode.Error(), "twoFactor": true})
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,12 +12,16 @@ import (
 	"github.com/MertJSX/folder-host-go/database/logs"
 	"github.com/MertJSX/folder-host-go/types"
 	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/checksum"
 	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+	"github.com/MertJSX/folder-host-go/utils/versions"
 	"github.com/gofiber/fiber/v2"
 )
 
 func ChunkedUpload(c *fiber.Ctx) error {
-	if !c.Locals("account").(types.Account).Permissions.UploadFiles {
+	if !acl.Allowed(c.Locals("account").(types.Account), types.PermissionUploadFiles, c.Query("path")+"/"+c.FormValue("fileName")) {
 		return c.Status(403).JSON(
 			fiber.Map{"err": "No permission!"},
 		)
@@ -45,6 +50,49 @@ func ChunkedUpload(c *fiber.Ctx) error {
 	total, _ := strconv.ParseInt(totalChunks, 10, 64)
 	currentChunk, _ := strconv.Atoi(chunkIndex)
 
+	if len(form.File["file"]) == 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"err": "Missing file",
+		})
+	}
+
+	conflictPolicy, err := utils.ParseConflictPolicy(c.FormValue("conflict"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"err": err.Error(),
+		})
+	}
+
+	// Checksums are optional, like "sha256:<hex>". The file checksum is checked on the last chunk.
+	chunkChecksum, err := checksum.Parse(c.FormValue("chunkChecksum"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"err": err.Error(),
+		})
+	}
+
+	fileChecksum, err := checksum.Parse(c.FormValue("fileChecksum"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"err": err.Error(),
+		})
+	}
+
+	// Chunks wait in ./tmp until the last one, they count to the quota like the merged file
+	uploadSize := form.File["file"][0].Size + uploadedChunksSize(fileID, currentChunk)
+	if err := utils.CheckQuota(c.Locals("account").(types.Account), uploadSize); errors.Is(err, utils.ErrQuotaExceeded) {
+		removeChunks(fileID, currentChunk)
+		return c.Status(507).JSON(fiber.Map{
+			"err": "Not enough space!",
+		})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"err": "Couldn't check storage space",
+		})
+	}
+
+	finalPath := filepath.Join(config.GetScopedFolder(scope), targetPath, fileName)
+
 	if total == 1 {
 		file, err := form.File["file"][0].Open()
 		if err != nil {
@@ -54,31 +102,28 @@ func ChunkedUpload(c *fiber.Ctx) error {
 		}
 		defer file.Close()
 
-		finalPath := filepath.Join(config.GetScopedFolder(scope), targetPath, fileName)
-		outFile, err := os.Create(finalPath)
+		// The file is written next to its target and renamed into place, a failed upload never leaves a partial file
+		tempFile, err := utils.CreateTempFile(finalPath)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"err": "Couldn't create file",
 			})
 		}
-		defer outFile.Close()
+		defer os.Remove(tempFile.Name())
 
-		if _, err := io.Copy(outFile, file); err != nil {
+		_, err = io.Copy(tempFile, file)
+		tempFile.Close()
+		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"err": "Couldn't save file",
 			})
 		}
 
-		logs.CreateLog(types.AuditLog{
-			Username:    c.Locals("account").(types.Account).Username,
-			Action:      "Upload",
-			Description: fmt.Sprintf("%s uploaded a %s file.", c.Locals("account").(types.Account).Username, fileName),
-		})
+		if err := chunkChecksum.VerifyFile(tempFile.Name()); err != nil {
+			return checksumError(c, err)
+		}
 
-		return c.JSON(fiber.Map{
-			"response": "Successfully uploaded!",
-			"uploaded": true,
-		})
+		return placeUploadedFile(c, tempFile.Name(), finalPath, fileChecksum, conflictPolicy)
 	}
 
 	chunkPath := filepath.Join("./tmp", fileID+"_"+chunkIndex)
@@ -100,6 +145,10 @@ func ChunkedUpload(c *fiber.Ctx) error {
 
 	chunkContent, _ := utils.FileToString(chunkFile)
 
+	if err := chunkChecksum.Verify([]byte(chunkContent)); err != nil {
+		return checksumError(c, err)
+	}
+
 	ch := make(chan error, 1)
 	var wg sync.WaitGroup
 
@@ -121,23 +170,23 @@ func ChunkedUpload(c *fiber.Ctx) error {
 
 	// Merge all chunks
 	if currentChunk == int(total)-1 { // If it's the last chunk
-		finalPath := filepath.Join(config.GetScopedFolder(scope), targetPath, fileName)
-		if err := mergeChunks(fileID, finalPath, int(total)); err != nil {
+		tempFile, err := utils.CreateTempFile(finalPath)
+		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"err": "Error uploading file",
 			})
 		}
+		defer os.Remove(tempFile.Name())
 
-		logs.CreateLog(types.AuditLog{
-			Username:    c.Locals("account").(types.Account).Username,
-			Action:      "Upload",
-			Description: fmt.Sprintf("%s uploaded a %s file.", c.Locals("account").(types.Account).Username, fileName),
-		})
+		err = mergeChunks(fileID, tempFile, int(total))
+		tempFile.Close()
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"err": "Error uploading file",
+			})
+		}
 
-		return c.JSON(fiber.Map{
-			"response": "Successfully uploaded!",
-			"uploaded": true,
-		})
+		return placeUploadedFile(c, tempFile.Name(), finalPath, fileChecksum, conflictPolicy)
 	}
 
 	return c.JSON(fiber.Map{
@@ -145,13 +194,66 @@ func ChunkedUpload(c *fiber.Ctx) error {
 	})
 }
 
-func mergeChunks(fileID, outputPath string, totalChunks int) error {
-	outFile, err := os.Create(outputPath)
-	if err != nil {
-		return err
+func uploadedChunksSize(fileID string, currentChunk int) int64 {
+	var size int64
+	for i := 0; i < currentChunk; i++ {
+		if chunkStat, err := os.Stat(filepath.Join("./tmp", fmt.Sprintf("%s_%d", fileID, i))); err == nil {
+			size += chunkStat.Size()
+		}
 	}
-	defer outFile.Close()
+	return size
+}
+
+func removeChunks(fileID string, currentChunk int) {
+	for i := 0; i < currentChunk; i++ {
+		os.Remove(filepath.Join("./tmp", fmt.Sprintf("%s_%d", fileID, i)))
+	}
+}
+
+// placeUploadedFile verifies the whole file and moves it from tempPath to its target with the conflict policy.
+func placeUploadedFile(c *fiber.Ctx, tempPath string, finalPath string, fileChecksum checksum.Checksum, conflictPolicy string) error {
+	if err := fileChecksum.VerifyFile(tempPath); err != nil {
+		return checksumError(c, err)
+	}
+
+	placedPath, err := versions.Replace(c.Locals("account").(types.Account).Username, tempPath, finalPath, conflictPolicy)
+	if errors.Is(err, utils.ErrFileExists) {
+		return c.Status(409).JSON(fiber.Map{
+			"err": "File already exists!",
+		})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"err": "Error uploading file",
+		})
+	}
+
+	sizeindex.Refresh(placedPath)
+
+	logs.CreateLog(types.AuditLog{
+		Username:    c.Locals("account").(types.Account).Username,
+		Action:      "Upload",
+		Description: fmt.Sprintf("%s uploaded a %s file.", c.Locals("account").(types.Account).Username, filepath.Base(placedPath)),
+	})
+
+	return c.JSON(fiber.Map{
+		"response": "Successfully uploaded!",
+		"uploaded": true,
+		"fileName": filepath.Base(placedPath),
+	})
+}
+
+func checksumError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, checksum.ErrMismatch) {
+		return c.Status(422).JSON(fiber.Map{
+			"err": "Checksum mismatch",
+		})
+	}
+	return c.Status(500).JSON(fiber.Map{
+		"err": "Couldn't verify checksum",
+	})
+}
 
+func mergeChunks(fileID string, outFile *os.File, totalChunks int) error {
 	for i := 0; i < totalChunks; i++ {
 		chunkPath := filepath.Join("./tmp", fmt.Sprintf("%s_%d", fileID, i))
 		chunkData, err := os.Open(chunkPath)
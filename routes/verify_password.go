@@ -11,7 +11,10 @@ import (
 )
 
 func VerifyPassword(c *fiber.Ctx) error {
-	token, err := utils.CreateToken(c.Locals("account").(types.Account).Username, config.Config.SecretJwtKey)
+	// Kept for older clients, new ones should use /api/auth/login to get a refresh token too.
+	// The token lives as short as the ones of issueSession, a refresh token isn't created here.
+	accessTokenLifetime := config.Config.GetAccessTokenLifetime()
+	token, err := utils.CreateToken(c.Locals("account").(types.Account), config.Config.SecretJwtKey, accessTokenLifetime)
 
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"err": "unknown error while getting token"})
@@ -27,6 +30,7 @@ func VerifyPassword(c *fiber.Ctx) error {
 		fiber.Map{
 			"res":         "Verified!",
 			"token":       token,
+			"expiresIn":   int(accessTokenLifetime.Seconds()),
 			"permissions": c.Locals("account").(types.Account).Permissions,
 		},
 	)
@@ -0,0 +1,210 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/davfs"
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebDAV serves the scope of the account at /dav. The requested paths are checked here so clients get
+// a clear status, davfs checks the items below them.
+func WebDAV(c *fiber.Ctx) error {
+	var (
+		account     types.Account     = c.Locals("account").(types.Account)
+		config      *types.ConfigFile = &config.Config
+		method      string            = c.Method()
+		destination string
+	)
+
+	unescapedPath, err := url.PathUnescape(c.Path())
+	if err != nil {
+		return rejectDav(c, 400, "Bad request!")
+	}
+
+	itemPath, ok := davItemPath(unescapedPath)
+	if !ok {
+		return rejectDav(c, 400, "Bad request!")
+	}
+
+	if method == "COPY" || method == "MOVE" {
+		destinationURL, err := url.Parse(c.Get("Destination"))
+		if err != nil {
+			return rejectDav(c, 400, "Invalid destination!")
+		}
+
+		destination, ok = davItemPath(destinationURL.Path)
+		if !ok {
+			return rejectDav(c, 400, "Invalid destination!")
+		}
+	}
+
+	switch method {
+	case fiber.MethodOptions:
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodPost:
+		if !acl.Allowed(account, types.PermissionDownloadFiles, itemPath) {
+			return rejectDav(c, 403, "No permission!")
+		}
+	case "PROPFIND":
+		if !acl.Allowed(account, types.PermissionReadDirectories, itemPath) {
+			return rejectDav(c, 403, "No permission!")
+		}
+
+		// Listing whole trees is too expensive, clients ask for one level at a time
+		if depth := c.Get("Depth"); depth != "0" && depth != "1" {
+			return rejectDav(c, 403, "Depth infinity is not supported!")
+		}
+	case "PROPPATCH":
+		if !acl.Allowed(account, types.PermissionChange, itemPath) {
+			return rejectDav(c, 403, "No permission!")
+		}
+	case fiber.MethodPut:
+		if !acl.Allowed(account, types.PermissionUploadFiles, itemPath) {
+			return rejectDav(c, 403, "No permission!")
+		}
+
+		if length := c.Request().Header.ContentLength(); length > 0 {
+			if err := utils.CheckQuota(account, int64(length)); errors.Is(err, utils.ErrQuotaExceeded) {
+				return rejectDav(c, 507, "Not enough space!")
+			} else if err != nil {
+				log.Printf("Error: %v\n", err)
+				return rejectDav(c, 500, "Internal server error!")
+			}
+		}
+	case "MKCOL":
+		if !acl.Allowed(account, types.PermissionCreate, itemPath) {
+			return rejectDav(c, 403, "No permission!")
+		}
+	case fiber.MethodDelete:
+		if itemPath == "/" {
+			return rejectDav(c, 403, "You can't delete the main folder!")
+		}
+
+		if !acl.Allowed(account, types.PermissionDelete, itemPath) {
+			return rejectDav(c, 403, "No permission!")
+		}
+	case "COPY":
+		if !acl.Allowed(account, types.PermissionCopy, itemPath) || !acl.Allowed(account, types.PermissionCopy, destination) {
+			return rejectDav(c, 403, "No permission!")
+		}
+
+		if config.StorageLimit != "" || account.Quota != "" {
+			if status, message := checkCopyQuota(account, config.GetScopedFolder(account.Scope)+itemPath); status != 0 {
+				return rejectDav(c, status, message)
+			}
+		}
+	case "MOVE":
+		if itemPath == "/" {
+			return rejectDav(c, 403, "You can't move the main folder!")
+		}
+
+		permission := types.PermissionMove
+		if path.Dir(itemPath) == path.Dir(destination) {
+			permission = types.PermissionRename
+		}
+
+		if !acl.Allowed(account, permission, itemPath) || !acl.Allowed(account, permission, destination) {
+			return rejectDav(c, 403, "No permission!")
+		}
+	case "LOCK", "UNLOCK":
+		if !acl.Allowed(account, types.PermissionUploadFiles, itemPath) {
+			return rejectDav(c, 403, "No permission!")
+		}
+	}
+
+	if err := davfs.Serve(c, davfs.NewHandler(account)); err != nil {
+		return err
+	}
+
+	if status := c.Response().StatusCode(); status < 200 || status > 299 {
+		return nil
+	}
+
+	switch method {
+	case fiber.MethodGet:
+		if utils.IsDownloadStart(c) {
+			logDav(account, "Download", fmt.Sprintf("downloaded a %s file", itemPath))
+		}
+	case fiber.MethodPut:
+		logDav(account, "Upload", fmt.Sprintf("uploaded a %s file", itemPath))
+	case "MKCOL":
+		logDav(account, "Create folder", fmt.Sprintf("created a %s folder", itemPath))
+	case fiber.MethodDelete:
+		logDav(account, "Delete", fmt.Sprintf("deleted %s", itemPath))
+	case "COPY":
+		logDav(account, "Create copy", fmt.Sprintf("created a copy of %s at %s", itemPath, destination))
+	case "MOVE":
+		logDav(account, "Move", fmt.Sprintf("moved an item %s -> %s", itemPath, destination))
+	}
+
+	return nil
+}
+
+// davItemPath converts an unescaped request path like "/dav/docs" to the path in the scope, like "/docs".
+func davItemPath(requestPath string) (string, bool) {
+	itemPath, ok := strings.CutPrefix(requestPath, davfs.Prefix)
+	if !ok || (itemPath != "" && !strings.HasPrefix(itemPath, "/")) {
+		return "", false
+	}
+
+	if itemPath != "" && !utils.IsSafePath(itemPath) {
+		return "", false
+	}
+
+	return acl.NormalizePath(itemPath), true
+}
+
+// checkCopyQuota returns the status and the error message if the item doesn't fit, like the copy route.
+func checkCopyQuota(account types.Account, diskPath string) (int, string) {
+	stat, err := os.Stat(diskPath)
+	if err != nil {
+		// The handler answers for missing items
+		return 0, ""
+	}
+
+	size := stat.Size()
+	if stat.IsDir() {
+		size, _, err = utils.GetDirectorySize(diskPath)
+		if err != nil {
+			return 520, "Internal server error!"
+		}
+	}
+
+	remainingFreeSpace, err := utils.GetRemainingSpace(account)
+	if err != nil {
+		return 520, "Internal server error!"
+	}
+
+	if size > remainingFreeSpace {
+		return 507, "Not enough space!"
+	}
+
+	return 0, ""
+}
+
+// rejectDav answers before the handler runs, the body of the request stays unread on the connection.
+func rejectDav(c *fiber.Ctx, status int, message string) error {
+	if c.Context().RequestBodyStream() != nil && c.Request().Header.ContentLength() != 0 {
+		c.Context().SetConnectionClose()
+	}
+	return c.Status(status).JSON(fiber.Map{"err": message})
+}
+
+func logDav(account types.Account, action string, description string) {
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      action,
+		Description: fmt.Sprintf("%s %s over WebDAV.", account.Username, description),
+	})
+}
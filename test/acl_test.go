@@ -0,0 +1,65 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACL_Allowed(t *testing.T) {
+	account := types.Account{
+		Permissions: types.AccountPermissions{ReadFiles: true, Change: true, Delete: false},
+		ACL: []types.ACLRule{
+			{Path: "/archive", Permission: types.PermissionAll, Allow: false},
+			{Path: "/archive", Permission: types.PermissionReadFiles, Allow: true},
+			{Path: "/projects", Permission: types.PermissionDelete, Allow: true},
+			{Path: "/projects/keep", Permission: types.PermissionDelete, Allow: false},
+		},
+	}
+
+	t.Run("should use global permissions without a matching rule", func(t *testing.T) {
+		assert.True(t, acl.Allowed(account, types.PermissionChange, "/notes.txt"))
+		assert.False(t, acl.Allowed(account, types.PermissionDelete, "/notes.txt"))
+	})
+
+	t.Run("should inherit rules down the tree", func(t *testing.T) {
+		assert.False(t, acl.Allowed(account, types.PermissionChange, "/archive/2024/report.txt"))
+		assert.True(t, acl.Allowed(account, types.PermissionDelete, "./projects/app/main.go"))
+	})
+
+	t.Run("should prefer the exact permission over *", func(t *testing.T) {
+		assert.True(t, acl.Allowed(account, types.PermissionReadFiles, "/archive/2024/report.txt"))
+	})
+
+	t.Run("should let the most specific path win", func(t *testing.T) {
+		assert.False(t, acl.Allowed(account, types.PermissionDelete, "/projects/keep/a.txt"))
+	})
+
+	t.Run("should not match sibling paths with the same prefix", func(t *testing.T) {
+		assert.True(t, acl.Allowed(account, types.PermissionChange, "/archive-old/a.txt"))
+	})
+
+	t.Run("should prefer deny on the same path and permission", func(t *testing.T) {
+		conflicting := account
+		conflicting.ACL = []types.ACLRule{
+			{Path: "/", Permission: types.PermissionChange, Allow: true},
+			{Path: "/", Permission: types.PermissionChange, Allow: false},
+		}
+		assert.False(t, acl.Allowed(conflicting, types.PermissionChange, "/a.txt"))
+	})
+}
+
+func TestACL_ValidateRules(t *testing.T) {
+	rules, err := acl.ValidateRules([]types.ACLRule{{Path: "archive/", Permission: types.PermissionDelete}})
+	require.NoError(t, err)
+	assert.Equal(t, "/archive", rules[0].Path)
+
+	_, err = acl.ValidateRules([]types.ACLRule{{Path: "/", Permission: "edit_users"}})
+	assert.Error(t, err, "Only path permissions can have rules")
+
+	_, err = acl.ValidateRules([]types.ACLRule{{Path: "/../etc", Permission: types.PermissionReadFiles}})
+	assert.Error(t, err)
+}
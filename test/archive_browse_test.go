@@ -0,0 +1,100 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveBrowse(t *testing.T) {
+	t.Chdir(t.TempDir())
+	oldFolder := config.Config.Folder
+	config.Config.Folder = "./host"
+	t.Cleanup(func() { config.Config.Folder = oldFolder })
+
+	require.NoError(t, os.MkdirAll(filepath.Join("host", "out", "bin"), 0777))
+	archivePath := filepath.Join("host", "release.zip")
+	writeZip(t, archivePath, map[string][]byte{
+		"release/readme.txt":   []byte("hello"),
+		"release/bin/tool":     []byte("tool"),
+		"release/bin/tool.sig": []byte("sig"),
+		"release/docs/a.md":    []byte("# a"),
+	}, map[string]string{"release/passwd": "/etc/passwd"})
+
+	t.Run("should list entries like folder items", func(t *testing.T) {
+		items, err := utils.ListArchive(utils.ArchiveFormatZip, archivePath)
+		require.NoError(t, err)
+
+		paths := make([]string, len(items))
+		for index, item := range items {
+			paths[index] = item.Path
+		}
+		// Folders without their own entry are added, the symlink isn't listed
+		assert.Equal(t, []string{"/release", "/release/bin", "/release/bin/tool", "/release/bin/tool.sig", "/release/docs", "/release/docs/a.md", "/release/readme.txt"}, paths)
+
+		assert.True(t, items[1].IsDirectory)
+		assert.Equal(t, "/release/", items[1].ParentPath)
+		assert.Equal(t, "tool", items[2].Name)
+		assert.Equal(t, int64(4), items[2].SizeBytes)
+		assert.Equal(t, "/", items[0].ParentPath)
+	})
+
+	t.Run("should read a single entry", func(t *testing.T) {
+		content, err := utils.ReadArchiveEntry(utils.ArchiveFormatZip, archivePath, "/release/readme.txt", 100)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(content))
+
+		_, err = utils.ReadArchiveEntry(utils.ArchiveFormatZip, archivePath, "release/readme.txt", 2)
+		assert.ErrorIs(t, err, utils.ErrEntryTooLarge)
+		_, err = utils.ReadArchiveEntry(utils.ArchiveFormatZip, archivePath, "release/missing.txt", 100)
+		assert.ErrorIs(t, err, utils.ErrEntryNotFound)
+		_, err = utils.ReadArchiveEntry(utils.ArchiveFormatZip, archivePath, "release/passwd", 100)
+		assert.ErrorIs(t, err, utils.ErrEntryNotFound)
+	})
+
+	t.Run("should extract only the selected entries", func(t *testing.T) {
+		dest := filepath.Join("host", "selected")
+		require.NoError(t, os.MkdirAll(dest, 0777))
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "keep.txt"), []byte("keep"), 0644))
+
+		entries := []string{"release/bin", "/release/readme.txt"}
+		require.NoError(t, utils.ExtractEntries(context.Background(), utils.ArchiveFormatZip, archivePath, dest, entries, 1<<30, func(int64, bool, string) {}))
+
+		assert.FileExists(t, filepath.Join(dest, "bin", "tool"))
+		assert.FileExists(t, filepath.Join(dest, "bin", "tool.sig"))
+		assert.FileExists(t, filepath.Join(dest, "readme.txt"))
+		assert.FileExists(t, filepath.Join(dest, "keep.txt"))
+		assert.NoDirExists(t, filepath.Join(dest, "docs"))
+		assert.NoDirExists(t, filepath.Join(dest, "release"))
+	})
+
+	t.Run("should validate the selected entries", func(t *testing.T) {
+		account := types.Account{
+			Username:    "mert",
+			Permissions: types.AccountPermissions{Archive: true},
+		}
+
+		job := types.Job{Type: types.JobExtract, Source: "/release.zip", Destination: "/out", Entries: []string{"./release/docs/"}}
+		require.NoError(t, jobs.Validate(account, &job))
+		assert.Equal(t, []string{"release/docs"}, job.Entries)
+
+		invalid := [][]string{
+			{"release/bin"},                    // /out/bin exists
+			{"release/bin/tool", "other/tool"}, // same name
+			{"release/docs", "release/docs/a.md"},
+			{"/"},
+		}
+		for _, entries := range invalid {
+			job := types.Job{Type: types.JobExtract, Source: "/release.zip", Destination: "/out", Entries: entries}
+			assert.Error(t, jobs.Validate(account, &job), entries)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// release/ with readme.txt ("hello bz2\n") and a symlink to /etc/passwd, made with Python's tarfile and bz2
+const tarBz2Fixture = "QlpoOTFBWSZTWbYkBBAAALn/gMqQBABAAf+AAAEGQH5v3tAICCAAkgyQiZlAyaZGmBNHqbFBtKJPU0NA9QGj0mgAH7zfSzLNybyMAhWJAp6JeCMHYjNNpVlYITSIwS73qLveuSEpDQFvClQFWe2G5xX3FCx0nMYCdFtmcFNUT0vKF9K4av3N/4oX5yZDkWIv3BTHFPtAWBE8ZIW7xEWdpXEED+LuSKcKEhbEgIIA"
+
+func TestArchiveFormats(t *testing.T) {
+	t.Chdir(t.TempDir())
+	oldFolder := config.Config.Folder
+	config.Config.Folder = "./host"
+	t.Cleanup(func() { config.Config.Folder = oldFolder })
+
+	require.NoError(t, os.MkdirAll(filepath.Join("host", "docs", "sub"), 0777))
+	require.NoError(t, os.WriteFile(filepath.Join("host", "docs", "a.txt"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join("host", "docs", "sub", "b.txt"), bytes.Repeat([]byte("b"), 100000), 0644))
+
+	noProgress := func(int64, bool, string) {}
+
+	t.Run("should detect formats from the name", func(t *testing.T) {
+		cases := map[string]string{
+			"a.zip":            utils.ArchiveFormatZip,
+			"a.tar":            utils.ArchiveFormatTar,
+			"release.TAR.GZ":   utils.ArchiveFormatTarGz,
+			"a.tgz":            utils.ArchiveFormatTarGz,
+			"a.tar.bz2":        utils.ArchiveFormatTarBz2,
+			"a.txz":            utils.ArchiveFormatTarXz,
+			"v1.2.3.tar.zst":   utils.ArchiveFormatTarZst,
+			"notes.gz":         "",
+			"archive.zip.text": "",
+		}
+		for name, expected := range cases {
+			format, ok := utils.DetectArchiveFormat(name)
+			assert.Equal(t, expected, format, name)
+			assert.Equal(t, expected != "", ok, name)
+		}
+
+		assert.False(t, utils.CanCreateArchive(utils.ArchiveFormatTarBz2))
+		assert.True(t, utils.IsArchiveFormat(utils.ArchiveFormatTarBz2))
+	})
+
+	for _, format := range []string{utils.ArchiveFormatZip, utils.ArchiveFormatTar, utils.ArchiveFormatTarGz, utils.ArchiveFormatTarXz, utils.ArchiveFormatTarZst} {
+		t.Run("should create and extract "+format, func(t *testing.T) {
+			archivePath := filepath.Join("host", "docs"+utils.ArchiveExtension(format))
+			dest := filepath.Join("host", "out-"+format)
+
+			var completed bool
+			require.NoError(t, utils.Archive(context.Background(), format, filepath.Join("host", "docs"), archivePath, 1<<30, func(totalSize int64, isCompleted bool, abortMsg string) {
+				completed = isCompleted
+			}))
+			assert.True(t, completed)
+
+			require.NoError(t, utils.Extract(context.Background(), format, archivePath, dest, 1<<30, noProgress))
+
+			content, err := os.ReadFile(filepath.Join(dest, "sub", "b.txt"))
+			require.NoError(t, err)
+			assert.Len(t, content, 100000)
+			content, err = os.ReadFile(filepath.Join(dest, "a.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, "a", string(content))
+		})
+	}
+
+	t.Run("should extract tar.bz2 and skip links", func(t *testing.T) {
+		data, err := base64.StdEncoding.DecodeString(tarBz2Fixture)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join("host", "release.tar.bz2"), data, 0644))
+
+		dest := filepath.Join("host", "release")
+		require.NoError(t, utils.Extract(context.Background(), utils.ArchiveFormatTarBz2, filepath.Join("host", "release.tar.bz2"), dest, 1<<30, noProgress))
+
+		content, err := os.ReadFile(filepath.Join(dest, "release", "readme.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello bz2\n", string(content))
+
+		_, err = os.Lstat(filepath.Join(dest, "release", "link"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("should reject tar entries outside of the destination", func(t *testing.T) {
+		var buffer bytes.Buffer
+		writer := tar.NewWriter(&buffer)
+		require.NoError(t, writer.WriteHeader(&tar.Header{Name: "../../evil.txt", Mode: 0644, Size: 4, Typeflag: tar.TypeReg}))
+		_, err := writer.Write([]byte("evil"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		require.NoError(t, os.WriteFile(filepath.Join("host", "evil.tar"), buffer.Bytes(), 0644))
+
+		err = utils.Extract(context.Background(), utils.ArchiveFormatTar, filepath.Join("host", "evil.tar"), filepath.Join("host", "evil"), 1<<30, noProgress)
+		assert.Error(t, err)
+		assert.NoFileExists(t, "evil.txt")
+	})
+
+	t.Run("should abort when the extracted size exceeds the remaining space", func(t *testing.T) {
+		var abortMsg string
+		err := utils.Extract(context.Background(), utils.ArchiveFormatTarGz, filepath.Join("host", "docs.tar.gz"), filepath.Join("host", "small"), 1000, func(totalSize int64, isCompleted bool, message string) {
+			if message != "" {
+				abortMsg = message
+			}
+		})
+		assert.Error(t, err)
+		assert.NotEmpty(t, abortMsg)
+		assert.NoDirExists(t, filepath.Join("host", "small"))
+	})
+}
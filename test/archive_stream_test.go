@@ -0,0 +1,73 @@
+package test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamArchive(t *testing.T) {
+	t.Chdir(t.TempDir())
+	oldFolder := config.Config.Folder
+	config.Config.Folder = "./host"
+	t.Cleanup(func() { config.Config.Folder = oldFolder })
+
+	require.NoError(t, os.MkdirAll(filepath.Join("host", "docs", "secret"), 0777))
+	require.NoError(t, os.WriteFile(filepath.Join("host", "docs", "a.txt"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join("host", "docs", "secret", "b.txt"), []byte("b"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join("host", "c.txt"), []byte("c"), 0644))
+
+	sources := []types.ArchiveSource{
+		{DiskPath: "./host/docs", ScopePath: "/docs"},
+		{DiskPath: "./host/c.txt", ScopePath: "/c.txt"},
+	}
+	allowed := func(scopePath string) bool {
+		return scopePath != "/docs/secret"
+	}
+
+	t.Run("should stream a zip without denied entries", func(t *testing.T) {
+		var buffer bytes.Buffer
+		require.NoError(t, utils.StreamArchive(&buffer, utils.ArchiveFormatZip, sources, allowed))
+
+		reader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		require.NoError(t, err)
+
+		var names []string
+		for _, file := range reader.File {
+			names = append(names, file.Name)
+		}
+		assert.ElementsMatch(t, []string{"docs/", "docs/a.txt", "c.txt"}, names)
+	})
+
+	t.Run("should stream a tar.gz", func(t *testing.T) {
+		var buffer bytes.Buffer
+		require.NoError(t, utils.StreamArchive(&buffer, utils.ArchiveFormatTarGz, sources, allowed))
+
+		gzipReader, err := gzip.NewReader(&buffer)
+		require.NoError(t, err)
+		tarReader := tar.NewReader(gzipReader)
+
+		contents := map[string]string{}
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			data, _ := io.ReadAll(tarReader)
+			contents[header.Name] = string(data)
+		}
+		assert.Equal(t, map[string]string{"docs/": "", "docs/a.txt": "a", "c.txt": "c"}, contents)
+	})
+}
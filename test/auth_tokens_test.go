@@ -0,0 +1,182 @@
+package test
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/tokens"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/routes"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthTokens(t *testing.T) {
+	openTestDatabase(t)
+	oldConfig := config.Config
+	config.Config.SecretJwtKey = "test-secret"
+	t.Cleanup(func() { config.Config = oldConfig })
+
+	require.NoError(t, users.CreateUser(&types.Account{Username: "mert", Password: "secret123", Scope: "/"}))
+
+	app := fiber.New()
+	app.Post("/login", routes.Login)
+	app.Post("/refresh", routes.RefreshToken)
+	app.Post("/logout", routes.Logout)
+	app.Post("/verify-password", func(c *fiber.Ctx) error {
+		account, err := users.GetUserByUsername("mert")
+		require.NoError(t, err)
+		c.Locals("account", account)
+		return routes.VerifyPassword(c)
+	})
+	app.Post("/edit-user", func(c *fiber.Ctx) error {
+		c.Locals("account", types.Account{Username: "admin", Permissions: types.AccountPermissions{EditUsers: true}})
+		return routes.EditUser(c)
+	})
+
+	post := func(target string, body fiber.Map) (int, fiber.Map) {
+		return postJSON(t, app, target, body)
+	}
+
+	login := func() string {
+		status, response := post("/login", fiber.Map{"username": "mert", "password": "secret123"})
+		require.Equal(t, 200, status, response)
+		require.NotEmpty(t, response["token"])
+		return response["refreshToken"].(string)
+	}
+
+	t.Run("should rotate the refresh token", func(t *testing.T) {
+		first := login()
+
+		status, response := post("/refresh", fiber.Map{"refreshToken": first})
+		require.Equal(t, 200, status)
+		second := response["refreshToken"].(string)
+		assert.NotEqual(t, first, second)
+
+		status, _ = post("/refresh", fiber.Map{"refreshToken": second})
+		assert.Equal(t, 200, status)
+	})
+
+	t.Run("should revoke the family when a used token comes again", func(t *testing.T) {
+		first := login()
+
+		_, response := post("/refresh", fiber.Map{"refreshToken": first})
+		second := response["refreshToken"].(string)
+
+		status, _ := post("/refresh", fiber.Map{"refreshToken": first})
+		assert.Equal(t, 401, status)
+
+		// The token of the legitimate client dies with the family
+		status, _ = post("/refresh", fiber.Map{"refreshToken": second})
+		assert.Equal(t, 401, status)
+	})
+
+	t.Run("should let only one of many concurrent refreshes win", func(t *testing.T) {
+		token := login()
+
+		var wg sync.WaitGroup
+		statuses := make([]int, 8)
+		for index := range statuses {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				statuses[index], _ = post("/refresh", fiber.Map{"refreshToken": token})
+			}()
+		}
+		wg.Wait()
+
+		succeeded := 0
+		for _, status := range statuses {
+			if status == 200 {
+				succeeded++
+			}
+		}
+		assert.Equal(t, 1, succeeded, statuses)
+	})
+
+	t.Run("should revoke the login on logout", func(t *testing.T) {
+		token := login()
+
+		status, _ := post("/logout", fiber.Map{"refreshToken": token})
+		assert.Equal(t, 200, status)
+
+		status, _ = post("/refresh", fiber.Map{"refreshToken": token})
+		assert.Equal(t, 401, status)
+	})
+
+	t.Run("should remove the refresh tokens with the user", func(t *testing.T) {
+		require.NoError(t, users.CreateUser(&types.Account{Username: "temp", Password: "secret123", Scope: "/"}))
+		status, response := post("/login", fiber.Map{"username": "temp", "password": "secret123"})
+		require.Equal(t, 200, status)
+		token := response["refreshToken"].(string)
+
+		account, err := users.GetUserByUsername("temp")
+		require.NoError(t, err)
+		require.NoError(t, users.RemoveUser(*account.ID))
+
+		_, err = tokens.GetRefreshToken(token)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("should give short lived access tokens to older clients too", func(t *testing.T) {
+		status, response := post("/verify-password", fiber.Map{})
+		require.Equal(t, 200, status)
+		assert.EqualValues(t, config.Config.GetAccessTokenLifetime().Seconds(), response["expiresIn"])
+
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(response["token"].(string), claims, func(*jwt.Token) (any, error) {
+			return []byte(config.Config.SecretJwtKey), nil
+		})
+		require.NoError(t, err)
+		expiresAt, err := claims.GetExpirationTime()
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(config.Config.GetAccessTokenLifetime()), expiresAt.Time, 5*time.Second)
+	})
+
+	loginAs := func(username string) (int, string) {
+		require.NoError(t, users.CreateUser(&types.Account{Username: username, Password: "secret123", Scope: "/"}))
+		status, response := post("/login", fiber.Map{"username": username, "password": "secret123"})
+		require.Equal(t, 200, status)
+
+		account, err := users.GetUserByUsername(username)
+		require.NoError(t, err)
+		return *account.ID, response["refreshToken"].(string)
+	}
+
+	t.Run("should revoke the tokens of a renamed user", func(t *testing.T) {
+		id, token := loginAs("alice")
+
+		status, response := post("/edit-user", fiber.Map{"user": fiber.Map{"id": id, "username": "bob", "scope": "/"}})
+		require.Equal(t, 200, status, response)
+
+		// The new alice has the same token version as the old one had
+		require.NoError(t, users.CreateUser(&types.Account{Username: "alice", Password: "secret123", Scope: "/"}))
+
+		status, _ = post("/refresh", fiber.Map{"refreshToken": token})
+		assert.Equal(t, 401, status)
+
+		refreshToken, err := tokens.GetRefreshToken(token)
+		require.NoError(t, err)
+		assert.True(t, refreshToken.Revoked, "the token should be revoked under the old username")
+	})
+
+	t.Run("should not give the token of a renamed user to a new user with the old name", func(t *testing.T) {
+		id, token := loginAs("carol")
+
+		// Renamed without revoking anything, only the user id protects the new carol
+		renamed, err := users.GetUserByUsername("carol")
+		require.NoError(t, err)
+		renamed.Username = "dave"
+		require.NoError(t, users.UpdateUser(id, &renamed))
+		require.NoError(t, users.CreateUser(&types.Account{Username: "carol", Password: "secret123", Scope: "/"}))
+
+		status, _ := post("/refresh", fiber.Map{"refreshToken": token})
+		assert.Equal(t, 401, status)
+	})
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch(t *testing.T) {
+	t.Chdir(t.TempDir())
+	oldConfig := config.Config
+	config.Config.Folder = "./host"
+	config.Config.RecoveryBin = false
+	t.Cleanup(func() { config.Config = oldConfig })
+
+	account := types.Account{
+		Username:    "mert",
+		Permissions: types.AccountPermissions{Copy: true, Move: true, Delete: true, DownloadFiles: true},
+	}
+
+	setup := func(t *testing.T) {
+		require.NoError(t, os.RemoveAll("host"))
+		require.NoError(t, os.MkdirAll(filepath.Join("host", "docs"), 0777))
+		require.NoError(t, os.MkdirAll(filepath.Join("host", "archive"), 0777))
+		for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+			require.NoError(t, os.WriteFile(filepath.Join("host", "docs", name), []byte(name), 0644))
+		}
+	}
+
+	statuses := func(results []types.BatchResult) []string {
+		list := make([]string, len(results))
+		for index, result := range results {
+			list[index] = result.Status
+		}
+		return list
+	}
+
+	t.Run("should run every valid operation in best-effort mode", func(t *testing.T) {
+		setup(t)
+
+		results := jobs.RunBatch(context.Background(), account, []types.BatchOperation{
+			{Type: types.JobDelete, Path: "/docs/a.txt"},
+			{Type: types.JobMove, Path: "/docs/b.txt", Destination: "/archive"},
+			{Type: types.JobCopy, Path: "/docs/c.txt", Destination: "/docs"},
+			{Type: types.JobDelete, Path: "/docs/missing.txt"},
+			{Type: types.BatchDownload, Path: "/docs/c.txt"},
+		}, false)
+
+		assert.Equal(t, []string{types.BatchDone, types.BatchDone, types.BatchDone, types.BatchFailed, types.BatchDone}, statuses(results))
+		assert.Equal(t, "/archive/b.txt", results[1].Result)
+		assert.Equal(t, "/docs/c - Copy.txt", results[2].Result)
+		assert.NoFileExists(t, filepath.Join("host", "docs", "a.txt"))
+		assert.FileExists(t, filepath.Join("host", "archive", "b.txt"))
+		assert.FileExists(t, filepath.Join("host", "docs", "c - Copy.txt"))
+	})
+
+	t.Run("should fail operations that use the same items", func(t *testing.T) {
+		setup(t)
+
+		results := jobs.RunBatch(context.Background(), account, []types.BatchOperation{
+			{Type: types.JobMove, Path: "/docs", Destination: "/archive"},
+			{Type: types.JobDelete, Path: "/docs/a.txt"},
+			{Type: types.JobCopy, Path: "/archive", Destination: "/docs"},
+		}, false)
+
+		assert.Equal(t, []string{types.BatchDone, types.BatchFailed, types.BatchFailed}, statuses(results))
+		assert.FileExists(t, filepath.Join("host", "archive", "docs", "a.txt"))
+	})
+
+	t.Run("should change nothing in atomic mode if an operation fails the checks", func(t *testing.T) {
+		setup(t)
+
+		results := jobs.RunBatch(context.Background(), account, []types.BatchOperation{
+			{Type: types.JobDelete, Path: "/docs/a.txt"},
+			{Type: types.JobMove, Path: "/docs/b.txt", Destination: "/missing"},
+		}, true)
+
+		assert.Equal(t, []string{types.BatchSkipped, types.BatchFailed}, statuses(results))
+		assert.FileExists(t, filepath.Join("host", "docs", "a.txt"))
+	})
+
+	t.Run("should check the space of all copies together", func(t *testing.T) {
+		setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join("host", "big.bin"), bytes.Repeat([]byte("x"), 400), 0644))
+
+		limited := account
+		limited.Quota = "1 KB"
+		operations := []types.BatchOperation{
+			{Type: types.JobCopy, Path: "/big.bin", Destination: "/docs"},
+			{Type: types.JobCopy, Path: "/big.bin", Destination: "/archive"},
+		}
+
+		results := jobs.RunBatch(context.Background(), limited, operations, true)
+		assert.Equal(t, []string{types.BatchFailed, types.BatchFailed}, statuses(results))
+		assert.Equal(t, "Not enough space!", results[0].Error)
+
+		results = jobs.RunBatch(context.Background(), limited, operations, false)
+		assert.Equal(t, []string{types.BatchDone, types.BatchFailed}, statuses(results))
+		assert.FileExists(t, filepath.Join("host", "docs", "big.bin"))
+	})
+
+	t.Run("should fail only the copies if the space can't be checked", func(t *testing.T) {
+		setup(t)
+
+		broken := account
+		broken.Quota = "lots"
+		results := jobs.RunBatch(context.Background(), broken, []types.BatchOperation{
+			{Type: types.JobDelete, Path: "/docs/a.txt"},
+			{Type: types.JobMove, Path: "/docs/b.txt", Destination: "/archive"},
+			{Type: types.JobCopy, Path: "/docs/c.txt", Destination: "/archive"},
+		}, false)
+
+		assert.Equal(t, []string{types.BatchDone, types.BatchDone, types.BatchFailed}, statuses(results))
+		assert.Equal(t, "Cannot get remaining storage space!", results[2].Error)
+		assert.FileExists(t, filepath.Join("host", "archive", "b.txt"))
+	})
+
+	t.Run("should stop copying when the context is cancelled", func(t *testing.T) {
+		setup(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results := jobs.RunBatch(ctx, account, []types.BatchOperation{
+			{Type: types.JobCopy, Path: "/docs/c.txt", Destination: "/archive"},
+		}, false)
+
+		assert.Equal(t, []string{types.BatchFailed}, statuses(results))
+		assert.NoFileExists(t, filepath.Join("host", "archive", "c.txt"))
+	})
+}
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/utils/bruteforce"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ageAttempts moves every time of the key into the past, like if d passed since the failures.
+func ageAttempts(t *testing.T, key string, d time.Duration) {
+	attempts, ok := cache.LoginAttemptCache.Get(key)
+	require.True(t, ok, key)
+
+	for index := range attempts.Failures {
+		attempts.Failures[index] = attempts.Failures[index].Add(-d)
+	}
+	attempts.NextAttemptAt = attempts.NextAttemptAt.Add(-d)
+	attempts.LockedUntil = attempts.LockedUntil.Add(-d)
+	cache.LoginAttemptCache.Set(key, attempts, time.Hour)
+}
+
+func lockedFor(key string) time.Duration {
+	for _, lockout := range bruteforce.List() {
+		if lockout.Key == key {
+			return time.Until(lockout.LockedUntil)
+		}
+	}
+	return 0
+}
+
+func TestBruteForce(t *testing.T) {
+	oldConfig := config.Config
+	config.Config.BruteForce.Disabled = false
+	config.Config.BruteForce.MaxAttempts = 3
+	config.Config.BruteForce.MaxAttemptsPerIP = 5
+	config.Config.BruteForce.Window = 10
+	config.Config.BruteForce.LockoutDuration = 1
+	config.Config.BruteForce.MaxLockoutDuration = 3
+	t.Cleanup(func() {
+		config.Config = oldConfig
+		bruteforce.ClearAll()
+	})
+
+	t.Run("should lock at exactly the attempt threshold", func(t *testing.T) {
+		cases := []struct {
+			failures int
+			locked   bool
+		}{
+			{failures: 1, locked: false},
+			{failures: 2, locked: false},
+			{failures: 3, locked: true},
+		}
+
+		for _, tc := range cases {
+			bruteforce.ClearAll()
+			// Different IPs, so only the username counts
+			for index := range tc.failures {
+				bruteforce.RegisterFailure("10.0.0."+string(rune('1'+index)), "mert", false, "wrong password")
+			}
+
+			assert.Equal(t, tc.locked, lockedFor("user:mert") > 0, "%d failures", tc.failures)
+			// Every failure slows the next attempt down, locked or not
+			assert.Greater(t, bruteforce.Check("10.0.1.1", "mert"), time.Duration(0))
+		}
+	})
+
+	t.Run("should forget failures outside of the window", func(t *testing.T) {
+		bruteforce.ClearAll()
+		bruteforce.RegisterFailure("10.0.0.1", "mert", false, "wrong password")
+		bruteforce.RegisterFailure("10.0.0.2", "mert", false, "wrong password")
+		ageAttempts(t, "user:mert", 11*time.Minute)
+
+		assert.Equal(t, time.Duration(0), bruteforce.Check("10.0.1.1", "mert"))
+
+		bruteforce.RegisterFailure("10.0.0.3", "mert", false, "wrong password")
+		assert.Zero(t, lockedFor("user:mert"), "the old failures must not count")
+
+		attempts, _ := cache.LoginAttemptCache.Get("user:mert")
+		assert.Len(t, attempts.Failures, 1)
+	})
+
+	t.Run("should double the lockout on every repeated lockout up to the maximum", func(t *testing.T) {
+		bruteforce.ClearAll()
+		expected := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute}
+
+		for round, duration := range expected {
+			for range 3 {
+				bruteforce.RegisterFailure("10.0.0.1", "mert", false, "wrong password")
+				bruteforce.Clear("ip:10.0.0.1")
+			}
+
+			assert.InDelta(t, duration.Seconds(), lockedFor("user:mert").Seconds(), 2, "lockout %d", round+1)
+			attempts, _ := cache.LoginAttemptCache.Get("user:mert")
+			assert.Equal(t, round+1, attempts.Lockouts)
+
+			// The lockout ends, its count stays
+			ageAttempts(t, "user:mert", duration+time.Second)
+			assert.Equal(t, time.Duration(0), bruteforce.Check("10.0.1.1", "mert"))
+		}
+	})
+
+	t.Run("should count IPs and usernames separately", func(t *testing.T) {
+		bruteforce.ClearAll()
+
+		// One IP guessing many usernames locks the IP only
+		for _, username := range []string{"a", "b", "c", "d", "e"} {
+			bruteforce.RegisterFailure("10.0.0.9", username, false, "account not found")
+		}
+		assert.Greater(t, lockedFor("ip:10.0.0.9"), time.Duration(0))
+		assert.Greater(t, bruteforce.Check("10.0.0.9", "mert"), time.Duration(0))
+		assert.Equal(t, time.Duration(0), bruteforce.Check("10.0.0.10", "mert"))
+
+		// Usernames are compared without case
+		bruteforce.ClearAll()
+		for index, username := range []string{"Mert", "mert", "MERT"} {
+			bruteforce.RegisterFailure("10.0.2."+string(rune('1'+index)), username, false, "wrong password")
+		}
+		assert.Greater(t, lockedFor("user:mert"), time.Duration(0))
+		assert.Greater(t, bruteforce.Check("10.0.3.1", "mert"), time.Duration(0))
+		assert.Equal(t, time.Duration(0), bruteforce.Check("10.0.3.1", "other"))
+	})
+
+	t.Run("should reset only the username on success", func(t *testing.T) {
+		bruteforce.ClearAll()
+		bruteforce.RegisterFailure("10.0.0.1", "mert", false, "wrong password")
+		bruteforce.RegisterSuccess("mert")
+
+		_, userFound := cache.LoginAttemptCache.Get("user:mert")
+		_, ipFound := cache.LoginAttemptCache.Get("ip:10.0.0.1")
+		assert.False(t, userFound)
+		assert.True(t, ipFound)
+	})
+
+	t.Run("should clear single keys and everything", func(t *testing.T) {
+		bruteforce.ClearAll()
+		for range 3 {
+			bruteforce.RegisterFailure("10.0.0.1", "mert", false, "wrong password")
+		}
+		require.Greater(t, bruteforce.Check("10.0.5.5", "mert"), time.Duration(0))
+
+		assert.True(t, bruteforce.Clear("user:mert"))
+		assert.False(t, bruteforce.Clear("user:mert"), "a cleared key doesn't exist anymore")
+		assert.Equal(t, time.Duration(0), bruteforce.Check("10.0.5.5", "mert"))
+		assert.Greater(t, bruteforce.Check("10.0.0.1", ""), time.Duration(0))
+
+		bruteforce.ClearAll()
+		assert.Equal(t, time.Duration(0), bruteforce.Check("10.0.0.1", "mert"))
+		assert.Empty(t, bruteforce.List())
+	})
+
+	t.Run("should allow everything when disabled", func(t *testing.T) {
+		bruteforce.ClearAll()
+		config.Config.BruteForce.Disabled = true
+		defer func() { config.Config.BruteForce.Disabled = false }()
+
+		for range 5 {
+			bruteforce.RegisterFailure("10.0.0.1", "mert", false, "wrong password")
+		}
+		assert.Equal(t, time.Duration(0), bruteforce.Check("10.0.0.1", "mert"))
+	})
+}
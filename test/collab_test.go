@@ -0,0 +1,176 @@
+package test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/collab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encode(text string) []uint16 {
+	return utf16.Encode([]rune(text))
+}
+
+func decode(document []uint16) string {
+	return string(utf16.Decode(document))
+}
+
+func randomOperation(random *rand.Rand, length int) collab.Operation {
+	offset := random.Intn(length + 1)
+	deleted := random.Intn(length - offset + 1)
+	texts := []string{"", "a", "bc", "\n", "ü", "😀"}
+	return collab.Replace(length, offset, deleted, texts[random.Intn(len(texts))])
+}
+
+func TestCollabOperation(t *testing.T) {
+	t.Run("should apply operations in UTF-16 units", func(t *testing.T) {
+		document := encode("hi 😀 there")
+		result, err := collab.Apply(document, collab.Replace(len(document), 3, 2, "🙂"))
+		require.NoError(t, err)
+		assert.Equal(t, "hi 🙂 there", decode(result))
+	})
+
+	t.Run("should reject operations with the wrong length", func(t *testing.T) {
+		_, err := collab.Apply(encode("abc"), collab.Replace(5, 0, 1, "x"))
+		assert.ErrorIs(t, err, collab.ErrInvalidOperation)
+	})
+
+	t.Run("should encode operations like [retain, insert, delete]", func(t *testing.T) {
+		data, err := json.Marshal(collab.Replace(10, 2, 3, "xy"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `[2, "xy", -3, 5]`, string(data))
+
+		var op collab.Operation
+		require.NoError(t, json.Unmarshal(data, &op))
+		assert.Equal(t, collab.Replace(10, 2, 3, "xy"), op)
+
+		assert.ErrorIs(t, json.Unmarshal([]byte(`[1, 0]`), &op), collab.ErrInvalidOperation)
+		assert.ErrorIs(t, json.Unmarshal([]byte(`[1.5]`), &op), collab.ErrInvalidOperation)
+	})
+
+	t.Run("should put the first insert first on ties", func(t *testing.T) {
+		document := encode("ab")
+		a := collab.Replace(2, 1, 0, "X")
+		b := collab.Replace(2, 1, 0, "Y")
+
+		aPrime, bPrime, err := collab.Transform(a, b)
+		require.NoError(t, err)
+
+		left, _ := collab.Apply(document, a)
+		left, _ = collab.Apply(left, bPrime)
+		right, _ := collab.Apply(document, b)
+		right, _ = collab.Apply(right, aPrime)
+
+		assert.Equal(t, "aXYb", decode(left))
+		assert.Equal(t, "aXYb", decode(right))
+	})
+
+	t.Run("should keep inserts inside a concurrently deleted range", func(t *testing.T) {
+		document := encode("0123456789")
+		a := collab.Replace(10, 2, 6, "")
+		b := collab.Replace(10, 5, 0, "x")
+
+		aPrime, bPrime, err := collab.Transform(a, b)
+		require.NoError(t, err)
+
+		left, _ := collab.Apply(document, a)
+		left, _ = collab.Apply(left, bPrime)
+		right, _ := collab.Apply(document, b)
+		right, _ = collab.Apply(right, aPrime)
+
+		assert.Equal(t, "01x89", decode(left))
+		assert.Equal(t, "01x89", decode(right))
+	})
+
+	t.Run("should converge for random concurrent operations", func(t *testing.T) {
+		random := rand.New(rand.NewSource(1))
+		for i := 0; i < 2000; i++ {
+			document := encode("hello\nwörld 😀!")
+			a := randomOperation(random, len(document))
+			b := randomOperation(random, len(document))
+
+			aPrime, bPrime, err := collab.Transform(a, b)
+			require.NoError(t, err)
+
+			left, err := collab.Apply(document, a)
+			require.NoError(t, err)
+			left, err = collab.Apply(left, bPrime)
+			require.NoError(t, err)
+
+			right, err := collab.Apply(document, b)
+			require.NoError(t, err)
+			right, err = collab.Apply(right, aPrime)
+			require.NoError(t, err)
+
+			require.Equal(t, decode(left), decode(right))
+		}
+	})
+}
+
+func TestCollabDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("line 1\nline 2"), 0644))
+
+	var saved string
+	save := func(path string, content string, account types.Account) error {
+		saved = content
+		return nil
+	}
+
+	document, err := collab.Open(path, save, nil)
+	require.NoError(t, err)
+
+	account := types.Account{Username: "mert"}
+
+	t.Run("should transform changes made on older revisions", func(t *testing.T) {
+		var revisions []int
+		publish := func(op collab.Operation, revision int) {
+			revisions = append(revisions, revision)
+		}
+
+		// Both clients saw revision 0
+		require.NoError(t, document.Submit(0, collab.Replace(13, 0, 0, "// "), account, publish))
+		require.NoError(t, document.Submit(0, collab.Replace(13, 13, 0, "\nline 3"), account, publish))
+
+		content, revision := document.Snapshot()
+		assert.Equal(t, "// line 1\nline 2\nline 3", content)
+		assert.Equal(t, 2, revision)
+		assert.Equal(t, []int{1, 2}, revisions)
+	})
+
+	t.Run("should convert Monaco ranges", func(t *testing.T) {
+		op, revision := document.FromRange(types.ChangeRange{StartLineNumber: 2, StartColumn: 6, EndLineNumber: 2, EndColumn: 7}, "two")
+		require.NoError(t, document.Submit(revision, op, account, nil))
+
+		content, _ := document.Snapshot()
+		assert.Equal(t, "// line 1\nline two\nline 3", content)
+	})
+
+	t.Run("should reject unknown revisions", func(t *testing.T) {
+		_, revision := document.Snapshot()
+		assert.ErrorIs(t, document.Submit(revision+1, collab.Operation{}, account, nil), collab.ErrStaleRevision)
+	})
+
+	t.Run("should make older revisions stale after a reset", func(t *testing.T) {
+		_, revision := document.Snapshot()
+		document.Reset("new", nil)
+
+		assert.ErrorIs(t, document.Submit(revision, collab.Replace(24, 0, 0, "x"), account, nil), collab.ErrStaleRevision)
+		require.NoError(t, document.Submit(revision+1, collab.Replace(3, 3, 0, "er"), account, nil))
+	})
+
+	t.Run("should save pending changes on close", func(t *testing.T) {
+		collab.Close(path)
+		assert.Equal(t, "newer", saved)
+
+		_, ok := collab.Get(path)
+		assert.False(t, ok)
+	})
+}
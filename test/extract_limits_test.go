@@ -0,0 +1,118 @@
+package test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeZip(t *testing.T, path string, files map[string][]byte, symlinks map[string]string) {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for name, content := range files {
+		entry, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = entry.Write(content)
+		require.NoError(t, err)
+	}
+	for name, target := range symlinks {
+		header := &zip.FileHeader{Name: name}
+		header.SetMode(os.ModeSymlink | 0777)
+		entry, err := writer.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = entry.Write([]byte(target))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	require.NoError(t, os.WriteFile(path, buffer.Bytes(), 0644))
+}
+
+func TestExtractLimits(t *testing.T) {
+	t.Chdir(t.TempDir())
+	oldConfig := config.Config
+	config.Config.Folder = "./host"
+	t.Cleanup(func() { config.Config = oldConfig })
+
+	require.NoError(t, os.MkdirAll("host", 0777))
+
+	extract := func(format, src, dest string, remainingSpace int64) (string, error) {
+		var abortMsg string
+		err := utils.Extract(context.Background(), format, src, dest, remainingSpace, func(totalSize int64, isCompleted bool, message string) {
+			if message != "" {
+				abortMsg = message
+			}
+		})
+		return abortMsg, err
+	}
+
+	t.Run("should stop a highly compressed entry while it's written", func(t *testing.T) {
+		// tar.gz doesn't declare sizes before the content, so only the streaming check can catch it
+		var buffer bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buffer)
+		tarWriter := tar.NewWriter(gzipWriter)
+		size := int64(50 * 1024 * 1024)
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "zeros", Mode: 0644, Size: size, Typeflag: tar.TypeReg}))
+		_, err := tarWriter.Write(make([]byte, size))
+		require.NoError(t, err)
+		require.NoError(t, tarWriter.Close())
+		require.NoError(t, gzipWriter.Close())
+		require.NoError(t, os.WriteFile(filepath.Join("host", "bomb.tar.gz"), buffer.Bytes(), 0644))
+
+		abortMsg, err := extract(utils.ArchiveFormatTarGz, filepath.Join("host", "bomb.tar.gz"), filepath.Join("host", "bomb"), 1<<40)
+		assert.ErrorIs(t, err, utils.ErrCompressionRatio)
+		assert.NotEmpty(t, abortMsg)
+		assert.NoDirExists(t, filepath.Join("host", "bomb"))
+	})
+
+	t.Run("should reject zip files by their declared size", func(t *testing.T) {
+		writeZip(t, filepath.Join("host", "big.zip"), map[string][]byte{"big.txt": bytes.Repeat([]byte("x"), 5000)}, nil)
+
+		abortMsg, err := extract(utils.ArchiveFormatZip, filepath.Join("host", "big.zip"), filepath.Join("host", "big"), 1000)
+		assert.ErrorIs(t, err, utils.ErrExtractStorageLimit)
+		assert.Equal(t, "Unzip process exceeds storage limit!", abortMsg)
+		assert.NoDirExists(t, filepath.Join("host", "big"))
+
+		config.Config.Extraction.MaxSize = "1 KB"
+		defer func() { config.Config.Extraction.MaxSize = "" }()
+
+		_, err = extract(utils.ArchiveFormatZip, filepath.Join("host", "big.zip"), filepath.Join("host", "big"), 1<<40)
+		assert.ErrorIs(t, err, utils.ErrExtractMaxSize)
+	})
+
+	t.Run("should limit the number of entries", func(t *testing.T) {
+		files := map[string][]byte{}
+		for index := range 5 {
+			files[fmt.Sprintf("%d.txt", index)] = []byte("x")
+		}
+		writeZip(t, filepath.Join("host", "many.zip"), files, nil)
+
+		config.Config.Extraction.MaxEntries = 3
+		defer func() { config.Config.Extraction.MaxEntries = 0 }()
+
+		_, err := extract(utils.ArchiveFormatZip, filepath.Join("host", "many.zip"), filepath.Join("host", "many"), 1<<40)
+		assert.ErrorIs(t, err, utils.ErrTooManyEntries)
+		assert.NoDirExists(t, filepath.Join("host", "many"))
+	})
+
+	t.Run("should skip symlink entries", func(t *testing.T) {
+		writeZip(t, filepath.Join("host", "links.zip"), map[string][]byte{"a.txt": []byte("a")}, map[string]string{"passwd": "/etc/passwd"})
+
+		_, err := extract(utils.ArchiveFormatZip, filepath.Join("host", "links.zip"), filepath.Join("host", "links"), 1<<40)
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join("host", "links", "a.txt"))
+
+		_, err = os.Lstat(filepath.Join("host", "links", "passwd"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
@@ -0,0 +1,262 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/MertJSX/folder-host-go/database/groups"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/routes"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroups_ApplyGroups(t *testing.T) {
+	editors := types.Group{
+		Name:        "editors",
+		Scope:       "/projects",
+		Permissions: types.AccountPermissions{Change: true, Delete: true},
+		ACL:         []types.ACLRule{{Path: "/archive", Permission: types.PermissionChange, Allow: false}},
+	}
+	viewers := types.Group{
+		Name:        "viewers",
+		Scope:       "/public",
+		Permissions: types.AccountPermissions{ReadDirectories: true, ReadFiles: true},
+		ACL:         []types.ACLRule{{Path: "/drafts", Permission: types.PermissionReadFiles, Allow: false}},
+	}
+	unscoped := types.Group{Name: "auditors", Permissions: types.AccountPermissions{ReadLogs: true}}
+
+	// check is a path permission the resolved account should have, or not
+	type check struct {
+		permission string
+		path       string
+		allowed    bool
+	}
+
+	cases := []struct {
+		name        string
+		account     types.Account
+		groups      []types.Group
+		permissions map[string]bool
+		scope       string
+		checks      []check
+	}{
+		{
+			name:        "without groups the user keeps its values",
+			account:     types.Account{Scope: "/mert", Permissions: types.AccountPermissions{ReadFiles: true}},
+			permissions: map[string]bool{types.PermissionReadFiles: true, types.PermissionChange: false},
+			scope:       "/mert",
+		},
+		{
+			name:    "permissions of several groups are combined",
+			account: types.Account{Permissions: types.AccountPermissions{Rename: true}},
+			groups:  []types.Group{unscoped, editors, viewers},
+			permissions: map[string]bool{
+				types.PermissionRename:          true,
+				types.PermissionReadLogs:        true,
+				types.PermissionChange:          true,
+				types.PermissionDelete:          true,
+				types.PermissionReadDirectories: true,
+				types.PermissionReadFiles:       true,
+				types.PermissionMove:            false,
+			},
+			scope: "/projects",
+		},
+		{
+			name:   "the first group with a scope gives the default scope",
+			groups: []types.Group{unscoped, viewers},
+			scope:  "/public",
+		},
+		{
+			name:    "the scope of the user wins against groups",
+			account: types.Account{Scope: "/mert"},
+			groups:  []types.Group{editors, viewers},
+			scope:   "/mert",
+		},
+		{
+			name:   "without any scope the account has none",
+			groups: []types.Group{unscoped},
+			scope:  "",
+		},
+		{
+			name: "overrides win against the groups",
+			account: types.Account{
+				Permissions:         types.AccountPermissions{ReadFiles: true},
+				PermissionOverrides: map[string]bool{types.PermissionDelete: false, types.PermissionMove: true},
+			},
+			groups: []types.Group{editors},
+			permissions: map[string]bool{
+				types.PermissionDelete: false,
+				types.PermissionMove:   true,
+				types.PermissionChange: true,
+			},
+			scope: "/projects",
+		},
+		{
+			name: "overrides win against the user",
+			account: types.Account{
+				Permissions:         types.AccountPermissions{Delete: true},
+				PermissionOverrides: map[string]bool{types.PermissionDelete: false},
+			},
+			groups:      []types.Group{viewers},
+			permissions: map[string]bool{types.PermissionDelete: false, types.PermissionReadFiles: true},
+			scope:       "/public",
+		},
+		{
+			name:   "path rules of several groups are merged",
+			groups: []types.Group{editors, viewers},
+			checks: []check{
+				{permission: types.PermissionChange, path: "/archive/a.txt", allowed: false},
+				{permission: types.PermissionChange, path: "/notes.txt", allowed: true},
+				{permission: types.PermissionReadFiles, path: "/drafts/a.txt", allowed: false},
+				{permission: types.PermissionReadFiles, path: "/notes.txt", allowed: true},
+			},
+			scope: "/projects",
+		},
+		{
+			name: "a rule of the user wins against the group rule on the same path",
+			account: types.Account{
+				ACL: []types.ACLRule{{Path: "/archive", Permission: types.PermissionChange, Allow: true}},
+			},
+			groups: []types.Group{editors},
+			checks: []check{
+				{permission: types.PermissionChange, path: "/archive/a.txt", allowed: true},
+			},
+			scope: "/projects",
+		},
+		{
+			name: "a group rule on a deeper path wins against the user rule",
+			account: types.Account{
+				ACL: []types.ACLRule{{Path: "/", Permission: types.PermissionAll, Allow: true}},
+			},
+			groups: []types.Group{editors},
+			checks: []check{
+				{permission: types.PermissionChange, path: "/archive/a.txt", allowed: false},
+				{permission: types.PermissionChange, path: "/a.txt", allowed: true},
+			},
+			scope: "/projects",
+		},
+		{
+			name: "deny wins between conflicting groups",
+			groups: []types.Group{
+				editors,
+				{Name: "archivists", ACL: []types.ACLRule{{Path: "/archive", Permission: types.PermissionChange, Allow: true}}},
+			},
+			checks: []check{
+				{permission: types.PermissionChange, path: "/archive/a.txt", allowed: false},
+			},
+			scope: "/projects",
+		},
+		{
+			name: "group rules don't grant a permission denied by an override",
+			account: types.Account{
+				PermissionOverrides: map[string]bool{types.PermissionChange: false},
+			},
+			groups: []types.Group{editors},
+			checks: []check{
+				{permission: types.PermissionChange, path: "/notes.txt", allowed: false},
+			},
+			scope: "/projects",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			account := tc.account
+			ownPermissions, ownACL := account.Permissions, account.ACL
+			account.ApplyGroups(tc.groups)
+
+			for permission, expected := range tc.permissions {
+				value, ok := account.Permissions.Get(permission)
+				require.True(t, ok, permission)
+				assert.Equal(t, expected, value, permission)
+			}
+			assert.Equal(t, tc.scope, account.Scope)
+
+			for _, check := range tc.checks {
+				assert.Equal(t, check.allowed, acl.Allowed(account, check.permission, check.path), "%s on %s", check.permission, check.path)
+			}
+
+			assert.Equal(t, ownPermissions, account.OwnPermissions, "stored permissions should be kept")
+			assert.Equal(t, tc.account.Scope, account.OwnScope, "stored scope should be kept")
+			assert.Equal(t, ownACL, account.OwnACL, "stored rules should be kept")
+		})
+	}
+}
+
+func TestGroups_Database(t *testing.T) {
+	openTestDatabase(t)
+
+	require.NoError(t, users.CreateUser(&types.Account{
+		Username:    "mert",
+		Password:    "secret123",
+		Permissions: types.AccountPermissions{ReadFiles: true},
+	}))
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("account", types.Account{Username: "admin", Permissions: types.AccountPermissions{EditUsers: true}})
+		return c.Next()
+	})
+	app.Post("/groups", routes.CreateGroup)
+	app.Post("/groups/edit", routes.EditGroup)
+
+	status, response := postJSON(t, app, "/groups", fiber.Map{"group": fiber.Map{
+		"name":        "editors",
+		"scope":       "/projects",
+		"permissions": fiber.Map{"change": true},
+		"acl":         []fiber.Map{{"path": "archive/", "permission": types.PermissionChange, "allow": false}},
+	}})
+	require.Equal(t, 200, status, response)
+	id := int(response["group"].(map[string]any)["id"].(float64))
+
+	require.NoError(t, groups.SetUserGroups("mert", []string{"editors"}))
+
+	account, err := users.GetUserByUsername("mert")
+	require.NoError(t, err)
+	assert.Equal(t, "/projects", account.Scope)
+	assert.True(t, account.Permissions.Change)
+	assert.Empty(t, account.OwnACL)
+	assert.Equal(t, "/archive", account.ACL[0].Path, "the path should be normalized")
+	assert.False(t, acl.Allowed(account, types.PermissionChange, "/archive/a.txt"))
+	assert.True(t, acl.Allowed(account, types.PermissionChange, "/a.txt"))
+
+	t.Run("should reject invalid rules", func(t *testing.T) {
+		status, _ := postJSON(t, app, "/groups/edit", fiber.Map{"group": fiber.Map{
+			"id":   id,
+			"name": "editors",
+			"acl":  []fiber.Map{{"path": "/", "permission": "unknown", "allow": true}},
+		}})
+		assert.Equal(t, 400, status)
+	})
+
+	t.Run("should keep the rules if the request has none", func(t *testing.T) {
+		status, _ := postJSON(t, app, "/groups/edit", fiber.Map{"group": fiber.Map{"id": id, "name": "editors"}})
+		require.Equal(t, 200, status)
+
+		rules, err := groups.GetRules(id)
+		require.NoError(t, err)
+		assert.Len(t, rules, 1)
+
+		status, _ = postJSON(t, app, "/groups/edit", fiber.Map{"group": fiber.Map{"id": id, "name": "editors", "acl": []fiber.Map{}}})
+		require.Equal(t, 200, status)
+
+		rules, err = groups.GetRules(id)
+		require.NoError(t, err)
+		assert.Empty(t, rules)
+	})
+
+	t.Run("should remove the rules with the group", func(t *testing.T) {
+		require.NoError(t, groups.ReplaceRules(id, []types.ACLRule{{Path: "/archive", Permission: types.PermissionAll, Allow: false}}))
+		require.NoError(t, groups.RemoveGroup(id))
+
+		var count int
+		require.NoError(t, database.DB.QueryRow("SELECT COUNT(*) FROM group_acl_rules;").Scan(&count))
+		assert.Zero(t, count)
+		require.NoError(t, database.DB.QueryRow("SELECT COUNT(*) FROM user_group_members;").Scan(&count))
+		assert.Zero(t, count)
+	})
+}
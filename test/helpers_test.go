@@ -0,0 +1,47 @@
+package test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/database"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestDatabase replaces database.DB with an empty database in a temporary folder until the test ends.
+func openTestDatabase(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "database.db"))
+	require.NoError(t, err)
+
+	oldDB := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		db.Close()
+		database.DB = oldDB
+	})
+
+	database.CreateUsersTable()
+	database.CreateLogsTable()
+	database.CreateRecoveryTable()
+	database.Migrate()
+}
+
+// postJSON sends the body to the app and returns the status and the decoded JSON response.
+func postJSON(t *testing.T, app *fiber.App, target string, body fiber.Map) (int, fiber.Map) {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", target, strings.NewReader(string(data)))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := app.Test(req, -1)
+	require.NoError(t, err)
+
+	var response fiber.Map
+	json.NewDecoder(res.Body).Decode(&response)
+	return res.StatusCode, response
+}
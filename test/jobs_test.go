@@ -0,0 +1,119 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	jobsdb "github.com/MertJSX/folder-host-go/database/jobs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	oldFolder := config.Config.Folder
+	config.Config.Folder = "./host"
+	t.Cleanup(func() { config.Config.Folder = oldFolder })
+
+	require.NoError(t, os.MkdirAll(filepath.Join("host", "docs", "old"), 0777))
+	require.NoError(t, os.WriteFile(filepath.Join("host", "docs", "a.txt"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join("host", "docs", "b.zip"), []byte("b"), 0644))
+
+	account := types.Account{
+		Username:    "mert",
+		Permissions: types.AccountPermissions{Copy: true, Archive: true},
+	}
+
+	t.Run("should normalize paths and default to the parent folder", func(t *testing.T) {
+		job := types.Job{Type: types.JobCopy, Source: "docs//a.txt"}
+		require.NoError(t, jobs.Validate(account, &job))
+		assert.Equal(t, "/docs/a.txt", job.Source)
+		assert.Equal(t, "/docs", job.Destination)
+	})
+
+	t.Run("should reject invalid jobs", func(t *testing.T) {
+		invalid := []types.Job{
+			{Type: "format", Source: "/docs/a.txt"},
+			{Type: types.JobCopy, Source: "/"},
+			{Type: types.JobCopy, Source: "/missing.txt"},
+			{Type: types.JobCopy, Source: "/../a.txt"},
+			{Type: types.JobCopy, Source: "/docs/a.txt", Destination: "/docs/a.txt"},
+			{Type: types.JobCopy, Source: "/docs", Destination: "/docs/old"},
+			{Type: types.JobExtract, Source: "/docs/a.txt"},
+			{Type: types.JobMove, Source: "/docs/a.txt", Destination: "/docs"},
+		}
+		for _, job := range invalid {
+			assert.Error(t, jobs.Validate(account, &job), job)
+		}
+	})
+
+	t.Run("should check the permissions of the job type", func(t *testing.T) {
+		assert.True(t, jobs.Allowed(account, types.Job{Type: types.JobCopy, Source: "/docs/a.txt", Destination: "/docs"}))
+		assert.True(t, jobs.Allowed(account, types.Job{Type: types.JobArchive, Source: "/docs"}))
+		assert.False(t, jobs.Allowed(account, types.Job{Type: types.JobMove, Source: "/docs/a.txt", Destination: "/"}))
+		assert.False(t, jobs.Allowed(account, types.Job{Type: types.JobDelete, Source: "/docs/a.txt"}))
+		assert.False(t, jobs.Allowed(account, types.Job{Type: "format", Source: "/docs/a.txt"}))
+	})
+
+	t.Run("should stop zipping when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := utils.Archive(ctx, utils.ArchiveFormatZip, filepath.Join("host", "docs"), filepath.Join("host", "docs.zip"), 1<<30, func(int64, bool, string) {})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestJobs_Retry(t *testing.T) {
+	openTestDatabase(t)
+
+	job := types.Job{Type: types.JobCopy, Username: "mert", Status: types.JobFailed, Source: "/docs/a.txt", Destination: "/docs"}
+	require.NoError(t, jobsdb.CreateJob(&job))
+	job.Error = "Unknown error while copying item"
+	require.NoError(t, jobsdb.UpdateJob(job))
+
+	t.Run("should queue the job only once for concurrent retries", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errs := make(chan error, 8)
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := jobs.Retry(job.ID)
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		retried := 0
+		for err := range errs {
+			if err == nil {
+				retried++
+			} else {
+				assert.ErrorIs(t, err, jobs.ErrNotRetryable)
+			}
+		}
+		assert.Equal(t, 1, retried)
+
+		queued, err := jobsdb.GetJobByID(job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.JobQueued, queued.Status)
+		assert.Empty(t, queued.Error)
+	})
+
+	t.Run("should not retry queued or missing jobs", func(t *testing.T) {
+		_, err := jobs.Retry(job.ID)
+		assert.ErrorIs(t, err, jobs.ErrNotRetryable)
+
+		_, err = jobs.Retry(job.ID + 100)
+		assert.ErrorIs(t, err, jobs.ErrNotFound)
+	})
+}
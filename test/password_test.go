@@ -0,0 +1,88 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassword_HashAndVerify(t *testing.T) {
+	for _, algorithm := range []string{"argon2id", "bcrypt"} {
+		t.Run(algorithm, func(t *testing.T) {
+			setPasswordHashing(t, algorithm)
+
+			hash, err := password.Hash("correct horse")
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(hash, "$"), "Hash should be an encoded string with parameters")
+
+			ok, needsRehash := password.Verify("correct horse", hash)
+			assert.True(t, ok, "Correct password should verify")
+			assert.False(t, needsRehash, "Fresh hash shouldn't need a rehash")
+
+			ok, _ = password.Verify("wrong horse", hash)
+			assert.False(t, ok, "Wrong password should not verify")
+		})
+	}
+
+	t.Run("should salt every hash", func(t *testing.T) {
+		setPasswordHashing(t, "argon2id")
+
+		first, err := password.Hash("same")
+		require.NoError(t, err)
+		second, err := password.Hash("same")
+		require.NoError(t, err)
+
+		assert.NotEqual(t, first, second)
+	})
+}
+
+func TestPassword_LegacyMigration(t *testing.T) {
+	setPasswordHashing(t, "argon2id")
+
+	sum := sha256.Sum256([]byte("123"))
+	legacyHash := hex.EncodeToString(sum[:])
+
+	t.Run("should verify legacy sha256 hashes and ask for rehash", func(t *testing.T) {
+		ok, needsRehash := password.Verify("123", legacyHash)
+		assert.True(t, ok)
+		assert.True(t, needsRehash)
+	})
+
+	t.Run("should reject wrong password against legacy hash", func(t *testing.T) {
+		ok, needsRehash := password.Verify("1234", legacyHash)
+		assert.False(t, ok)
+		assert.False(t, needsRehash)
+	})
+
+	t.Run("should ask for rehash when the configured algorithm changes", func(t *testing.T) {
+		setPasswordHashing(t, "bcrypt")
+		bcryptHash, err := password.Hash("123")
+		require.NoError(t, err)
+
+		setPasswordHashing(t, "argon2id")
+		ok, needsRehash := password.Verify("123", bcryptHash)
+		assert.True(t, ok)
+		assert.True(t, needsRehash)
+	})
+
+	t.Run("should reject unknown formats", func(t *testing.T) {
+		ok, _ := password.Verify("123", "plain-text")
+		assert.False(t, ok)
+	})
+}
+
+func setPasswordHashing(t *testing.T, algorithm string) {
+	t.Helper()
+
+	previous := config.Config.PasswordHashing
+	config.Config.PasswordHashing = algorithm
+	t.Cleanup(func() {
+		config.Config.PasswordHashing = previous
+	})
+}
@@ -0,0 +1,108 @@
+package test
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	fhWS "github.com/MertJSX/folder-host-go/middleware/websocket"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/fasthttp/websocket"
+	contribws "github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type presenceMessage struct {
+	Type  string                 `json:"type"`
+	Self  string                 `json:"self"`
+	ID    string                 `json:"id"`
+	Users []types.EditorPresence `json:"users"`
+}
+
+// readPresence reads messages of the connection until one has the type.
+func readPresence(t *testing.T, conn *websocket.Conn, messageType string) presenceMessage {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err, "waiting for %s", messageType)
+
+		var message presenceMessage
+		if json.Unmarshal(data, &message) == nil && message.Type == messageType {
+			return message
+		}
+	}
+}
+
+func TestPresence_GC(t *testing.T) {
+	oldConfig := config.Config
+	oldPingInterval, oldPongWait := fhWS.PingInterval, fhWS.PongWait
+	t.Cleanup(func() {
+		config.Config = oldConfig
+		fhWS.PingInterval, fhWS.PongWait = oldPingInterval, oldPongWait
+	})
+	t.Chdir(t.TempDir())
+	config.Config.Folder = "./host"
+	config.Config.SizeBytes = 1 << 30
+	fhWS.PingInterval = 50 * time.Millisecond
+	fhWS.PongWait = 300 * time.Millisecond
+
+	require.NoError(t, os.MkdirAll("host", 0777))
+	require.NoError(t, os.WriteFile(filepath.Join("host", "notes.txt"), []byte("hello"), 0644))
+
+	account := types.Account{
+		Username:    "mert",
+		Scope:       "/",
+		Permissions: types.AccountPermissions{ReadFiles: true, Change: true},
+	}
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use("/ws", func(c *fiber.Ctx) error {
+		c.Locals("account", account)
+		return c.Next()
+	})
+	app.Get("/ws/:path", contribws.New(func(c *contribws.Conn) {
+		fhWS.HandleWebsocket(c)
+	}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.Listener(listener)
+	t.Cleanup(func() { app.Shutdown() })
+	// Open editors count against the storage limit, the next tests must not see these ones
+	t.Cleanup(func() {
+		assert.Eventually(t, func() bool { return utils.GetActiveFileCount() == 0 }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	dial := func() *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws://"+listener.Addr().String()+"/ws/notes.txt", nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+
+	// The stale client reads nothing after joining, so it never answers the pings
+	stale := dial()
+	staleID := readPresence(t, stale, "presence-roster").Self
+
+	active := dial()
+	roster := readPresence(t, active, "presence-roster")
+	require.Len(t, roster.Users, 2)
+
+	// Reading keeps the active client alive, its ping handler answers with pongs
+	leave := readPresence(t, active, "presence-leave")
+	assert.Equal(t, staleID, leave.ID)
+
+	joined := dial()
+	roster = readPresence(t, joined, "presence-roster")
+	require.Len(t, roster.Users, 2, "the stale client shouldn't be in the roster anymore")
+	for _, user := range roster.Users {
+		assert.NotEqual(t, staleID, user.ID)
+	}
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuota_GetRemainingSpace(t *testing.T) {
+	oldConfig := config.Config
+	t.Cleanup(func() { config.Config = oldConfig })
+
+	config.Config.Folder = t.TempDir()
+	config.Config.StorageLimit = ""
+	config.Config.SizeBytes = 0
+
+	require.NoError(t, os.MkdirAll(filepath.Join(config.Config.Folder, "team"), 0777))
+	require.NoError(t, os.MkdirAll(filepath.Join(config.Config.Folder, "mert"), 0777))
+	require.NoError(t, os.WriteFile(filepath.Join(config.Config.Folder, "team", "a.txt"), make([]byte, 300), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(config.Config.Folder, "mert", "b.txt"), make([]byte, 100), 0644))
+
+	t.Run("should be unlimited without quota and storage limit", func(t *testing.T) {
+		remaining, err := utils.GetRemainingSpace(types.Account{Scope: "/team"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(math.MaxInt64), remaining)
+		assert.NoError(t, utils.CheckQuota(types.Account{Scope: "/team"}, 1<<40))
+	})
+
+	t.Run("should count the usage of the scope inherited from a group", func(t *testing.T) {
+		account := types.Account{Quota: "1 KB"}
+		account.ApplyGroups([]types.Group{{Name: "team", Scope: "/team"}})
+		require.Equal(t, "/team", account.Scope)
+
+		remaining, err := utils.GetRemainingSpace(account)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1024-300), remaining, "the files of the group scope should count")
+
+		// Own scope wins against the group
+		account = types.Account{Quota: "1 KB", Scope: "/mert"}
+		account.ApplyGroups([]types.Group{{Name: "team", Scope: "/team"}})
+		remaining, err = utils.GetRemainingSpace(account)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1024-100), remaining)
+	})
+
+	t.Run("should allow exactly the remaining space", func(t *testing.T) {
+		account := types.Account{Quota: "1 KB", Scope: "/team"}
+
+		cases := []struct {
+			size     int64
+			exceeded bool
+		}{
+			{size: 723, exceeded: false},
+			{size: 724, exceeded: false},
+			{size: 725, exceeded: true},
+		}
+
+		for _, tc := range cases {
+			err := utils.CheckQuota(account, tc.size)
+			if tc.exceeded {
+				assert.ErrorIs(t, err, utils.ErrQuotaExceeded, "%d bytes", tc.size)
+			} else {
+				assert.NoError(t, err, "%d bytes", tc.size)
+			}
+		}
+	})
+
+	t.Run("should use the smaller of the quota and the storage limit", func(t *testing.T) {
+		config.Config.StorageLimit = "500 B"
+		config.Config.SizeBytes = 500
+		defer func() {
+			config.Config.StorageLimit = ""
+			config.Config.SizeBytes = 0
+		}()
+
+		remaining, err := utils.GetRemainingSpace(types.Account{Quota: "1 KB", Scope: "/team"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), remaining, "the whole folder uses 400 bytes")
+
+		remaining, err = utils.GetRemainingSpace(types.Account{Scope: "/team"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), remaining)
+	})
+}
@@ -0,0 +1,180 @@
+package test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAccessKeyID = "FHTESTKEY"
+	testSecret      = "test-secret"
+)
+
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func s3SHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func s3SigningKey(now time.Time) []byte {
+	key := s3HMAC([]byte("AWS4"+testSecret), now.Format("20060102"))
+	key = s3HMAC(key, "us-east-1")
+	key = s3HMAC(key, "s3")
+	return s3HMAC(key, "aws4_request")
+}
+
+// signS3Request signs the request like the AWS SDKs do and returns the signature.
+func signS3Request(req *http.Request, payloadHash string, now time.Time) string {
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/us-east-1/s3/aws4_request"
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	var query []string
+	for key, values := range req.URL.Query() {
+		for _, value := range values {
+			query = append(query, strings.ReplaceAll(fmt.Sprintf("%s=%s", key, value), "+", "%20"))
+		}
+	}
+	sort.Strings(query)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.Join(query, "&"),
+		fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", req.Host, payloadHash, amzDate),
+		"host;x-amz-content-sha256;x-amz-date",
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, s3SHA256(canonicalRequest)}, "\n")
+	signature := hex.EncodeToString(s3HMAC(s3SigningKey(now), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=%s",
+		testAccessKeyID, scope, signature,
+	))
+	return signature
+}
+
+// s3ChunkedBody encodes the chunks as aws-chunked, every signature continues the previous one.
+func s3ChunkedBody(chunks []string, seed string, now time.Time) string {
+	var body strings.Builder
+	previous := seed
+	for _, chunk := range append(chunks, "") {
+		stringToSign := strings.Join([]string{
+			"AWS4-HMAC-SHA256-PAYLOAD",
+			now.Format("20060102T150405Z"),
+			now.Format("20060102") + "/us-east-1/s3/aws4_request",
+			previous,
+			s3SHA256(""),
+			s3SHA256(chunk),
+		}, "\n")
+		previous = hex.EncodeToString(s3HMAC(s3SigningKey(now), stringToSign))
+		fmt.Fprintf(&body, "%x;chunk-signature=%s\r\n%s\r\n", len(chunk), previous, chunk)
+	}
+	return body.String()
+}
+
+func TestS3_Signature(t *testing.T) {
+	now := time.Now().UTC()
+
+	app := fiber.New()
+	app.All("/s3/*", func(c *fiber.Ctx) error {
+		signature, s3Err := s3.ParseSignature(c)
+		if s3Err != nil {
+			return s3.SendError(c, s3Err)
+		}
+
+		if s3Err := signature.Verify(c, testSecret, time.Now()); s3Err != nil {
+			return s3.SendError(c, s3Err)
+		}
+
+		var body io.Reader = strings.NewReader(string(c.Body()))
+		if signature.IsStreaming() {
+			body = s3.NewChunkReader(body, signature, testSecret)
+		}
+
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return c.Status(400).SendString(err.Error())
+		}
+		return c.Send(data)
+	})
+
+	request := func(req *http.Request) (int, string) {
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		responseBody, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(responseBody)
+	}
+
+	t.Run("should accept signed requests", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/s3/mert/my%20notes.txt?partNumber=1&uploadId=abc", strings.NewReader("hello"))
+		signS3Request(req, s3SHA256("hello"), now)
+
+		status, body := request(req)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "hello", body)
+	})
+
+	t.Run("should reject changed requests", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/s3/mert/notes.txt", nil)
+		signS3Request(req, s3SHA256(""), now)
+		changed := httptest.NewRequest("GET", "/s3/mert/secret.txt", nil)
+		changed.Header = req.Header
+
+		status, body := request(changed)
+		assert.Equal(t, 403, status)
+		assert.Contains(t, body, "SignatureDoesNotMatch")
+	})
+
+	t.Run("should reject old requests", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/s3/mert/notes.txt", nil)
+		signS3Request(req, s3SHA256(""), now.Add(-time.Hour))
+
+		status, body := request(req)
+		assert.Equal(t, 403, status)
+		assert.Contains(t, body, "RequestTimeTooSkewed")
+	})
+
+	t.Run("should decode signed chunks", func(t *testing.T) {
+		chunkedRequest := func(change func(string) string) *http.Request {
+			req := httptest.NewRequest("PUT", "/s3/mert/notes.txt", nil)
+			seed := signS3Request(req, s3.StreamingPayload, now)
+			body := change(s3ChunkedBody([]string{"hello ", "world"}, seed, now))
+			req.Body = io.NopCloser(strings.NewReader(body))
+			req.ContentLength = int64(len(body))
+			return req
+		}
+
+		status, body := request(chunkedRequest(func(body string) string { return body }))
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "hello world", body)
+
+		status, body = request(chunkedRequest(func(body string) string {
+			return strings.Replace(body, "world", "w0rld", 1)
+		}))
+		assert.Equal(t, 400, status)
+		assert.Equal(t, s3.ErrChunkSignature.Error(), body)
+	})
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	folder := t.TempDir()
+	oldConfig := config.Config
+	config.Config.Folder = folder
+	config.Config.Search.IndexContent = true
+	t.Cleanup(func() { config.Config = oldConfig })
+
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "docs", "old"), 0777))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "docs", "Notes.txt"), []byte("meeting at noon"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "docs", "old", "report.md"), make([]byte, 100), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "docs", ".report.md.123.part"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "photo.jpg"), []byte("meeting"), 0644))
+
+	search.Scan()
+
+	find := func(dir string, query search.Query) []string {
+		results, total, err := search.Search(dir, query, func(search.Result) bool { return true }, 0, 100)
+		require.NoError(t, err)
+		assert.Equal(t, len(results), total)
+
+		var paths []string
+		for _, result := range results {
+			paths = append(paths, result.Path)
+		}
+		return paths
+	}
+
+	t.Run("should match names in every mode", func(t *testing.T) {
+		assert.Equal(t, []string{"/docs/Notes.txt"}, find(folder, search.Query{Pattern: "notes"}))
+		assert.Equal(t, []string{"/docs/Notes.txt", "/docs/old/report.md"}, find(folder, search.Query{Pattern: "*.[mt]*", Mode: search.ModeGlob, Type: search.TypeFile}))
+		assert.Equal(t, []string{"/docs/old/report.md"}, find(folder, search.Query{Pattern: "docs/*/*.md", Mode: search.ModeGlob}))
+		assert.Equal(t, []string{"/photo.jpg"}, find(folder, search.Query{Pattern: `\.jpe?g$`, Mode: search.ModeRegex}))
+	})
+
+	t.Run("should filter by type, size and date", func(t *testing.T) {
+		assert.Equal(t, []string{"/docs", "/docs/old"}, find(folder, search.Query{Type: search.TypeDirectory}))
+		assert.Equal(t, []string{"/docs/old/report.md"}, find(folder, search.Query{Type: search.TypeFile, MinSize: 50}))
+		assert.Empty(t, find(folder, search.Query{Type: search.TypeFile, ModifiedAfter: time.Now().Add(time.Hour)}))
+	})
+
+	t.Run("should search the text of files below the directory", func(t *testing.T) {
+		results, _, err := search.Search(filepath.Join(folder, "docs"), search.Query{Pattern: "MEETING", Content: true}, func(search.Result) bool { return true }, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "/Notes.txt", results[0].Path)
+		assert.True(t, results[0].ContentMatched)
+	})
+
+	t.Run("should page and filter results", func(t *testing.T) {
+		results, total, err := search.Search(folder, search.Query{}, func(result search.Result) bool {
+			return result.Path != "/photo.jpg"
+		}, 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 4, total)
+		assert.Len(t, results, 2)
+		assert.Equal(t, "/docs/Notes.txt", results[0].Path)
+	})
+
+	t.Run("should follow refreshes", func(t *testing.T) {
+		path := filepath.Join(folder, "docs", "todo.txt")
+		require.NoError(t, os.WriteFile(path, []byte("buy milk"), 0644))
+		search.Refresh(path)
+		assert.Equal(t, []string{"/docs/todo.txt"}, find(folder, search.Query{Pattern: "milk", Content: true}))
+
+		require.NoError(t, os.RemoveAll(filepath.Join(folder, "docs")))
+		search.Refresh(filepath.Join(folder, "docs"))
+		assert.Equal(t, []string{"/photo.jpg"}, find(folder, search.Query{}))
+	})
+
+	t.Run("should reject invalid queries", func(t *testing.T) {
+		_, _, err := search.Search(folder, search.Query{Pattern: "(", Mode: search.ModeRegex}, nil, 0, 10)
+		assert.ErrorIs(t, err, search.ErrInvalidQuery)
+
+		_, _, err = search.Search(folder, search.Query{Pattern: "*", Mode: search.ModeGlob, Content: true}, nil, 0, 10)
+		assert.ErrorIs(t, err, search.ErrInvalidQuery)
+	})
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "video.mp4")
+	require.NoError(t, os.WriteFile(path, []byte("0123456789"), 0644))
+
+	app := fiber.New()
+	app.Get("/file", func(c *fiber.Ctx) error {
+		fileinfo, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		return utils.SendFile(c, path, fileinfo, utils.DispositionInline)
+	})
+
+	request := func(headers map[string]string) (int, map[string]string, string) {
+		req := httptest.NewRequest("GET", "/file", nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(res.Body)
+		responseHeaders := map[string]string{}
+		for key := range res.Header {
+			responseHeaders[key] = res.Header.Get(key)
+		}
+		return res.StatusCode, responseHeaders, string(body)
+	}
+
+	status, headers, body := request(nil)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "0123456789", body)
+	assert.Equal(t, "video/mp4", headers["Content-Type"])
+	assert.Equal(t, `inline; filename=video.mp4`, headers["Content-Disposition"])
+	etag := headers["Etag"]
+
+	t.Run("should send a single range", func(t *testing.T) {
+		status, headers, body := request(map[string]string{"Range": "bytes=2-4"})
+		assert.Equal(t, 206, status)
+		assert.Equal(t, "234", body)
+		assert.Equal(t, "bytes 2-4/10", headers["Content-Range"])
+
+		_, _, body = request(map[string]string{"Range": "bytes=-3"})
+		assert.Equal(t, "789", body)
+	})
+
+	t.Run("should send multiple ranges as multipart", func(t *testing.T) {
+		status, headers, body := request(map[string]string{"Range": "bytes=0-1,8-"})
+		assert.Equal(t, 206, status)
+		assert.True(t, strings.HasPrefix(headers["Content-Type"], "multipart/byteranges; boundary="))
+		assert.Contains(t, body, "Content-Range: bytes 0-1/10\r\nContent-Type: video/mp4\r\n\r\n01\r\n")
+		assert.Contains(t, body, "Content-Range: bytes 8-9/10")
+	})
+
+	t.Run("should reject unsatisfiable ranges", func(t *testing.T) {
+		status, headers, _ := request(map[string]string{"Range": "bytes=20-"})
+		assert.Equal(t, 416, status)
+		assert.Equal(t, "bytes */10", headers["Content-Range"])
+	})
+
+	t.Run("should answer conditional requests", func(t *testing.T) {
+		status, _, _ := request(map[string]string{"If-None-Match": etag})
+		assert.Equal(t, 304, status)
+
+		status, _, body := request(map[string]string{"Range": "bytes=2-4", "If-Range": `"old"`})
+		assert.Equal(t, 200, status, "Ranges of a changed file should send the whole file")
+		assert.Equal(t, "0123456789", body)
+
+		status, _, _ = request(map[string]string{"If-Match": `"old"`})
+		assert.Equal(t, 412, status)
+	})
+}
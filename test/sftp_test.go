@@ -0,0 +1,163 @@
+package test
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/sftpserver"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openSFTP serves the handlers of the account over an in-memory connection and returns a client for it.
+func openSFTP(t *testing.T, account types.Account) *sftp.Client {
+	serverConn, clientConn := net.Pipe()
+
+	server := sftp.NewRequestServer(serverConn, sftpserver.NewHandlers(account))
+	go server.Serve()
+
+	client, err := sftp.NewClientPipe(clientConn, clientConn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client
+}
+
+func TestSFTP(t *testing.T) {
+	openTestDatabase(t)
+	oldConfig := config.Config
+	t.Cleanup(func() { config.Config = oldConfig })
+	t.Chdir(t.TempDir())
+	config.Config.Folder = "./host"
+	config.Config.StorageLimit = ""
+	config.Config.RecoveryBin = false
+
+	for _, folder := range []string{"mert/private", "mert/readonly", "mert/locked"} {
+		require.NoError(t, os.MkdirAll(filepath.Join("host", folder), 0777))
+	}
+	for _, file := range []string{"secret.txt", "mert/notes.txt", "mert/private/a.txt", "mert/locked/keep.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join("host", file), []byte(file), 0644))
+	}
+
+	account := types.Account{
+		Username: "mert",
+		Scope:    "/mert",
+		Quota:    "1 KB",
+		Permissions: types.AccountPermissions{
+			ReadDirectories: true,
+			DownloadFiles:   true,
+			UploadFiles:     true,
+			Create:          true,
+			Delete:          true,
+			Rename:          true,
+			Move:            true,
+		},
+		ACL: []types.ACLRule{
+			{Path: "/private", Permission: types.PermissionDownloadFiles, Allow: false},
+			{Path: "/readonly", Permission: types.PermissionUploadFiles, Allow: false},
+			{Path: "/locked", Permission: types.PermissionAll, Allow: false},
+			{Path: "/locked", Permission: types.PermissionReadDirectories, Allow: true},
+		},
+	}
+	client := openSFTP(t, account)
+
+	readFile := func(name string) (string, error) {
+		file, err := client.Open(name)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+
+		content, err := io.ReadAll(file)
+		return string(content), err
+	}
+
+	writeFile := func(name string, content string) error {
+		file, err := client.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}
+
+	t.Run("should keep the session in the scope", func(t *testing.T) {
+		content, err := readFile("notes.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "mert/notes.txt", content)
+
+		for _, name := range []string{"../secret.txt", "/../secret.txt", "/secret.txt", "private/../../secret.txt"} {
+			_, err := readFile(name)
+			assert.ErrorIs(t, err, os.ErrNotExist, name)
+		}
+
+		require.NoError(t, writeFile("../escaped.txt", "x"))
+		assert.NoFileExists(t, filepath.Join("host", "escaped.txt"))
+		assert.FileExists(t, filepath.Join("host", "mert", "escaped.txt"), "../ should resolve to the scope root")
+
+		require.NoError(t, client.Rename("/escaped.txt", "/../../moved.txt"))
+		assert.NoFileExists(t, "moved.txt")
+		assert.FileExists(t, filepath.Join("host", "mert", "moved.txt"))
+		require.NoError(t, client.Remove("moved.txt"))
+
+		entries, err := client.ReadDir("/..")
+		require.NoError(t, err)
+		for _, entry := range entries {
+			assert.NotEqual(t, "secret.txt", entry.Name())
+		}
+	})
+
+	t.Run("should check the ACL of every operation", func(t *testing.T) {
+		_, err := readFile("/private/a.txt")
+		assert.ErrorIs(t, err, os.ErrPermission, "Fileread needs download_files")
+
+		assert.ErrorIs(t, writeFile("/readonly/new.txt", "x"), os.ErrPermission, "Filewrite needs upload_files")
+		assert.NoFileExists(t, filepath.Join("host", "mert", "readonly", "new.txt"))
+
+		assert.ErrorIs(t, client.Mkdir("/locked/new"), os.ErrPermission, "Mkdir needs create")
+		assert.ErrorIs(t, client.Remove("/locked/keep.txt"), os.ErrPermission, "Remove needs delete")
+		assert.ErrorIs(t, client.Rename("/locked/keep.txt", "/locked/renamed.txt"), os.ErrPermission, "Rename needs rename")
+		assert.ErrorIs(t, client.Rename("/locked/keep.txt", "/keep.txt"), os.ErrPermission, "Move needs move on the source")
+		assert.ErrorIs(t, client.Rename("/notes.txt", "/locked/notes.txt"), os.ErrPermission, "Move needs move on the target")
+		assert.FileExists(t, filepath.Join("host", "mert", "locked", "keep.txt"))
+		assert.FileExists(t, filepath.Join("host", "mert", "notes.txt"))
+
+		require.NoError(t, client.Mkdir("/new"))
+		require.NoError(t, client.Rename("/new", "/renamed"))
+		require.NoError(t, client.RemoveDirectory("/renamed"))
+	})
+
+	t.Run("should enforce the quota while writing", func(t *testing.T) {
+		used := 0
+		filepath.Walk(filepath.Join("host", "mert"), func(_ string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				used += int(info.Size())
+			}
+			return nil
+		})
+		remaining := 1024 - used
+
+		err := writeFile("/big.txt", strings.Repeat("x", remaining+1))
+		assert.Error(t, err)
+		assert.NoFileExists(t, filepath.Join("host", "mert", "big.txt"), "the temporary file should be removed")
+
+		require.NoError(t, writeFile("/big.txt", strings.Repeat("x", remaining)))
+		assert.FileExists(t, filepath.Join("host", "mert", "big.txt"))
+
+		// Replacing a file frees its old space
+		require.NoError(t, writeFile("/big.txt", strings.Repeat("y", remaining)))
+		assert.Error(t, writeFile("/other.txt", "z"))
+	})
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/acl"
+	"github.com/MertJSX/folder-host-go/database/shares"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/routes"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareLink_Expired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	assert.False(t, types.ShareLink{}.Expired(now), "Links without limits should never expire")
+	assert.True(t, types.ShareLink{ExpiresAt: &past}.Expired(now))
+	assert.False(t, types.ShareLink{ExpiresAt: &future}.Expired(now))
+	assert.False(t, types.ShareLink{MaxUses: 3, UseCount: 2}.Expired(now))
+	assert.True(t, types.ShareLink{MaxUses: 3, UseCount: 3}.Expired(now), "Used up links should expire")
+}
+
+func TestShareLink_Access(t *testing.T) {
+	openTestDatabase(t)
+	oldConfig := config.Config
+	t.Cleanup(func() { config.Config = oldConfig })
+	t.Chdir(t.TempDir())
+	config.Config.Folder = "./host"
+
+	for _, folder := range []string{"shared/docs", "shared/private"} {
+		require.NoError(t, os.MkdirAll(filepath.Join("host", folder), 0777))
+	}
+	for _, file := range []string{"x", "secret.txt", "shared/x", "shared/a.txt", "shared/denied.txt", "shared/private/b.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join("host", file), []byte(file), 0644))
+	}
+
+	require.NoError(t, users.CreateUser(&types.Account{
+		Username:    "mert",
+		Password:    "secret123",
+		Scope:       "/",
+		Permissions: types.AccountPermissions{ReadDirectories: true, DownloadFiles: true},
+	}))
+	require.NoError(t, acl.ReplaceRules("mert", []types.ACLRule{
+		{Path: "/shared/denied.txt", Permission: types.PermissionDownloadFiles, Allow: false},
+		{Path: "/shared/private", Permission: types.PermissionReadDirectories, Allow: false},
+	}))
+
+	link := types.ShareLink{Username: "mert", Path: "/shared", IsDirectory: true, Mode: types.ShareModeRead}
+	require.NoError(t, shares.CreateShareLink(&link))
+
+	app := fiber.New()
+	app.Get("/s/:token", routes.Share)
+
+	get := func(query string) (int, []byte) {
+		res, err := app.Test(httptest.NewRequest("GET", "/s/"+link.Token+query, nil), -1)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(res.Body)
+		return res.StatusCode, body
+	}
+
+	t.Run("should list only the items the owner can share", func(t *testing.T) {
+		status, body := get("")
+		require.Equal(t, 200, status, string(body))
+
+		var response struct {
+			Items []types.DirectoryItem `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(body, &response))
+
+		names := make([]string, 0, len(response.Items))
+		for _, item := range response.Items {
+			names = append(names, item.Name)
+		}
+		assert.ElementsMatch(t, []string{"a.txt", "docs", "x"}, names)
+	})
+
+	t.Run("should download allowed files", func(t *testing.T) {
+		status, body := get("?path=/a.txt")
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "shared/a.txt", string(body))
+	})
+
+	t.Run("should not leave the shared folder", func(t *testing.T) {
+		status, body := get("?path=../x")
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "shared/x", string(body), "../x should resolve inside the link")
+
+		status, _ = get("?path=../../secret.txt")
+		assert.Equal(t, 404, status)
+	})
+
+	t.Run("should hide denied files", func(t *testing.T) {
+		status, _ := get("?path=/denied.txt")
+		assert.Equal(t, 404, status)
+	})
+
+	t.Run("should hide denied folders", func(t *testing.T) {
+		status, _ := get("?path=/private")
+		assert.Equal(t, 404, status)
+	})
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeIndex(t *testing.T) {
+	folder := t.TempDir()
+	oldFolder := config.Config.Folder
+	config.Config.Folder = folder
+	t.Cleanup(func() { config.Config.Folder = oldFolder })
+
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "a", "b"), 0777))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "a", "one.txt"), make([]byte, 10), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "a", "b", "two.txt"), make([]byte, 20), 0644))
+
+	assert.Equal(t, int64(30), sizeindex.Scan())
+
+	t.Run("should return sizes of directories and files", func(t *testing.T) {
+		size, ok := sizeindex.Size(filepath.Join(folder, "a", "b"))
+		assert.True(t, ok)
+		assert.Equal(t, int64(20), size)
+
+		size, ok = sizeindex.Size(filepath.Join(folder, "a", "one.txt"))
+		assert.True(t, ok)
+		assert.Equal(t, int64(10), size)
+
+		_, ok = sizeindex.Size(filepath.Dir(folder))
+		assert.False(t, ok)
+	})
+
+	t.Run("should update parents on refresh", func(t *testing.T) {
+		path := filepath.Join(folder, "a", "b", "two.txt")
+		require.NoError(t, os.WriteFile(path, make([]byte, 50), 0644))
+		sizeindex.Refresh(path)
+
+		size, _ := sizeindex.Size(folder)
+		assert.Equal(t, int64(60), size)
+	})
+
+	t.Run("should remove deleted directories", func(t *testing.T) {
+		path := filepath.Join(folder, "a", "b")
+		require.NoError(t, os.RemoveAll(path))
+		sizeindex.Refresh(path)
+
+		size, _ := sizeindex.Size(filepath.Join(folder, "a"))
+		assert.Equal(t, int64(10), size)
+
+		_, ok := sizeindex.Size(path)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/utils/textdiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextDiff(t *testing.T) {
+	t.Run("should return nothing for the same texts", func(t *testing.T) {
+		diff, err := textdiff.Unified("a", "b", "same\n", "same\n")
+		require.NoError(t, err)
+		assert.Empty(t, diff)
+	})
+
+	t.Run("should write hunks with context", func(t *testing.T) {
+		var from, to []string
+		for i := 1; i <= 20; i++ {
+			from = append(from, fmt.Sprintf("line %d", i))
+			to = append(to, fmt.Sprintf("line %d", i))
+		}
+		to[1] = "changed 2"
+		to = append(to[:15], to[16:]...)
+		to = append(to, "line 21")
+
+		diff, err := textdiff.Unified("old.txt", "new.txt", strings.Join(from, "\n")+"\n", strings.Join(to, "\n")+"\n")
+		require.NoError(t, err)
+		assert.Equal(t, `--- old.txt
++++ new.txt
+@@ -1,5 +1,5 @@
+ line 1
+-line 2
++changed 2
+ line 3
+ line 4
+ line 5
+@@ -13,8 +13,8 @@
+ line 13
+ line 14
+ line 15
+-line 16
+ line 17
+ line 18
+ line 19
+ line 20
++line 21
+`, diff)
+	})
+
+	t.Run("should mark missing newlines and empty files", func(t *testing.T) {
+		diff, err := textdiff.Unified("a", "b", "", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "--- a\n+++ b\n@@ -0,0 +1 @@\n+hello\n\\ No newline at end of file\n", diff)
+
+		diff, err = textdiff.Unified("a", "b", "x\ny\n", "x\ny")
+		require.NoError(t, err)
+		assert.Equal(t, "--- a\n+++ b\n@@ -1,2 +1,2 @@\n x\n-y\n+y\n\\ No newline at end of file\n", diff)
+	})
+
+	t.Run("should refuse too many changes", func(t *testing.T) {
+		from := strings.Repeat("a\n", textdiff.MaxChanges)
+		to := strings.Repeat("b\n", textdiff.MaxChanges)
+
+		_, err := textdiff.Unified("a", "b", from, to)
+		assert.ErrorIs(t, err, textdiff.ErrTooManyChanges)
+	})
+}
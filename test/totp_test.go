@@ -0,0 +1,75 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/utils/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// base32 of the ASCII secret "12345678901234567890" from RFC 6238 appendix B
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTP_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8 digit codes, we use the last 6 of them
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := totp.GenerateCode(rfcSecret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, "Wrong code for time %d", unix)
+	}
+}
+
+func TestTOTP_Validate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := totp.GenerateCode(rfcSecret, now)
+	require.NoError(t, err)
+
+	t.Run("should accept current code", func(t *testing.T) {
+		step, ok := totp.Validate(rfcSecret, code, now, 0)
+		assert.True(t, ok)
+		assert.Equal(t, totp.Step(now), step)
+	})
+
+	t.Run("should accept code within skew", func(t *testing.T) {
+		_, ok := totp.Validate(rfcSecret, code, now.Add(totp.Period*time.Second), 0)
+		assert.True(t, ok)
+	})
+
+	t.Run("should reject code outside skew", func(t *testing.T) {
+		_, ok := totp.Validate(rfcSecret, code, now.Add(3*totp.Period*time.Second), 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("should reject already used step", func(t *testing.T) {
+		_, ok := totp.Validate(rfcSecret, code, now, totp.Step(now))
+		assert.False(t, ok, "Same code can't be used twice")
+	})
+
+	t.Run("should reject malformed codes", func(t *testing.T) {
+		_, ok := totp.Validate(rfcSecret, "12345", now, 0)
+		assert.False(t, ok)
+	})
+}
+
+func TestTOTP_GenerateSecretAndURI(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32, "160 bit secret should be 32 base32 characters")
+
+	uri := totp.ProvisioningURI("FolderHost", "mert", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/FolderHost:mert?"))
+	assert.Contains(t, uri, "secret="+secret)
+	assert.Contains(t, uri, "issuer=FolderHost")
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/checksum"
+	"github.com/MertJSX/folder-host-go/utils/tus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTus_ParseMetadata(t *testing.T) {
+	metadata, err := tus.ParseMetadata("filename d29ybGQudHh0,is_confidential")
+	require.NoError(t, err)
+	assert.Equal(t, "world.txt", metadata["filename"])
+	assert.Contains(t, metadata, "is_confidential")
+
+	_, err = tus.ParseMetadata("filename not-base64!")
+	assert.Error(t, err)
+}
+
+func TestTus_Append(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.Mkdir(tus.Folder, 0700))
+
+	upload := types.Upload{ID: "abc", Length: 10}
+	require.NoError(t, tus.Create(upload.ID))
+
+	offset, err := tus.Append(upload, strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), offset)
+
+	t.Run("should drop bytes after the saved offset", func(t *testing.T) {
+		upload.Offset = 3
+		offset, err := tus.Append(upload, strings.NewReader("p me!!!!!!"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), offset, "Data longer than Upload-Length should be cut")
+
+		data, err := os.ReadFile(tus.DataPath(upload.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "help me!!!", string(data))
+	})
+
+	t.Run("should move the finished upload", func(t *testing.T) {
+		placedPath, err := tus.Finish(upload, "done.txt", utils.ConflictOverwrite, checksum.Checksum{})
+		require.NoError(t, err)
+		assert.Equal(t, "done.txt", placedPath)
+		assert.FileExists(t, "done.txt")
+		assert.NoFileExists(t, tus.DataPath(upload.ID))
+	})
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/twofactor"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/routes"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/totp"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoFactorSetup(t *testing.T) {
+	openTestDatabase(t)
+	oldConfig := config.Config
+	// Wrong codes are sent on purpose, the backoff would reject the next requests
+	config.Config.BruteForce.Disabled = true
+	t.Cleanup(func() { config.Config = oldConfig })
+
+	require.NoError(t, users.CreateUser(&types.Account{Username: "mert", Password: "secret123", Scope: "/"}))
+
+	app := fiber.New()
+	app.Post("/setup", routes.TwoFactorSetup)
+	app.Post("/enable", routes.TwoFactorEnable)
+
+	credentials := func(code string) fiber.Map {
+		return fiber.Map{"username": "mert", "password": "secret123", "code": code}
+	}
+	codeFor := func(secret string) string {
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+		return code
+	}
+	recoveryCodes := func(response fiber.Map) []string {
+		var codes []string
+		for _, code := range response["recoveryCodes"].([]any) {
+			codes = append(codes, code.(string))
+		}
+		return codes
+	}
+
+	status, response := postJSON(t, app, "/setup", credentials(""))
+	require.Equal(t, 200, status, response)
+	firstSecret := response["secret"].(string)
+
+	account, err := users.GetUserByUsername("mert")
+	require.NoError(t, err)
+	assert.False(t, account.TwoFactorEnabled)
+
+	status, response = postJSON(t, app, "/enable", credentials(codeFor(firstSecret)))
+	require.Equal(t, 200, status, response)
+	firstCodes := recoveryCodes(response)
+
+	t.Run("should need the current factor to start the setup again", func(t *testing.T) {
+		status, _ := postJSON(t, app, "/setup", credentials(""))
+		assert.Equal(t, 401, status)
+	})
+
+	t.Run("should keep the active secret until the new one is confirmed", func(t *testing.T) {
+		status, response := postJSON(t, app, "/setup", credentials(firstCodes[0]))
+		require.Equal(t, 200, status, response)
+		secondSecret := response["secret"].(string)
+
+		account, err := users.GetUserByUsername("mert")
+		require.NoError(t, err)
+		assert.True(t, account.TwoFactorEnabled)
+		assert.Equal(t, firstSecret, account.TOTPSecret)
+		assert.Equal(t, secondSecret, account.TOTPPendingSecret)
+
+		// A recovery code of the old secret doesn't confirm the new one
+		status, _ = postJSON(t, app, "/enable", credentials(firstCodes[1]))
+		assert.Equal(t, 401, status)
+
+		status, response = postJSON(t, app, "/enable", credentials(codeFor(secondSecret)))
+		require.Equal(t, 200, status, response)
+		assert.Len(t, recoveryCodes(response), 10)
+
+		account, err = users.GetUserByUsername("mert")
+		require.NoError(t, err)
+		assert.True(t, account.TwoFactorEnabled)
+		assert.Equal(t, secondSecret, account.TOTPSecret)
+		assert.Empty(t, account.TOTPPendingSecret)
+
+		used, err := twofactor.UseRecoveryCode("mert", firstCodes[2])
+		require.NoError(t, err)
+		assert.False(t, used, "old recovery codes must be replaced")
+	})
+
+	t.Run("should not enable without a started setup", func(t *testing.T) {
+		status, _ := postJSON(t, app, "/enable", credentials("123456"))
+		assert.Equal(t, 400, status)
+	})
+}
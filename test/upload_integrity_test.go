@@ -0,0 +1,81 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/checksum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum(t *testing.T) {
+	data := []byte("test")
+
+	sha, err := checksum.Parse("sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08")
+	require.NoError(t, err)
+	assert.NoError(t, sha.Verify(data))
+	assert.ErrorIs(t, sha.Verify([]byte("tesT")), checksum.ErrMismatch)
+
+	crc, err := checksum.ParseTusHeader("crc32c hqBywA==")
+	require.NoError(t, err)
+	assert.NoError(t, crc.Verify(data))
+
+	empty, err := checksum.Parse("")
+	require.NoError(t, err)
+	assert.NoError(t, empty.Verify(data), "Missing checksums should match everything")
+
+	_, err = checksum.Parse("md5:098f6bcd4621d373cade4e832627b4f6")
+	assert.ErrorIs(t, err, checksum.ErrUnsupported)
+
+	_, err = checksum.Parse("sha256:abcd")
+	assert.Error(t, err, "Short sums should be rejected")
+}
+
+func TestPlaceFile(t *testing.T) {
+	dir := t.TempDir()
+	finalPath := filepath.Join(dir, "report.txt")
+	require.NoError(t, os.WriteFile(finalPath, []byte("old"), 0644))
+
+	newTempFile := func() string {
+		tempFile, err := utils.CreateTempFile(finalPath)
+		require.NoError(t, err)
+		tempFile.WriteString("new")
+		tempFile.Close()
+		return tempFile.Name()
+	}
+
+	t.Run("should fail on existing files", func(t *testing.T) {
+		tempPath := newTempFile()
+		defer os.Remove(tempPath)
+
+		_, err := utils.PlaceFile(tempPath, finalPath, utils.ConflictFail)
+		assert.ErrorIs(t, err, utils.ErrFileExists)
+
+		data, _ := os.ReadFile(finalPath)
+		assert.Equal(t, "old", string(data))
+	})
+
+	t.Run("should rename like copies", func(t *testing.T) {
+		placedPath, err := utils.PlaceFile(newTempFile(), finalPath, utils.ConflictRename)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "report (1).txt"), placedPath)
+
+		placedPath, err = utils.PlaceFile(newTempFile(), finalPath, utils.ConflictRename)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "report (2).txt"), placedPath)
+	})
+
+	t.Run("should overwrite", func(t *testing.T) {
+		_, err := utils.PlaceFile(newTempFile(), finalPath, utils.ConflictOverwrite)
+		require.NoError(t, err)
+
+		data, _ := os.ReadFile(finalPath)
+		assert.Equal(t, "new", string(data))
+	})
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 3, "No temp files should be left")
+}
@@ -0,0 +1,198 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/database"
+	versionsdb "github.com/MertJSX/folder-host-go/database/versions"
+	"github.com/MertJSX/folder-host-go/routes"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/versions"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersions(t *testing.T) {
+	openTestDatabase(t)
+	oldConfig := config.Config
+	t.Cleanup(func() { config.Config = oldConfig })
+	t.Chdir(t.TempDir())
+	config.Config.Folder = "./host"
+	config.Config.StorageLimit = ""
+	config.Config.Versioning = types.VersioningConfig{Enabled: true}
+
+	require.NoError(t, os.MkdirAll("host", 0777))
+	require.NoError(t, os.MkdirAll(versions.Folder, 0777))
+
+	filePath := filepath.Join("host", "notes.txt")
+
+	// reset starts every subtest with one file and no versions
+	reset := func(t *testing.T, content string) {
+		_, err := database.DB.Exec("DELETE FROM file_versions;")
+		require.NoError(t, err)
+		require.NoError(t, os.RemoveAll(versions.Folder))
+		require.NoError(t, os.MkdirAll(versions.Folder, 0777))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	}
+
+	// overwrite replaces the file like an upload does
+	overwrite := func(t *testing.T, content string) {
+		temp, err := utils.CreateTempFile(filePath)
+		require.NoError(t, err)
+		_, err = temp.WriteString(content)
+		require.NoError(t, err)
+		temp.Close()
+
+		_, err = versions.Replace("mert", temp.Name(), filePath, utils.ConflictOverwrite)
+		require.NoError(t, err)
+	}
+
+	contents := func(t *testing.T) []string {
+		list, err := versionsdb.GetVersionsByPath("notes.txt")
+		require.NoError(t, err)
+
+		result := make([]string, len(list))
+		for index, version := range list {
+			data, err := os.ReadFile(version.Location)
+			require.NoError(t, err)
+			result[index] = string(data)
+		}
+		return result
+	}
+
+	versionFiles := func(t *testing.T) int {
+		entries, err := os.ReadDir(versions.Folder)
+		require.NoError(t, err)
+		return len(entries)
+	}
+
+	t.Run("should keep the replaced content", func(t *testing.T) {
+		reset(t, "v1")
+		overwrite(t, "v2")
+
+		data, _ := os.ReadFile(filePath)
+		assert.Equal(t, "v2", string(data))
+		assert.Equal(t, []string{"v1"}, contents(t))
+
+		// The version is linked to the old inode, writes to the new file don't reach it
+		require.NoError(t, os.WriteFile(filePath, []byte("changed"), 0644))
+		assert.Equal(t, []string{"v1"}, contents(t))
+	})
+
+	t.Run("should not keep a version if the replace fails", func(t *testing.T) {
+		reset(t, "v1")
+
+		_, err := versions.Replace("mert", filepath.Join("host", "missing.tmp"), filePath, utils.ConflictOverwrite)
+		require.Error(t, err)
+		assert.Empty(t, contents(t))
+		assert.Zero(t, versionFiles(t), "the hard link should be removed")
+
+		// The editor writes in place, nothing may share the inode of the live file
+		file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_TRUNC, 0644)
+		require.NoError(t, err)
+		file.WriteString("edited")
+		file.Close()
+		assert.Zero(t, versionFiles(t))
+	})
+
+	t.Run("should copy the content before an editing session", func(t *testing.T) {
+		reset(t, "v1")
+
+		versions.BeforeEdit("mert", filePath)
+		file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_TRUNC, 0644)
+		require.NoError(t, err)
+		file.WriteString("v2")
+		file.Close()
+		assert.Equal(t, []string{"v1"}, contents(t), "in-place writes shouldn't change the copy")
+
+		// Later changes of the same session don't make versions
+		versions.BeforeEdit("mert", filePath)
+		assert.Equal(t, []string{"v1"}, contents(t))
+	})
+
+	t.Run("should apply the retention", func(t *testing.T) {
+		cases := []struct {
+			name       string
+			versioning types.VersioningConfig
+			expected   []string
+		}{
+			{name: "keep_last", versioning: types.VersioningConfig{Enabled: true, KeepLast: 2}, expected: []string{"v3", "v2"}},
+			{name: "storage_limit", versioning: types.VersioningConfig{Enabled: true, StorageLimit: "5 Bytes"}, expected: []string{"v3", "v2"}},
+			{name: "unlimited", versioning: types.VersioningConfig{Enabled: true}, expected: []string{"v3", "v2", "v1"}},
+		}
+
+		for _, tc := range cases {
+			config.Config.Versioning = tc.versioning
+			reset(t, "v1")
+			for index := 2; index <= 4; index++ {
+				overwrite(t, fmt.Sprintf("v%d", index))
+			}
+
+			assert.Equal(t, tc.expected, contents(t), tc.name)
+			assert.Equal(t, len(tc.expected), versionFiles(t), "%s: removed versions should be deleted from the disk", tc.name)
+		}
+		config.Config.Versioning = types.VersioningConfig{Enabled: true}
+	})
+
+	t.Run("should remove versions older than keep_days", func(t *testing.T) {
+		reset(t, "v1")
+		overwrite(t, "v2")
+		overwrite(t, "v3")
+
+		list, err := versionsdb.GetVersionsByPath("notes.txt")
+		require.NoError(t, err)
+		_, err = database.DB.Exec("UPDATE file_versions SET created_at = '2000-01-01 00:00:00' WHERE id = ?;", list[1].ID)
+		require.NoError(t, err)
+
+		config.Config.Versioning.KeepDays = 7
+		defer func() { config.Config.Versioning.KeepDays = 0 }()
+		require.NoError(t, versions.Clear())
+		assert.Equal(t, []string{"v2"}, contents(t))
+	})
+
+	t.Run("should list and restore versions", func(t *testing.T) {
+		reset(t, "v1")
+		overwrite(t, "v2")
+
+		account := types.Account{
+			Username:    "mert",
+			Scope:       "/",
+			Permissions: types.AccountPermissions{ReadFiles: true, Change: true},
+		}
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("account", account)
+			return c.Next()
+		})
+		app.Get("/versions", routes.GetVersions)
+		app.Post("/versions/restore/:id", routes.RestoreVersion)
+
+		res, err := app.Test(httptest.NewRequest("GET", "/versions?path=/notes.txt", nil), -1)
+		require.NoError(t, err)
+		require.Equal(t, 200, res.StatusCode)
+
+		var response struct {
+			Versions []types.FileVersion `json:"versions"`
+		}
+		body, _ := io.ReadAll(res.Body)
+		require.NoError(t, json.Unmarshal(body, &response))
+		require.Len(t, response.Versions, 1)
+
+		res, err = app.Test(httptest.NewRequest("POST", fmt.Sprintf("/versions/restore/%d", response.Versions[0].ID), nil), -1)
+		require.NoError(t, err)
+		require.Equal(t, 200, res.StatusCode)
+
+		data, _ := os.ReadFile(filePath)
+		assert.Equal(t, "v1", string(data))
+		assert.Equal(t, []string{"v2", "v1"}, contents(t), "the replaced content should become a version")
+	})
+}
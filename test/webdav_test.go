@@ -0,0 +1,123 @@
+package test
+
+import (
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MertJSX/folder-host-go/routes"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebDAV(t *testing.T) {
+	t.Chdir(t.TempDir())
+	oldFolder := config.Config.Folder
+	config.Config.Folder = "./host"
+	t.Cleanup(func() { config.Config.Folder = oldFolder })
+
+	require.NoError(t, os.MkdirAll(filepath.Join("host", "secret"), 0777))
+	require.NoError(t, os.MkdirAll(filepath.Join("host", "readonly"), 0777))
+
+	account := types.Account{
+		Username: "mert",
+		Permissions: types.AccountPermissions{
+			ReadDirectories: true,
+			Create:          true,
+			Delete:          true,
+			DownloadFiles:   true,
+			UploadFiles:     true,
+		},
+		ACL: []types.ACLRule{
+			{Path: "/secret", Permission: types.PermissionReadDirectories, Allow: false},
+			{Path: "/readonly", Permission: types.PermissionUploadFiles, Allow: false},
+		},
+	}
+
+	app := fiber.New(fiber.Config{
+		StreamRequestBody: true,
+		RequestMethods:    append(append([]string{}, fiber.DefaultMethods...), "PROPFIND", "MKCOL"),
+	})
+	app.Use("/dav", func(c *fiber.Ctx) error {
+		c.Locals("account", account)
+		return routes.WebDAV(c)
+	})
+
+	request := func(method string, target string, body io.Reader, headers map[string]string) (int, string) {
+		req := httptest.NewRequest(method, target, body)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		responseBody, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(responseBody)
+	}
+
+	t.Run("should put and get a file", func(t *testing.T) {
+		status, _ := request("PUT", "/dav/notes.txt", strings.NewReader("hello"), nil)
+		assert.Equal(t, 201, status)
+
+		content, err := os.ReadFile(filepath.Join("host", "notes.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(content))
+
+		status, body := request("GET", "/dav/notes.txt", nil, map[string]string{"Range": "bytes=1-3"})
+		assert.Equal(t, 206, status)
+		assert.Equal(t, "ell", body)
+	})
+
+	t.Run("should hide denied items from listings", func(t *testing.T) {
+		status, body := request("PROPFIND", "/dav/", nil, map[string]string{"Depth": "1"})
+		assert.Equal(t, 207, status)
+		assert.Contains(t, body, "/dav/readonly/")
+		assert.NotContains(t, body, "/dav/secret/")
+
+		status, _ = request("PROPFIND", "/dav/secret", nil, map[string]string{"Depth": "0"})
+		assert.Equal(t, 403, status)
+
+		status, _ = request("PROPFIND", "/dav/", nil, map[string]string{"Depth": "infinity"})
+		assert.Equal(t, 403, status)
+	})
+
+	t.Run("should check the permissions of writes", func(t *testing.T) {
+		status, _ := request("PUT", "/dav/readonly/a.txt", strings.NewReader("a"), nil)
+		assert.Equal(t, 403, status)
+		assert.NoFileExists(t, filepath.Join("host", "readonly", "a.txt"))
+
+		status, _ = request("MKCOL", "/dav/readonly/folder", nil, nil)
+		assert.Equal(t, 201, status)
+
+		status, _ = request("DELETE", "/dav/", nil, nil)
+		assert.Equal(t, 403, status)
+	})
+
+	t.Run("should reject uploads over the quota", func(t *testing.T) {
+		account.Quota = "1 KB"
+		t.Cleanup(func() { account.Quota = "" })
+
+		status, _ := request("PUT", "/dav/big.bin", strings.NewReader(strings.Repeat("a", 2048)), nil)
+		assert.Equal(t, 507, status)
+
+		// Without Content-Length the handler finds out while writing
+		req := httptest.NewRequest("PUT", "/dav/big.bin", strings.NewReader(strings.Repeat("a", 2048)))
+		req.ContentLength = -1
+		req.TransferEncoding = []string{"chunked"}
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, 507, res.StatusCode)
+
+		assert.NoFileExists(t, filepath.Join("host", "big.bin"))
+		entries, err := os.ReadDir("host")
+		require.NoError(t, err)
+		for _, entry := range entries {
+			assert.False(t, strings.HasSuffix(entry.Name(), ".part"), "temporary file %s was left", entry.Name())
+		}
+	})
+}
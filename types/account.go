@@ -5,8 +5,25 @@ type Account struct {
 	Username    string             `yaml:"username" json:"username"`
 	Email       string             `yaml:"email" json:"email"`
 	Scope       string             `yaml:"scope" json:"scope"`
+	Quota       string             `yaml:"quota" json:"quota"` // Like storage_limit, empty means unlimited
 	Password    string             `yaml:"password" json:"password"`
 	Permissions AccountPermissions `yaml:"permissions" json:"permissions"`
+	// Incremented when the account is edited, removed or its password changes. Tokens carrying an older version are rejected.
+	TokenVersion      int    `yaml:"-" json:"-"`
+	TwoFactorRequired bool   `yaml:"two_factor" json:"two_factor_required"`
+	TwoFactorEnabled  bool   `yaml:"-" json:"two_factor_enabled"`
+	TOTPSecret        string `yaml:"-" json:"-"`
+	TOTPPendingSecret string `yaml:"-" json:"-"` // Secret of a started setup, it replaces TOTPSecret when a code is confirmed
+	TOTPLastStep      int64  `yaml:"-" json:"-"`
+	// Path rules override Permissions below their path, see utils/acl
+	ACL []ACLRule `yaml:"-" json:"acl"`
+	// Groups are referenced by name. Permissions and Scope are resolved with them when the account is loaded,
+	// OwnPermissions, OwnScope and OwnACL keep the values stored on the user.
+	Groups              []string           `yaml:"groups" json:"groups"`
+	PermissionOverrides map[string]bool    `yaml:"-" json:"permission_overrides"`
+	OwnPermissions      AccountPermissions `yaml:"-" json:"-"`
+	OwnScope            string             `yaml:"-" json:"-"`
+	OwnACL              []ACLRule          `yaml:"-" json:"-"`
 }
 
 type AccountPermissions struct {
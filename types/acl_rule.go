@@ -0,0 +1,8 @@
+package types
+
+type ACLRule struct {
+	ID         int    `json:"id"`
+	Path       string `json:"path"` // Relative to the scope of the user, like "/archive"
+	Permission string `json:"permission"`
+	Allow      bool   `json:"allow"`
+}
@@ -0,0 +1,24 @@
+package types
+
+const BatchDownload = "download"
+
+const (
+	BatchDone     = "done"
+	BatchFailed   = "failed"
+	BatchSkipped  = "skipped"  // Not run because another operation of an all-or-nothing batch failed
+	BatchReverted = "reverted" // Done and undone again because another operation of an all-or-nothing batch failed
+)
+
+// BatchOperation is one item of a multi-selection. Type is JobDelete, JobMove, JobCopy or BatchDownload.
+type BatchOperation struct {
+	Type        string `json:"type"`
+	Path        string `json:"path"`
+	Destination string `json:"destination"` // Folder of moves and copies
+}
+
+type BatchResult struct {
+	BatchOperation
+	Status string `json:"status"`
+	Result string `json:"result"` // Path of the moved item or of the copy
+	Error  string `json:"err,omitempty"`
+}
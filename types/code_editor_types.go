@@ -1,9 +1,18 @@
 package types
 
+import "encoding/json"
+
 type EditorChange struct {
-	Type   string     `json:"type"`
-	Path   string     `json:"path"`
-	Change ChangeData `json:"change"`
+	Type      string          `json:"type"`
+	Path      string          `json:"path"`
+	Change    ChangeData      `json:"change"`
+	Format    string          `json:"format,omitempty"`    // Archive format of "zip" and "unzip" messages
+	Revision  *int            `json:"revision,omitempty"`  // Revision the operation is based on
+	Operation json.RawMessage `json:"operation,omitempty"` // Operation in the format of collab.Operation
+
+	// presence-update
+	Cursor     *EditorPosition `json:"cursor,omitempty"`
+	Selections []ChangeRange   `json:"selections,omitempty"`
 }
 
 type ChangeData struct {
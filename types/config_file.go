@@ -1,22 +1,186 @@
 package types
 
+import "time"
+
 type ConfigFile struct {
-	Port            int    `yaml:"port"`
-	Folder          string `yaml:"folder"`
-	StorageLimit    string `yaml:"storage_limit"`
-	SecretJwtKey    string `yaml:"secret_jwt_key"`
-	BirthDate       string `yaml:"birthDate"`
-	DateModified    string `yaml:"dateModified"`
-	Size            string `yaml:"size"`
-	SizeBytes       int64
-	AdminAccount    Account `yaml:"admin"`
-	RecoveryBin     bool    `yaml:"recovery_bin"`
-	BinStorageLimit string  `yaml:"bin_storage_limit"`
-	LogActivities   bool    `yaml:"log_activities"`
-	ClearLogsAfter  int     `yaml:"clear_logs_after"`
+	Port                       int    `yaml:"port"`
+	Folder                     string `yaml:"folder"`
+	StorageLimit               string `yaml:"storage_limit"`
+	SecretJwtKey               string `yaml:"secret_jwt_key"`
+	BirthDate                  string `yaml:"birthDate"`
+	DateModified               string `yaml:"dateModified"`
+	Size                       string `yaml:"size"`
+	SizeBytes                  int64
+	AdminAccount               Account          `yaml:"admin"`
+	RecoveryBin                bool             `yaml:"recovery_bin"`
+	BinStorageLimit            string           `yaml:"bin_storage_limit"`
+	LogActivities              bool             `yaml:"log_activities"`
+	ClearLogsAfter             int              `yaml:"clear_logs_after"`
+	PasswordHashing            string           `yaml:"password_hashing"`
+	AccessTokenTTL             int              `yaml:"access_token_lifetime"`  // Minutes
+	RefreshTokenTTL            int              `yaml:"refresh_token_lifetime"` // Days
+	BruteForce                 BruteForceConfig `yaml:"brute_force_protection"`
+	SizeIndexReconcileInterval int              `yaml:"size_index_reconcile_interval"` // Minutes
+	UploadExpiration           int              `yaml:"upload_expiration"`             // Hours
+	S3Gateway                  bool             `yaml:"s3_gateway"`
+	SFTP                       SFTPConfig       `yaml:"sftp"`
+	Search                     SearchConfig     `yaml:"search"`
+	Versioning                 VersioningConfig `yaml:"versioning"`
+	Jobs                       JobsConfig       `yaml:"jobs"`
+	Extraction                 ExtractionConfig `yaml:"extraction"`
+}
+
+type BruteForceConfig struct {
+	Disabled           bool `yaml:"disabled"`
+	MaxAttempts        int  `yaml:"max_attempts"`         // Failures per username in the window before lockout
+	MaxAttemptsPerIP   int  `yaml:"max_attempts_per_ip"`  // Failures per IP in the window before lockout
+	Window             int  `yaml:"window"`               // Minutes
+	LockoutDuration    int  `yaml:"lockout_duration"`     // Minutes, doubled on every following lockout
+	MaxLockoutDuration int  `yaml:"max_lockout_duration"` // Minutes
+}
+
+type SFTPConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Port    int    `yaml:"port"`
+	HostKey string `yaml:"host_key"` // Created on the first start if it doesn't exist
+}
+
+type SearchConfig struct {
+	IndexContent   bool `yaml:"index_content"`
+	MaxContentSize int  `yaml:"max_content_size"` // KB, bigger files are only found by name
+}
+
+type VersioningConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	KeepLast     int    `yaml:"keep_last"`     // Versions per file, 0 keeps all of them
+	KeepDays     int    `yaml:"keep_days"`     // 0 keeps them until the other limits remove them
+	StorageLimit string `yaml:"storage_limit"` // Like "5 GB", the oldest versions are removed above it
+}
+
+type JobsConfig struct {
+	Concurrency int `yaml:"concurrency"` // Jobs running at the same time
+	KeepDays    int `yaml:"keep_days"`   // Finished jobs are removed after it
+}
+
+type ExtractionConfig struct {
+	MaxEntries int    `yaml:"max_entries"`
+	MaxRatio   int    `yaml:"max_ratio"` // Extracted size divided by the archive size
+	MaxSize    string `yaml:"max_size"`  // Like "20 GB", "" only applies the storage limit and quotas
 }
 
 func (c *ConfigFile) GetScopedFolder(scope string) string {
 	// for example: ./host + /mert
 	return c.Folder + scope
 }
+
+func (c *ConfigFile) GetAccessTokenLifetime() time.Duration {
+	if c.AccessTokenTTL <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(c.AccessTokenTTL) * time.Minute
+}
+
+func (c *ConfigFile) GetRefreshTokenLifetime() time.Duration {
+	if c.RefreshTokenTTL <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(c.RefreshTokenTTL) * 24 * time.Hour
+}
+
+func (c *ConfigFile) GetSizeIndexReconcileInterval() time.Duration {
+	if c.SizeIndexReconcileInterval <= 0 {
+		return time.Hour
+	}
+	return time.Duration(c.SizeIndexReconcileInterval) * time.Minute
+}
+
+func (c *ConfigFile) GetUploadExpiration() time.Duration {
+	if c.UploadExpiration <= 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration(c.UploadExpiration) * time.Hour
+}
+
+func (b *BruteForceConfig) GetMaxAttempts() int {
+	if b.MaxAttempts <= 0 {
+		return 5
+	}
+	return b.MaxAttempts
+}
+
+func (b *BruteForceConfig) GetMaxAttemptsPerIP() int {
+	if b.MaxAttemptsPerIP <= 0 {
+		return 20
+	}
+	return b.MaxAttemptsPerIP
+}
+
+func (b *BruteForceConfig) GetWindow() time.Duration {
+	if b.Window <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(b.Window) * time.Minute
+}
+
+func (b *BruteForceConfig) GetLockoutDuration() time.Duration {
+	if b.LockoutDuration <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(b.LockoutDuration) * time.Minute
+}
+
+func (b *BruteForceConfig) GetMaxLockoutDuration() time.Duration {
+	if b.MaxLockoutDuration <= 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration(b.MaxLockoutDuration) * time.Minute
+}
+
+func (s *SFTPConfig) GetPort() int {
+	if s.Port <= 0 {
+		return 2022
+	}
+	return s.Port
+}
+
+func (s *SFTPConfig) GetHostKey() string {
+	if s.HostKey == "" {
+		return "./sftp_host_key"
+	}
+	return s.HostKey
+}
+
+func (s *SearchConfig) GetMaxContentSize() int64 {
+	if s.MaxContentSize <= 0 {
+		return 256 * 1024
+	}
+	return int64(s.MaxContentSize) * 1024
+}
+
+func (j *JobsConfig) GetConcurrency() int {
+	if j.Concurrency <= 0 {
+		return 2
+	}
+	return j.Concurrency
+}
+
+func (j *JobsConfig) GetKeepDuration() time.Duration {
+	if j.KeepDays <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return time.Duration(j.KeepDays) * 24 * time.Hour
+}
+
+func (e *ExtractionConfig) GetMaxEntries() int {
+	if e.MaxEntries <= 0 {
+		return 100000
+	}
+	return e.MaxEntries
+}
+
+func (e *ExtractionConfig) GetMaxRatio() int64 {
+	if e.MaxRatio <= 0 {
+		return 200
+	}
+	return int64(e.MaxRatio)
+}
@@ -0,0 +1,8 @@
+package types
+
+// DavCredential remembers a verified WebDAV password. Clients send it with every request and verifying
+// the password hash every time would be too slow.
+type DavCredential struct {
+	PasswordSum  [32]byte
+	TokenVersion int
+}
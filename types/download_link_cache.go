@@ -3,4 +3,13 @@ package types
 type DownloadLinkCache struct {
 	Path     string
 	Username string
+	// Archive links stream Paths as a ZIP or tar.gz named ArchiveName, Path is unused for them
+	ArchiveFormat string
+	ArchiveName   string
+	Paths         []ArchiveSource
+}
+
+type ArchiveSource struct {
+	DiskPath  string // Like "./host/mert/docs"
+	ScopePath string // Like "/docs", used for ACL checks of the entries
 }
@@ -0,0 +1,15 @@
+package types
+
+// EditorPresence is what the other clients of a file see about one connection of the code editor.
+type EditorPresence struct {
+	ID         string          `json:"id"` // One account can have several connections to the same file
+	Username   string          `json:"username"`
+	CanEdit    bool            `json:"canEdit"`
+	Cursor     *EditorPosition `json:"cursor"`
+	Selections []ChangeRange   `json:"selections"`
+}
+
+type EditorPosition struct {
+	LineNumber int `json:"lineNumber"`
+	Column     int `json:"column"`
+}
@@ -0,0 +1,67 @@
+package types
+
+type Group struct {
+	ID          *int               `json:"id,omitempty"`
+	Name        string             `json:"name"`
+	Scope       string             `json:"scope"` // Default scope of members without their own scope
+	Permissions AccountPermissions `json:"permissions"`
+	ACL         []ACLRule          `json:"acl"` // Path rules of the members, relative to their scope
+}
+
+// ApplyGroups resolves the effective permissions and scope of the account.
+// A permission is granted if the user or any of its groups grants it, then the overrides of the user win.
+// The scope of the user wins, otherwise the first group with a scope is used. groups should be sorted by name.
+// Path rules of the groups are added to the rules of the user, unless the user has a rule for the same path and permission.
+// Conflicting group rules are both kept, deny wins against allow in utils/acl.
+func (a *Account) ApplyGroups(groups []Group) {
+	a.OwnPermissions = a.Permissions
+	a.OwnScope = a.Scope
+	a.OwnACL = a.ACL
+
+	for _, permission := range AllPermissions {
+		value, _ := a.OwnPermissions.Get(permission)
+
+		for _, group := range groups {
+			if groupValue, _ := group.Permissions.Get(permission); groupValue {
+				value = true
+			}
+		}
+
+		if override, ok := a.PermissionOverrides[permission]; ok {
+			value = override
+		}
+
+		a.Permissions.Set(permission, value)
+	}
+
+	a.ACL = mergeGroupRules(a.OwnACL, groups)
+
+	if a.Scope != "" {
+		return
+	}
+
+	for _, group := range groups {
+		if group.Scope != "" {
+			a.Scope = group.Scope
+			return
+		}
+	}
+}
+
+func mergeGroupRules(rules []ACLRule, groups []Group) []ACLRule {
+	userRules := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		userRules[rule.Path+"\x00"+rule.Permission] = true
+	}
+
+	merged := append([]ACLRule{}, rules...)
+	for _, group := range groups {
+		for _, rule := range group.ACL {
+			if !userRules[rule.Path+"\x00"+rule.Permission] {
+				merged = append(merged, rule)
+			}
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,45 @@
+package types
+
+import "time"
+
+const (
+	JobArchive = "archive"
+	JobExtract = "extract"
+	JobCopy    = "copy"
+	JobMove    = "move"
+	JobDelete  = "delete"
+)
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is a long file operation that runs in the background, so it doesn't depend on the request
+// or the websocket connection that started it.
+type Job struct {
+	ID          int        `json:"id"`
+	Type        string     `json:"type"`
+	Username    string     `json:"username"`
+	Status      string     `json:"status"`
+	Source      string     `json:"source"`            // Path in the scope of the user, like "/docs/report.zip"
+	Destination string     `json:"destination"`       // Folder the result goes to, the folder of the source if it isn't given
+	Format      string     `json:"format"`            // Archive format of archive and extract jobs, like "zip" or "tar.gz"
+	Entries     []string   `json:"entries,omitempty"` // Entries an extract job takes from the archive, all of them if it's empty
+	Result      string     `json:"result"`            // Path of the created archive, folder or copy, the destination for extracted entries
+	Progress    int64      `json:"progress"`          // Bytes
+	Total       int64      `json:"total"`             // Bytes, 0 if it isn't known
+	Error       string     `json:"error"`
+	Attempts    int        `json:"attempts"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	StartedAt   *time.Time `json:"startedAt"`
+	FinishedAt  *time.Time `json:"finishedAt"`
+}
+
+// IsFinished reports whether the job won't change anymore unless it's retried.
+func (j Job) IsFinished() bool {
+	return j.Status == JobCompleted || j.Status == JobFailed || j.Status == JobCancelled
+}
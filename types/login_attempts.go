@@ -0,0 +1,19 @@
+package types
+
+import "time"
+
+type LoginAttempts struct {
+	Failures      []time.Time // Failures inside the sliding window
+	NextAttemptAt time.Time   // Progressive backoff between failures
+	LockedUntil   time.Time
+	Lockouts      int // Lockouts so far, every one doubles the next lockout duration
+}
+
+type Lockout struct {
+	Key         string    `json:"key"`
+	Type        string    `json:"type"` // "user" or "ip"
+	Value       string    `json:"value"`
+	Failures    int       `json:"failures"`
+	Lockouts    int       `json:"lockouts"`
+	LockedUntil time.Time `json:"lockedUntil"`
+}
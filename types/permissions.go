@@ -0,0 +1,105 @@
+package types
+
+// Permission names match the json names of AccountPermissions.
+const (
+	PermissionReadDirectories = "read_directories"
+	PermissionReadFiles       = "read_files"
+	PermissionCreate          = "create"
+	PermissionChange          = "change"
+	PermissionDelete          = "delete"
+	PermissionMove            = "move"
+	PermissionDownloadFiles   = "download_files"
+	PermissionUploadFiles     = "upload_files"
+	PermissionRename          = "rename"
+	PermissionExtract         = "extract"
+	PermissionArchive         = "archive"
+	PermissionCopy            = "copy"
+	PermissionReadRecovery    = "read_recovery"
+	PermissionUseRecovery     = "use_recovery"
+	PermissionReadUsers       = "read_users"
+	PermissionEditUsers       = "edit_users"
+	PermissionReadLogs        = "read_logs"
+	// PermissionAll matches every path permission in ACL rules
+	PermissionAll = "*"
+)
+
+// PathPermissions can be scoped to a path with ACL rules.
+var PathPermissions = []string{
+	PermissionReadDirectories,
+	PermissionReadFiles,
+	PermissionCreate,
+	PermissionChange,
+	PermissionDelete,
+	PermissionMove,
+	PermissionDownloadFiles,
+	PermissionUploadFiles,
+	PermissionRename,
+	PermissionExtract,
+	PermissionArchive,
+	PermissionCopy,
+}
+
+var AllPermissions = append(append([]string{}, PathPermissions...),
+	PermissionReadRecovery,
+	PermissionUseRecovery,
+	PermissionReadUsers,
+	PermissionEditUsers,
+	PermissionReadLogs,
+)
+
+// Get returns the value of a permission by name. The second value is false for unknown names.
+func (p AccountPermissions) Get(permission string) (bool, bool) {
+	if field := p.field(permission); field != nil {
+		return *field, true
+	}
+	return false, false
+}
+
+// Set changes a permission by name. It returns false for unknown names.
+func (p *AccountPermissions) Set(permission string, value bool) bool {
+	if field := p.field(permission); field != nil {
+		*field = value
+		return true
+	}
+	return false
+}
+
+func (p *AccountPermissions) field(permission string) *bool {
+	switch permission {
+	case PermissionReadDirectories:
+		return &p.ReadDirectories
+	case PermissionReadFiles:
+		return &p.ReadFiles
+	case PermissionCreate:
+		return &p.Create
+	case PermissionChange:
+		return &p.Change
+	case PermissionDelete:
+		return &p.Delete
+	case PermissionMove:
+		return &p.Move
+	case PermissionDownloadFiles:
+		return &p.DownloadFiles
+	case PermissionUploadFiles:
+		return &p.UploadFiles
+	case PermissionRename:
+		return &p.Rename
+	case PermissionExtract:
+		return &p.Extract
+	case PermissionArchive:
+		return &p.Archive
+	case PermissionCopy:
+		return &p.Copy
+	case PermissionReadRecovery:
+		return &p.ReadRecovery
+	case PermissionUseRecovery:
+		return &p.UseRecovery
+	case PermissionReadUsers:
+		return &p.ReadUsers
+	case PermissionEditUsers:
+		return &p.EditUsers
+	case PermissionReadLogs:
+		return &p.ReadLogs
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package types
+
+import "time"
+
+// S3Key is an access key of the S3 gateway. SigV4 needs the secret to verify requests, so it's stored as it is
+// and only shown when the key is created.
+type S3Key struct {
+	ID              int       `json:"id"`
+	AccessKeyID     string    `json:"accessKeyId"`
+	SecretAccessKey string    `json:"secretAccessKey,omitempty"`
+	Username        string    `json:"username"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// MultipartUpload is an unfinished S3 multipart upload, its parts wait in the uploads folder.
+type MultipartUpload struct {
+	ID        string
+	Username  string
+	Path      string // Target path in the scope of the user, like "/backups/db.tar"
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
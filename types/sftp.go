@@ -0,0 +1,13 @@
+package types
+
+import "time"
+
+// SFTPKey is a public key that can log in to the SFTP server as its user.
+type SFTPKey struct {
+	ID          int       `json:"id"`
+	Username    string    `json:"username"`
+	PublicKey   string    `json:"publicKey"`   // In authorized_keys format, without the comment
+	Fingerprint string    `json:"fingerprint"` // Like "SHA256:..."
+	Comment     string    `json:"comment"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
@@ -0,0 +1,31 @@
+package types
+
+import "time"
+
+const (
+	ShareModeRead   = "read"
+	ShareModeUpload = "upload" // File drop, visitors can upload into the folder but can't see it
+)
+
+type ShareLink struct {
+	ID           int        `json:"id"`
+	Token        string     `json:"token"`
+	Username     string     `json:"username"`
+	Path         string     `json:"path"` // Relative to the scope of the owner, resolved on every access
+	IsDirectory  bool       `json:"isDirectory"`
+	Mode         string     `json:"mode"`
+	PasswordHash string     `json:"-"`
+	HasPassword  bool       `json:"hasPassword"`
+	ExpiresAt    *time.Time `json:"expiresAt"`
+	MaxUses      int        `json:"maxUses"` // Downloads, or uploads for file drops. Zero means unlimited
+	UseCount     int        `json:"useCount"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// Expired reports whether the link can't be used anymore because of its expiry date or its use limit.
+func (s ShareLink) Expired(now time.Time) bool {
+	if s.ExpiresAt != nil && !now.Before(*s.ExpiresAt) {
+		return true
+	}
+	return s.MaxUses > 0 && s.UseCount >= s.MaxUses
+}
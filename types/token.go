@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+type TokenClaims struct {
+	Username     string
+	UserID       int
+	TokenVersion int
+}
+
+// Matches reports whether the token was issued for this exact account and hasn't been revoked since.
+// Checking the ID too makes sure a recreated user with the same name can't reuse old tokens.
+func (t TokenClaims) Matches(account Account) bool {
+	return account.ID != nil && *account.ID == t.UserID && account.TokenVersion == t.TokenVersion
+}
+
+type RefreshToken struct {
+	ID           int
+	Username     string
+	UserID       int // Usernames can be reused after a rename, the ID can't
+	FamilyID     string
+	TokenVersion int
+	Revoked      bool
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
@@ -0,0 +1,15 @@
+package types
+
+import "time"
+
+// Upload is an unfinished tus upload. Its data waits in the uploads folder until Offset reaches Length.
+type Upload struct {
+	ID        string
+	Username  string
+	Path      string // Target path in the scope of the user, like "/docs/video.mp4"
+	Length    int64
+	Offset    int64
+	Metadata  string // Raw Upload-Metadata header, returned on HEAD requests
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
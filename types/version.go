@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// FileVersion is the content a file had before it was overwritten.
+type FileVersion struct {
+	ID          int       `json:"id"`
+	Path        string    `json:"-"` // Relative to the host folder, like "mert/notes.txt"
+	Location    string    `json:"-"` // Where the content is kept, like "./versions/12_notes.txt"
+	Username    string    `json:"username"`
+	SizeDisplay string    `json:"size"`
+	SizeBytes   int64     `json:"sizeBytes"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
@@ -0,0 +1,100 @@
+package acl
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+// Allowed decides if the account has the permission on a path relative to its scope.
+// Rules are inherited down the tree. The rule with the longest matching path wins, a rule for the exact
+// permission wins against "*" on the same path and deny wins against allow. Without a matching rule,
+// the global permission of the account is used.
+func Allowed(account types.Account, permission string, targetPath string) bool {
+	allowed, ok := account.Permissions.Get(permission)
+	if !ok {
+		return false
+	}
+
+	target := NormalizePath(targetPath)
+	bestRank := -1
+
+	for _, rule := range account.ACL {
+		if rule.Permission != permission && rule.Permission != types.PermissionAll {
+			continue
+		}
+
+		rulePath := NormalizePath(rule.Path)
+		if !isInside(target, rulePath) {
+			continue
+		}
+
+		rank := len(rulePath) * 4
+		if rule.Permission == permission {
+			rank += 2
+		}
+		if !rule.Allow {
+			rank++
+		}
+
+		if rank > bestRank {
+			bestRank = rank
+			allowed = rule.Allow
+		}
+	}
+
+	return allowed
+}
+
+// Effective returns every path permission of the account on the path, like AccountPermissions but resolved.
+func Effective(account types.Account, targetPath string) map[string]bool {
+	permissions := make(map[string]bool, len(types.PathPermissions))
+	for _, permission := range types.PathPermissions {
+		permissions[permission] = Allowed(account, permission, targetPath)
+	}
+	return permissions
+}
+
+// NormalizePath converts paths like "./archive/", "archive" and "/archive" to "/archive".
+func NormalizePath(p string) string {
+	p = strings.ReplaceAll(p, "\\", "/")
+	return path.Clean("/" + strings.TrimPrefix(p, "."))
+}
+
+// ValidateRules normalizes the paths and checks the permission names.
+func ValidateRules(rules []types.ACLRule) ([]types.ACLRule, error) {
+	validated := make([]types.ACLRule, 0, len(rules))
+	seen := make(map[string]bool, len(rules))
+
+	for _, rule := range rules {
+		if rule.Permission != types.PermissionAll && !slices.Contains(types.PathPermissions, rule.Permission) {
+			return nil, fmt.Errorf("unknown permission %q", rule.Permission)
+		}
+
+		if strings.Contains(rule.Path, "..") {
+			return nil, fmt.Errorf("invalid path %q", rule.Path)
+		}
+
+		rule.Path = NormalizePath(rule.Path)
+
+		key := rule.Path + "\x00" + rule.Permission
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate rule for %s on %s", rule.Permission, rule.Path)
+		}
+		seen[key] = true
+
+		validated = append(validated, rule)
+	}
+
+	return validated, nil
+}
+
+func isInside(target string, rulePath string) bool {
+	if rulePath == "/" || target == rulePath {
+		return true
+	}
+	return strings.HasPrefix(target, rulePath+"/")
+}
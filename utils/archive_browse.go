@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	pathpkg "path"
+	"sort"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+)
+
+var (
+	ErrEntryNotFound    = errors.New("archive entry not found")
+	ErrEntryIsDirectory = errors.New("archive entry is a directory")
+	ErrEntryTooLarge    = errors.New("archive entry is too large")
+)
+
+// ListArchive returns the entries of the archive like the items of a folder, Path is the path inside the
+// archive like "/release/bin/tool". Folders that only exist as the parent of entries are added too.
+// Links and devices are left out, Extract skips them.
+func ListArchive(format, src string) ([]types.DirectoryItem, error) {
+	r, err := openArchive(format, src)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	items := map[string]*types.DirectoryItem{}
+	maxEntries := config.Config.Extraction.GetMaxEntries()
+
+	var addFolder func(name string, modTime time.Time)
+	addFolder = func(name string, modTime time.Time) {
+		if name == "" || name == "." {
+			return
+		}
+		if item, ok := items[name]; ok {
+			if item.DateModified.IsZero() {
+				item.DateModified = modTime
+			}
+			return
+		}
+		items[name] = newArchiveItem(name, true, 0, modTime)
+		addFolder(pathpkg.Dir(name), time.Time{})
+	}
+
+	for count := 1; ; count++ {
+		entry, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if count > maxEntries {
+			return nil, ErrTooManyEntries
+		}
+
+		name := CleanEntryName(entry.name)
+		switch {
+		case name == "":
+			continue
+		case entry.mode.IsDir():
+			addFolder(name, entry.modTime)
+		case entry.mode.IsRegular():
+			items[name] = newArchiveItem(name, false, entry.size, entry.modTime)
+			addFolder(pathpkg.Dir(name), time.Time{})
+		}
+	}
+
+	list := make([]types.DirectoryItem, 0, len(items))
+	for _, item := range items {
+		list = append(list, *item)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Path < list[j].Path
+	})
+	for index := range list {
+		list[index].Id = index
+	}
+
+	return list, nil
+}
+
+func newArchiveItem(name string, isDirectory bool, size int64, modTime time.Time) *types.DirectoryItem {
+	parentPath := pathpkg.Dir("/" + name)
+	if parentPath != "/" {
+		parentPath += "/"
+	}
+
+	return &types.DirectoryItem{
+		Name:         pathpkg.Base(name),
+		ParentPath:   parentPath,
+		Path:         "/" + name,
+		IsDirectory:  isDirectory,
+		DateModified: modTime,
+		Size:         ConvertBytesToString(size),
+		SizeBytes:    size,
+	}
+}
+
+// ReadArchiveEntry returns the content of a file in the archive if it isn't bigger than maxSize.
+func ReadArchiveEntry(format, src, name string, maxSize int64) ([]byte, error) {
+	r, err := openArchive(format, src)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	name = CleanEntryName(name)
+	maxEntries := config.Config.Extraction.GetMaxEntries()
+
+	for count := 1; count <= maxEntries; count++ {
+		entry, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entryName := CleanEntryName(entry.name)
+		if entry.mode.IsDir() && entryName == name {
+			return nil, ErrEntryIsDirectory
+		}
+		if entryName != name || !entry.mode.IsRegular() {
+			continue
+		}
+
+		if entry.size > maxSize {
+			return nil, ErrEntryTooLarge
+		}
+
+		rc, err := entry.open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+
+		// The declared size can be wrong
+		content, err := io.ReadAll(io.LimitReader(rc, maxSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(content)) > maxSize {
+			return nil, ErrEntryTooLarge
+		}
+		return content, nil
+	}
+
+	return nil, ErrEntryNotFound
+}
@@ -0,0 +1,368 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	ArchiveFormatZip    = "zip"
+	ArchiveFormatTar    = "tar"
+	ArchiveFormatTarGz  = "tar.gz"
+	ArchiveFormatTarBz2 = "tar.bz2"
+	ArchiveFormatTarXz  = "tar.xz"
+	ArchiveFormatTarZst = "tar.zst"
+)
+
+var ErrUnsupportedArchive = errors.New("unsupported archive format")
+
+type archiveFormat struct {
+	// The first extension is used for created archives
+	extensions []string
+	// decompress and compress wrap the tar stream, they are nil for zip and plain tar.
+	// compress is also nil if the format can only be extracted.
+	decompress func(r io.Reader) (io.ReadCloser, error)
+	compress   func(w io.Writer) (io.WriteCloser, error)
+}
+
+var archiveFormats = map[string]archiveFormat{
+	ArchiveFormatZip: {extensions: []string{".zip"}},
+	ArchiveFormatTar: {extensions: []string{".tar"}},
+	ArchiveFormatTarGz: {
+		extensions: []string{".tar.gz", ".tgz"},
+		decompress: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		compress: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+	},
+	ArchiveFormatTarBz2: {
+		// The standard library has no bzip2 writer, so these archives can only be extracted
+		extensions: []string{".tar.bz2", ".tbz2", ".tbz"},
+		decompress: func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(bzip2.NewReader(r)), nil
+		},
+	},
+	ArchiveFormatTarXz: {
+		extensions: []string{".tar.xz", ".txz"},
+		decompress: func(r io.Reader) (io.ReadCloser, error) {
+			reader, err := xz.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return io.NopCloser(reader), nil
+		},
+		compress: func(w io.Writer) (io.WriteCloser, error) {
+			return xz.NewWriter(w)
+		},
+	},
+	ArchiveFormatTarZst: {
+		extensions: []string{".tar.zst", ".tzst"},
+		decompress: func(r io.Reader) (io.ReadCloser, error) {
+			decoder, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return decoder.IOReadCloser(), nil
+		},
+		compress: func(w io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(w)
+		},
+	},
+}
+
+// DetectArchiveFormat returns the format of the archive from the extension of its name.
+func DetectArchiveFormat(name string) (string, bool) {
+	name = strings.ToLower(name)
+
+	// Longest extension first, so "x.tar.gz" isn't detected as a tar
+	format, longest := "", 0
+	for formatName, archiveFormat := range archiveFormats {
+		for _, extension := range archiveFormat.extensions {
+			if strings.HasSuffix(name, extension) && len(extension) > longest {
+				format, longest = formatName, len(extension)
+			}
+		}
+	}
+
+	return format, format != ""
+}
+
+// IsArchiveFormat reports whether archives of the format can be extracted.
+func IsArchiveFormat(format string) bool {
+	_, ok := archiveFormats[format]
+	return ok
+}
+
+// CanCreateArchive reports whether archives of the format can be created, not only extracted.
+func CanCreateArchive(format string) bool {
+	archiveFormat, ok := archiveFormats[format]
+	return ok && (format == ArchiveFormatZip || format == ArchiveFormatTar || archiveFormat.compress != nil)
+}
+
+// ArchiveExtension returns the extension of created archives, like ".tar.gz".
+func ArchiveExtension(format string) string {
+	archiveFormat, ok := archiveFormats[format]
+	if !ok {
+		return ""
+	}
+	return archiveFormat.extensions[0]
+}
+
+// archiveEntry is a file, folder or link of an archive. open is only valid until the next entry is read.
+type archiveEntry struct {
+	name    string
+	mode    os.FileMode
+	size    int64
+	modTime time.Time
+	open    func() (io.ReadCloser, error)
+}
+
+type archiveReader interface {
+	// next returns io.EOF after the last entry
+	next() (*archiveEntry, error)
+	// consumed is how many bytes of the archive file were read, used for the compression ratio
+	consumed() int64
+	Close() error
+}
+
+func openArchive(format string, src string) (archiveReader, error) {
+	archiveFormat, ok := archiveFormats[format]
+	if !ok {
+		return nil, ErrUnsupportedArchive
+	}
+
+	if format == ArchiveFormatZip {
+		reader, err := zip.OpenReader(src)
+		if err != nil {
+			return nil, err
+		}
+		return &zipArchiveReader{reader: reader}, nil
+	}
+
+	file, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &tarArchiveReader{file: file}
+	var stream io.Reader = &countingReader{reader: file, count: &reader.read}
+
+	if archiveFormat.decompress != nil {
+		reader.decompressor, err = archiveFormat.decompress(stream)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("cannot read %s archive: %v", format, err)
+		}
+		stream = reader.decompressor
+	}
+
+	reader.reader = tar.NewReader(stream)
+	return reader, nil
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  *int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	*r.count += int64(n)
+	return n, err
+}
+
+type zipArchiveReader struct {
+	reader     *zip.ReadCloser
+	index      int
+	compressed int64
+}
+
+func (r *zipArchiveReader) next() (*archiveEntry, error) {
+	if r.index >= len(r.reader.File) {
+		return nil, io.EOF
+	}
+	file := r.reader.File[r.index]
+	r.index++
+	r.compressed += int64(file.CompressedSize64)
+
+	return &archiveEntry{
+		name:    file.Name,
+		mode:    file.Mode(),
+		size:    int64(file.UncompressedSize64),
+		modTime: file.Modified,
+		open:    file.Open,
+	}, nil
+}
+
+func (r *zipArchiveReader) consumed() int64 {
+	return r.compressed
+}
+
+func (r *zipArchiveReader) Close() error {
+	return r.reader.Close()
+}
+
+type tarArchiveReader struct {
+	file         *os.File
+	read         int64
+	decompressor io.ReadCloser
+	reader       *tar.Reader
+}
+
+func (r *tarArchiveReader) next() (*archiveEntry, error) {
+	for {
+		header, err := r.reader.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		mode := header.FileInfo().Mode()
+
+		switch header.Typeflag {
+		case tar.TypeReg, tar.TypeDir, tar.TypeSymlink, tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+		case tar.TypeXGlobalHeader:
+			continue // Only metadata, the other pax and GNU headers are handled by archive/tar
+		case tar.TypeLink:
+			mode |= os.ModeSymlink // Hard links are links to another entry, they are skipped like symlinks
+		default:
+			mode |= os.ModeIrregular
+		}
+
+		return &archiveEntry{
+			name:    header.Name,
+			mode:    mode,
+			size:    header.Size,
+			modTime: header.ModTime,
+			open: func() (io.ReadCloser, error) {
+				return io.NopCloser(r.reader), nil
+			},
+		}, nil
+	}
+}
+
+func (r *tarArchiveReader) consumed() int64 {
+	return r.read
+}
+
+func (r *tarArchiveReader) Close() error {
+	if r.decompressor != nil {
+		r.decompressor.Close()
+	}
+	return r.file.Close()
+}
+
+type archiveWriter interface {
+	// add writes an entry, content is nil for folders
+	add(archivePath string, info os.FileInfo, content io.Reader) (int64, error)
+	Close() error
+}
+
+func newArchiveWriter(format string, w io.Writer) (archiveWriter, error) {
+	if !CanCreateArchive(format) {
+		return nil, ErrUnsupportedArchive
+	}
+
+	if format == ArchiveFormatZip {
+		return &zipArchiveWriter{writer: zip.NewWriter(w)}, nil
+	}
+
+	writer := &tarArchiveWriter{}
+	if compress := archiveFormats[format].compress; compress != nil {
+		compressor, err := compress(w)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create %s archive: %v", format, err)
+		}
+		writer.compressor = compressor
+		w = compressor
+	}
+	writer.writer = tar.NewWriter(w)
+
+	return writer, nil
+}
+
+type zipArchiveWriter struct {
+	writer *zip.Writer
+}
+
+func (w *zipArchiveWriter) add(archivePath string, info os.FileInfo, content io.Reader) (int64, error) {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return 0, fmt.Errorf("cannot create zip header: %v", err)
+	}
+
+	header.Name = archivePath
+
+	if info.IsDir() {
+		header.Name += "/"
+	} else {
+		header.Method = zip.Deflate
+	}
+
+	writer, err := w.writer.CreateHeader(header)
+	if err != nil {
+		return 0, fmt.Errorf("cannot create zip entry: %v", err)
+	}
+
+	if info.IsDir() {
+		return 0, nil
+	}
+
+	return io.Copy(writer, content)
+}
+
+func (w *zipArchiveWriter) Close() error {
+	return w.writer.Close()
+}
+
+type tarArchiveWriter struct {
+	compressor io.WriteCloser
+	writer     *tar.Writer
+}
+
+func (w *tarArchiveWriter) add(archivePath string, info os.FileInfo, content io.Reader) (int64, error) {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return 0, fmt.Errorf("cannot create tar header: %v", err)
+	}
+
+	header.Name = archivePath
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if err := w.writer.WriteHeader(header); err != nil {
+		return 0, fmt.Errorf("cannot create tar entry: %v", err)
+	}
+
+	if info.IsDir() {
+		return 0, nil
+	}
+
+	// The header has the size from the walk, a file growing meanwhile must not write more
+	return io.Copy(w.writer, io.LimitReader(content, header.Size))
+}
+
+// Close closes the compressor even if the tar writer fails, zstd keeps goroutines until it's closed.
+// The first error is returned.
+func (w *tarArchiveWriter) Close() error {
+	err := w.writer.Close()
+	if w.compressor != nil {
+		if compressorErr := w.compressor.Close(); err == nil {
+			err = compressorErr
+		}
+	}
+	return err
+}
@@ -2,20 +2,144 @@ package utils
 
 import (
 	"archive/zip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	pathpkg "path"
 	"path/filepath"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
 )
 
-func Unzip(src, dest string, cb func(int64, bool, string)) error {
-	r, err := zip.OpenReader(src)
+// contextReader stops a copy in the middle of a large file when the context is cancelled.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// Errors of the extraction limits. Extract removes dest and sends their abort message through the progress callback.
+var (
+	ErrExtractStorageLimit = errors.New("extraction exceeds the storage limit")
+	ErrExtractMaxSize      = errors.New("extraction exceeds the max size")
+	ErrTooManyEntries      = errors.New("archive has too many entries")
+	ErrCompressionRatio    = errors.New("archive exceeds the max compression ratio")
+)
+
+var extractAbortMessages = map[error]string{
+	ErrExtractStorageLimit: "Unzip process exceeds storage limit!",
+	ErrExtractMaxSize:      "The archive is bigger than the max extraction size!",
+	ErrTooManyEntries:      "The archive has too many files!",
+	ErrCompressionRatio:    "The archive is compressed too well, it could be a zip bomb!",
+}
+
+// ratioCheckMinSize is the extracted size the compression ratio is checked from. Small archives can have
+// any ratio, a few MB of zeros compress to almost nothing.
+const ratioCheckMinSize = 10 * 1024 * 1024
+
+// extractBudget counts the extracted bytes while they are written, so a single entry can't go over the limits.
+type extractBudget struct {
+	archive   archiveReader
+	limit     int64
+	limitErr  error
+	maxRatio  int64
+	totalSize int64
+}
+
+func (b *extractBudget) reserve(size int64) error {
+	if size > b.limit-b.totalSize {
+		return b.limitErr
+	}
+	b.totalSize += size
+
+	if b.totalSize > ratioCheckMinSize && b.totalSize/b.maxRatio > b.archive.consumed() {
+		return ErrCompressionRatio
+	}
+	return nil
+}
+
+// budgetWriter reserves every write before it's done.
+type budgetWriter struct {
+	writer io.Writer
+	budget *extractBudget
+}
+
+func (w budgetWriter) Write(p []byte) (int, error) {
+	if err := w.budget.reserve(int64(len(p))); err != nil {
+		return 0, err
+	}
+	return w.writer.Write(p)
+}
+
+// Extract unpacks the archive of the format into dest. remainingSpace is the result of GetRemainingSpace
+// for the account that started the process. Cancelling ctx stops the process, the partly extracted folder
+// is left for the caller. Links and devices are skipped, they could point out of the scope.
+func Extract(ctx context.Context, format, src, dest string, remainingSpace int64, cb func(int64, bool, string)) error {
+	return ExtractEntries(ctx, format, src, dest, nil, remainingSpace, cb)
+}
+
+// ExtractEntries unpacks only the selected entries of the archive into dest, nil selects all of them. Selected
+// folders are extracted with everything in them. Each selected entry is put in dest with its own name, like
+// "release/bin" to dest/bin, ExtractedPaths returns these paths.
+func ExtractEntries(ctx context.Context, format, src, dest string, entries []string, remainingSpace int64, cb func(int64, bool, string)) error {
+	r, err := openArchive(format, src)
 	if err != nil {
-		return fmt.Errorf("cannot open zip file: %v", err)
+		return fmt.Errorf("cannot open archive: %v", err)
 	}
 	defer r.Close()
 
+	budget := &extractBudget{
+		archive:  r,
+		limit:    remainingSpace,
+		limitErr: ErrExtractStorageLimit,
+		maxRatio: config.Config.Extraction.GetMaxRatio(),
+	}
+	if maxSize, err := ParseQuota(config.Config.Extraction.MaxSize); err != nil {
+		log.Printf("Extraction max_size is ignored: %v\n", err)
+	} else if maxSize > 0 && maxSize < budget.limit {
+		budget.limit = maxSize
+		budget.limitErr = ErrExtractMaxSize
+	}
+
+	// Only the created items are removed when a limit is reached, dest can have other items with a selection
+	removeExtracted := func() error {
+		if entries == nil {
+			return os.RemoveAll(dest)
+		}
+		for _, path := range ExtractedPaths(dest, entries) {
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Zip files declare their size, most archives over the limits don't need to be read at all
+	if declaredSize, known, err := ArchiveTotalSize(format, src); err == nil && known && entries == nil {
+		var limitErr error
+		if info, err := os.Stat(src); err == nil && declaredSize > ratioCheckMinSize && declaredSize/budget.maxRatio > info.Size() {
+			limitErr = ErrCompressionRatio
+		}
+		if declaredSize > budget.limit {
+			limitErr = budget.limitErr
+		}
+		if limitErr != nil {
+			cb(0, false, extractAbortMessages[limitErr])
+			return limitErr
+		}
+	}
+
 	err = os.MkdirAll(dest, 0777)
 
 	if err != nil {
@@ -23,48 +147,107 @@ func Unzip(src, dest string, cb func(int64, bool, string)) error {
 	}
 
 	var (
-		totalSize  int64 = 0
-		currentUID int   = os.Getuid()
-		currentGID int   = os.Getgid()
+		count      int = 0
+		maxEntries int = config.Config.Extraction.GetMaxEntries()
+		currentUID int = os.Getuid()
+		currentGID int = os.Getgid()
 	)
 
-	remainingFolderSpace, err := GetRemainingFolderSpace()
-
-	if err != nil {
-		return fmt.Errorf("cannot get remaining folder space: %v", err)
-	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	for _, file := range r.File {
-		cb(totalSize, false, "") // Parameters: totalSize, isCompleted, abortMsg
-		err := extractFile(file, dest, &totalSize, currentUID, currentGID)
+		entry, err := r.next()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			log.Printf("Unzip error: %v\n", err)
-			return fmt.Errorf("unable to extract file (%s): %v", file.Name, err)
+			cb(budget.totalSize, false, "The archive is damaged!")
+			return fmt.Errorf("cannot read archive: %v", err)
 		}
-		if totalSize > remainingFolderSpace {
-			err := os.RemoveAll(dest)
-			if err != nil {
-				cb(totalSize, false, "Unzip process exceeds storage limit! Error while deleting the extracted folder.")
-				return fmt.Errorf("unzip process exceeds storage limit")
+
+		count++
+		if count > maxEntries {
+			err = ErrTooManyEntries
+		} else if entries != nil && !selectEntry(entry, entries) {
+			continue
+		} else {
+			cb(budget.totalSize, false, "") // Parameters: totalSize, isCompleted, abortMsg
+			err = extractFile(ctx, entry, dest, budget, currentUID, currentGID)
+		}
+
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		if message, ok := extractAbortMessages[err]; ok {
+			if removeErr := removeExtracted(); removeErr != nil {
+				message += " Error while deleting the extracted folder."
 			}
-			cb(totalSize, false, "Unzip process exceeds storage limit!")
-			return fmt.Errorf("unzip process exceeds storage limit")
+			cb(budget.totalSize, false, message)
+			return err
+		}
+		if err != nil {
+			log.Printf("Extract error: %v\n", err)
+			return fmt.Errorf("unable to extract file (%s): %v", entry.name, err)
 		}
 	}
 
-	cb(totalSize, true, "")
+	cb(budget.totalSize, true, "")
 
 	return nil
 }
 
-func extractFile(file *zip.File, dest string, totalSize *int64, uid int, gid int) error {
-	filePath := filepath.Join(dest, file.Name)
+// CleanEntryName makes entry names comparable, "./release//bin/" and "release/bin" are the same entry.
+// The result can't start with "..".
+func CleanEntryName(name string) string {
+	return strings.Trim(pathpkg.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+}
+
+// selectEntry reports whether the entry is one of the selected entries or inside one of them, and renames
+// it to its path in the destination. The outermost selection wins if they are nested.
+func selectEntry(entry *archiveEntry, entries []string) bool {
+	name := CleanEntryName(entry.name)
+	selected, found := "", false
+
+	for _, candidate := range entries {
+		candidate = CleanEntryName(candidate)
+		if (name == candidate || strings.HasPrefix(name, candidate+"/")) && (!found || len(candidate) < len(selected)) {
+			selected, found = candidate, true
+		}
+	}
+	if !found || selected == "" {
+		return false
+	}
+
+	if parent := pathpkg.Dir(selected); parent != "." {
+		name = strings.TrimPrefix(name, parent+"/")
+	}
+	entry.name = name
+	return true
+}
+
+// ExtractedPaths returns the items ExtractEntries creates in dest for the selected entries.
+func ExtractedPaths(dest string, entries []string) []string {
+	var paths []string
+	for _, entry := range entries {
+		if name := pathpkg.Base(CleanEntryName(entry)); name != "." && name != "/" {
+			paths = append(paths, filepath.Join(dest, name))
+		}
+	}
+	return paths
+}
 
-	if !IsSafePath(filePath) {
+func extractFile(ctx context.Context, entry *archiveEntry, dest string, budget *extractBudget, uid int, gid int) error {
+	filePath := filepath.Join(dest, entry.name)
+
+	// IsSafePath only keeps the path in the host folder, entries like "../x" must not leave dest either
+	relPath, err := filepath.Rel(dest, filePath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) || !IsSafePath(filePath) {
 		return fmt.Errorf("security risk: wrong filepath")
 	}
 
-	if file.FileInfo().IsDir() {
+	if entry.mode.IsDir() {
 		err := os.MkdirAll(filePath, 0755)
 		if err != nil {
 			return err
@@ -72,51 +255,82 @@ func extractFile(file *zip.File, dest string, totalSize *int64, uid int, gid int
 		return os.Chown(filePath, uid, gid)
 	}
 
+	if !entry.mode.IsRegular() {
+		return nil
+	}
+
+	// The declared size can be wrong, the writes are checked too
+	if entry.size > budget.limit-budget.totalSize {
+		return budget.limitErr
+	}
+
 	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 		return err
 	}
 
-	outFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.Mode())
+	outFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, entry.mode.Perm())
 	if err != nil {
 		return err
 	}
 	defer outFile.Close()
 
-	rc, err := file.Open()
+	rc, err := entry.open()
 	if err != nil {
 		return err
 	}
 	defer rc.Close()
 
-	size, err := io.Copy(outFile, rc)
-	*totalSize += size
+	_, err = io.Copy(budgetWriter{outFile, budget}, contextReader{ctx, rc})
 	return err
 }
 
-func Zip(src, dest string, cb func(int64, bool, string)) error {
+// ArchiveTotalSize returns the uncompressed size of the entries. It's only known without
+// reading the whole archive for zip files, the second value is false for the other formats.
+func ArchiveTotalSize(format, src string) (int64, bool, error) {
+	if format != ArchiveFormatZip {
+		return 0, false, nil
+	}
+
+	reader, err := zip.OpenReader(src)
+	if err != nil {
+		return 0, false, err
+	}
+	defer reader.Close()
+
+	var total int64
+	for _, file := range reader.File {
+		total += int64(file.UncompressedSize64)
+	}
+	return total, true, nil
+}
+
+// Archive packs src into a new archive of the format at dest. Cancelling ctx stops the process,
+// the unfinished archive is left for the caller.
+func Archive(ctx context.Context, format, src, dest string, remainingSpace int64, cb func(int64, bool, string)) error {
 	_, err := os.Stat(src)
 	if err != nil {
 		return fmt.Errorf("cannot access source: %v", err)
 	}
 
-	zipFile, err := os.Create(dest)
+	archiveFile, err := os.Create(dest)
 	if err != nil {
-		return fmt.Errorf("cannot create zip file: %v", err)
+		return fmt.Errorf("cannot create archive file: %v", err)
 	}
-	defer zipFile.Close()
+	defer archiveFile.Close()
 
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
+	writer, err := newArchiveWriter(format, archiveFile)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
 
-	var (
-		totalSize  int64 = 0
-		currentUID int   = os.Getuid()
-		currentGID int   = os.Getgid()
-	)
+	var totalSize int64 = 0
 
-	remainingSpace, err := GetRemainingFolderSpace()
-	if err != nil {
-		return fmt.Errorf("cannot get remaining folder space: %v", err)
+	// A single file is archived with its name, the content of a folder without the folder
+	srcInfo, _ := os.Stat(src)
+	baseName := ""
+	if !srcInfo.IsDir() {
+		baseName = filepath.Base(src)
 	}
 
 	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
@@ -130,22 +344,32 @@ func Zip(src, dest string, cb func(int64, bool, string)) error {
 		}
 
 		if relPath == "." {
-			return nil
+			if baseName == "" {
+				return nil
+			}
+			relPath = baseName
 		}
 
 		relPath = filepath.ToSlash(relPath)
 
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		cb(totalSize, false, "")
 
-		err = archiveItem(zipWriter, path, relPath, info, &totalSize, currentUID, currentGID)
+		err = archiveItem(ctx, writer, path, relPath, info, &totalSize)
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
 		if err != nil {
-			log.Printf("Zip error: %v\n", err)
+			log.Printf("Archive error: %v\n", err)
 			return fmt.Errorf("unable to archive file (%s): %v", relPath, err)
 		}
 
 		if totalSize > remainingSpace {
-			zipWriter.Close()
-			zipFile.Close()
+			writer.Close()
+			archiveFile.Close()
 			os.Remove(dest)
 
 			cb(totalSize, false, "Zip process exceeds storage limit!")
@@ -159,34 +383,26 @@ func Zip(src, dest string, cb func(int64, bool, string)) error {
 		return err
 	}
 
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("cannot finish archive: %v", err)
+	}
+
 	cb(totalSize, true, "")
 	return nil
 }
 
-func archiveItem(zipWriter *zip.Writer, sourcePath, archivePath string, info os.FileInfo, totalSize *int64, uid int, gid int) error {
+// archiveItem adds a folder or a regular file. Other items like symlinks are skipped, so they can't point out of the scope.
+func archiveItem(ctx context.Context, writer archiveWriter, sourcePath, archivePath string, info os.FileInfo, totalSize *int64) error {
 	if !IsSafePath(sourcePath) {
 		return fmt.Errorf("security risk: wrong filepath")
 	}
 
-	header, err := zip.FileInfoHeader(info)
-	if err != nil {
-		return fmt.Errorf("cannot create zip header: %v", err)
-	}
-
-	header.Name = archivePath
-
 	if info.IsDir() {
-		header.Name += "/"
-	} else {
-		header.Method = zip.Deflate
-	}
-
-	writer, err := zipWriter.CreateHeader(header)
-	if err != nil {
-		return fmt.Errorf("cannot create zip entry: %v", err)
+		_, err := writer.add(archivePath, info, nil)
+		return err
 	}
 
-	if info.IsDir() {
+	if !info.Mode().IsRegular() {
 		return nil
 	}
 
@@ -196,7 +412,10 @@ func archiveItem(zipWriter *zip.Writer, sourcePath, archivePath string, info os.
 	}
 	defer sourceFile.Close()
 
-	size, err := io.Copy(writer, sourceFile)
+	size, err := writer.add(archivePath, info, contextReader{ctx, sourceFile})
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("cannot copy file content: %v", err)
 	}
@@ -204,3 +423,50 @@ func archiveItem(zipWriter *zip.Writer, sourcePath, archivePath string, info os.
 	*totalSize += size
 	return nil
 }
+
+// StreamArchive writes the sources as an archive of the format to w without touching the disk. Every source is
+// a top-level entry with its own name. allowed is asked for every entry with its scope path, denied files and
+// folders are left out. Symlinks are skipped so they can't point out of the scope.
+func StreamArchive(w io.Writer, format string, sources []types.ArchiveSource, allowed func(scopePath string) bool) error {
+	writer, err := newArchiveWriter(format, w)
+	if err != nil {
+		return fmt.Errorf("unsupported archive format %q", format)
+	}
+
+	var totalSize int64
+
+	for _, source := range sources {
+		baseName := filepath.Base(source.DiskPath)
+
+		err := filepath.Walk(source.DiskPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !info.IsDir() && !info.Mode().IsRegular() {
+				return nil
+			}
+
+			relPath, err := filepath.Rel(source.DiskPath, path)
+			if err != nil {
+				return fmt.Errorf("cannot get relative path: %v", err)
+			}
+			relPath = filepath.ToSlash(relPath)
+
+			if !allowed(pathpkg.Join(source.ScopePath, relPath)) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			return archiveItem(context.Background(), writer, path, pathpkg.Join(baseName, relPath), info, &totalSize)
+		})
+
+		if err != nil {
+			return fmt.Errorf("unable to archive %s: %v", baseName, err)
+		}
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,222 @@
+package bruteforce
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/cache"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Cache reads and writes have to happen together
+var mu sync.Mutex
+
+const maxBackoff = 30 * time.Second
+
+func userKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check returns how long the client has to wait before the next password attempt. Zero means it's allowed.
+func Check(ip string, username string) time.Duration {
+	if config.Config.BruteForce.Disabled {
+		return 0
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	wait := waitTime(ipKey(ip), now)
+
+	if username != "" {
+		if userWait := waitTime(userKey(username), now); userWait > wait {
+			wait = userWait
+		}
+	}
+
+	return wait
+}
+
+// Reject writes a 429 response if the client has to wait. It returns true if the request must stop.
+func Reject(c *fiber.Ctx, username string) bool {
+	wait := Check(c.IP(), username)
+	if wait <= 0 {
+		return false
+	}
+
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Set("Retry-After", fmt.Sprintf("%d", seconds))
+	c.Status(429).JSON(fiber.Map{
+		"err":        "too many attempts",
+		"retryAfter": seconds,
+	})
+	return true
+}
+
+// RegisterFailure counts a failed password or 2FA attempt for both the IP and the username.
+// accountExists decides if the attempt can be written to the audit log, logs must belong to an existing user.
+func RegisterFailure(ip string, username string, accountExists bool, reason string) {
+	if config.Config.BruteForce.Disabled {
+		return
+	}
+
+	bruteForceConfig := &config.Config.BruteForce
+
+	mu.Lock()
+	ipLocked := registerFailure(ipKey(ip), bruteForceConfig.GetMaxAttemptsPerIP())
+	userLocked := false
+	if username != "" {
+		userLocked = registerFailure(userKey(username), bruteForceConfig.GetMaxAttempts())
+	}
+	mu.Unlock()
+
+	description := fmt.Sprintf("Failed login attempt for %s from %s: %s.", username, ip, reason)
+	if userLocked {
+		description += " The account is temporarily locked."
+	}
+	if ipLocked {
+		description += fmt.Sprintf(" %s is temporarily locked.", ip)
+	}
+
+	if !accountExists {
+		log.Println(description)
+		return
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    username,
+		Action:      "Failed login",
+		Description: description,
+	})
+}
+
+// RegisterSuccess resets the failures of the username. IP failures stay until the window ends,
+// so one valid account can't be used to reset the counter while guessing others.
+func RegisterSuccess(username string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	cache.LoginAttemptCache.Delete(userKey(username))
+}
+
+func List() []types.Lockout {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	lockouts := []types.Lockout{}
+
+	cache.LoginAttemptCache.Mutex.RLock()
+	for key, item := range cache.LoginAttemptCache.Items {
+		attempts := item.Data
+		if !attempts.LockedUntil.After(now) {
+			continue
+		}
+
+		keyType, value, _ := strings.Cut(key, ":")
+		lockouts = append(lockouts, types.Lockout{
+			Key:         key,
+			Type:        keyType,
+			Value:       value,
+			Failures:    len(attempts.Failures),
+			Lockouts:    attempts.Lockouts,
+			LockedUntil: attempts.LockedUntil,
+		})
+	}
+	cache.LoginAttemptCache.Mutex.RUnlock()
+
+	sort.Slice(lockouts, func(i, j int) bool {
+		return lockouts[i].LockedUntil.After(lockouts[j].LockedUntil)
+	})
+
+	return lockouts
+}
+
+// Clear removes the lockout and the failure history of a key like "user:mert" or "ip:127.0.0.1".
+func Clear(key string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := cache.LoginAttemptCache.Get(key); !ok {
+		return false
+	}
+
+	cache.LoginAttemptCache.Delete(key)
+	return true
+}
+
+func ClearAll() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	cache.LoginAttemptCache.Clear()
+}
+
+func waitTime(key string, now time.Time) time.Duration {
+	attempts, ok := cache.LoginAttemptCache.Get(key)
+	if !ok {
+		return 0
+	}
+
+	until := attempts.NextAttemptAt
+	if attempts.LockedUntil.After(until) {
+		until = attempts.LockedUntil
+	}
+
+	if until.After(now) {
+		return until.Sub(now)
+	}
+	return 0
+}
+
+// registerFailure returns true if this failure locked the key.
+func registerFailure(key string, maxAttempts int) bool {
+	bruteForceConfig := &config.Config.BruteForce
+	now := time.Now()
+	window := bruteForceConfig.GetWindow()
+
+	attempts, _ := cache.LoginAttemptCache.Get(key)
+
+	// Sliding window, only failures of the last `window` count
+	failures := attempts.Failures[:0:0]
+	for _, failure := range attempts.Failures {
+		if now.Sub(failure) < window {
+			failures = append(failures, failure)
+		}
+	}
+	attempts.Failures = append(failures, now)
+
+	// 1s, 2s, 4s, 8s... between attempts
+	backoff := time.Duration(1<<min(len(attempts.Failures)-1, 5)) * time.Second
+	attempts.NextAttemptAt = now.Add(min(backoff, maxBackoff))
+
+	locked := false
+	if len(attempts.Failures) >= maxAttempts {
+		lockoutDuration := bruteForceConfig.GetLockoutDuration() * time.Duration(1<<min(attempts.Lockouts, 16))
+		lockoutDuration = min(lockoutDuration, bruteForceConfig.GetMaxLockoutDuration())
+
+		attempts.LockedUntil = now.Add(lockoutDuration)
+		attempts.Lockouts++
+		attempts.Failures = nil
+		locked = true
+	}
+
+	// Lockout count is remembered for a while after the lockout ends, so repeated attacks get longer lockouts
+	ttl := window + bruteForceConfig.GetMaxLockoutDuration()
+	cache.LoginAttemptCache.Set(key, attempts, ttl)
+
+	return locked
+}
@@ -1,5 +1,7 @@
 package cache
 
+import "path/filepath"
+
 func (c *Cache[KeyType, DataType]) Delete(key KeyType) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
@@ -29,3 +31,22 @@ func (c *Cache[KeyType, DataType]) DeleteDirCacheItemsByPathWithoutThisScope(pat
 		}
 	}
 }
+
+// DeleteDirCacheItemsByPaths removes the caches of all scopes for many directories with one lock.
+// Paths are compared after filepath.Clean, so "./host/docs/" and "host/docs" are the same.
+func (c *Cache[KeyType, DataType]) DeleteDirCacheItemsByPaths(paths []string) {
+	cleaned := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		cleaned[filepath.Clean(path)] = true
+	}
+
+	c.Mutex.Lock()
+	defer c.Mutex.Unlock()
+	for key := range c.Items {
+		if dirKey, ok := any(key).(DirectoryCacheKey); ok {
+			if cleaned[filepath.Clean(dirKey.Path)] {
+				delete(c.Items, key)
+			}
+		}
+	}
+}
@@ -29,6 +29,17 @@ var DownloadLinkCache *Cache[string, types.DownloadLinkCache] = CreateCache[stri
 	TimeoutCacheEvent: false,
 })
 
+var LoginAttemptCache *Cache[string, types.LoginAttempts] = CreateCache[string, types.LoginAttempts](1*time.Minute, CacheProperties{
+	SetCacheEvent:     false,
+	TimeoutCacheEvent: false,
+})
+
+// Entries are only valid while SessionCache has the account with the same token version
+var DavCredentialCache *Cache[string, types.DavCredential] = CreateCache[string, types.DavCredential](5*time.Minute, CacheProperties{
+	SetCacheEvent:     false,
+	TimeoutCacheEvent: false,
+})
+
 func ListenDirectorySetCacheEvents() {
 	msg, _ := json.Marshal(fiber.Map{
 		"type": "directory-update",
@@ -0,0 +1,141 @@
+// Package checksum verifies checksums sent with uploads. SHA-256 and CRC32C (Castagnoli) are supported.
+package checksum
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	SHA256 = "sha256"
+	CRC32C = "crc32c"
+)
+
+var (
+	ErrMismatch    = errors.New("checksum mismatch")
+	ErrUnsupported = errors.New("unsupported checksum algorithm")
+)
+
+// Algorithms is the list advertised to tus clients in the Tus-Checksum-Algorithm header.
+var Algorithms = []string{SHA256, CRC32C}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Checksum is an expected checksum. The zero value means the client didn't send one and matches everything.
+type Checksum struct {
+	Algorithm string
+	Sum       []byte
+}
+
+// Parse reads checksums of the upload form like "sha256:9f86d0..." or "crc32c:e3069283".
+func Parse(value string) (Checksum, error) {
+	if value == "" {
+		return Checksum{}, nil
+	}
+
+	algorithm, encoded, found := strings.Cut(value, ":")
+	if !found {
+		return Checksum{}, fmt.Errorf("invalid checksum %q, use a format like \"sha256:<hex>\"", value)
+	}
+
+	sum, err := hex.DecodeString(encoded)
+	if err != nil {
+		return Checksum{}, fmt.Errorf("invalid checksum %q, the sum must be hex encoded", value)
+	}
+
+	return newChecksum(algorithm, sum)
+}
+
+// ParseTusHeader reads the Upload-Checksum header of tus, like "sha256 n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=".
+func ParseTusHeader(value string) (Checksum, error) {
+	if value == "" {
+		return Checksum{}, nil
+	}
+
+	parts := strings.Fields(value)
+	if len(parts) != 2 {
+		return Checksum{}, fmt.Errorf("invalid Upload-Checksum %q", value)
+	}
+
+	sum, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Checksum{}, fmt.Errorf("invalid Upload-Checksum %q, the sum must be base64 encoded", value)
+	}
+
+	return newChecksum(parts[0], sum)
+}
+
+func newChecksum(algorithm string, sum []byte) (Checksum, error) {
+	algorithm = strings.ToLower(algorithm)
+
+	switch algorithm {
+	case SHA256:
+		if len(sum) != sha256.Size {
+			return Checksum{}, fmt.Errorf("sha256 checksums are %d bytes long", sha256.Size)
+		}
+	case CRC32C:
+		if len(sum) != crc32.Size {
+			return Checksum{}, fmt.Errorf("crc32c checksums are %d bytes long", crc32.Size)
+		}
+	default:
+		return Checksum{}, ErrUnsupported
+	}
+
+	return Checksum{Algorithm: algorithm, Sum: sum}, nil
+}
+
+func (c Checksum) IsSet() bool {
+	return c.Algorithm != ""
+}
+
+func (c Checksum) newHash() hash.Hash {
+	if c.Algorithm == CRC32C {
+		return crc32.New(castagnoli)
+	}
+	return sha256.New()
+}
+
+// Verify returns ErrMismatch if the data has another checksum.
+func (c Checksum) Verify(data []byte) error {
+	return c.VerifyReader(bytes.NewReader(data))
+}
+
+func (c Checksum) VerifyReader(reader io.Reader) error {
+	if !c.IsSet() {
+		return nil
+	}
+
+	hash := c.newHash()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return err
+	}
+
+	if !bytes.Equal(hash.Sum(nil), c.Sum) {
+		return ErrMismatch
+	}
+
+	return nil
+}
+
+func (c Checksum) VerifyFile(path string) error {
+	if !c.IsSet() {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return c.VerifyReader(file)
+}
@@ -0,0 +1,265 @@
+package collab
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/MertJSX/folder-host-go/types"
+)
+
+const (
+	// SaveDelay is how long a document waits after the last change before it's written to disk
+	SaveDelay = 2 * time.Second
+	// MaxSaveDelay limits how long changes can stay in memory while somebody types without a pause
+	MaxSaveDelay = 10 * time.Second
+	// HistoryLimit is the number of operations kept for transforming changes based on older revisions
+	HistoryLimit = 500
+)
+
+var ErrStaleRevision = errors.New("revision is too old or unknown")
+
+// SaveFunc writes the content of a document to disk. The account is the last one that changed it.
+type SaveFunc func(path string, content string, account types.Account) error
+
+// CheckFunc is called with the size in bytes a change would give the document, before it's applied.
+type CheckFunc func(path string, size int64, account types.Account) error
+
+type Document struct {
+	mu         sync.Mutex
+	path       string
+	content    []uint16
+	revision   int
+	history    []Operation // history[i] created revision (revision - len(history) + i + 1)
+	clients    int
+	dirty      bool
+	dirtySince time.Time
+	editor     types.Account
+	timer      *time.Timer
+	save       SaveFunc
+	check      CheckFunc
+}
+
+var (
+	documentsMu sync.Mutex
+	documents   = make(map[string]*Document)
+)
+
+// Open returns the document of the path and loads it from disk if nobody has it open yet.
+// Every Open needs a Close.
+func Open(path string, save SaveFunc, check CheckFunc) (*Document, error) {
+	documentsMu.Lock()
+	defer documentsMu.Unlock()
+
+	if document, ok := documents[path]; ok {
+		document.mu.Lock()
+		document.clients++
+		document.mu.Unlock()
+		return document, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &Document{
+		path:    path,
+		content: utf16.Encode([]rune(string(content))),
+		clients: 1,
+		save:    save,
+		check:   check,
+	}
+	documents[path] = document
+
+	return document, nil
+}
+
+// Get returns the document of the path if it's open.
+func Get(path string) (*Document, bool) {
+	documentsMu.Lock()
+	defer documentsMu.Unlock()
+
+	document, ok := documents[path]
+	return document, ok
+}
+
+// Close releases a client of the document. The last one writes pending changes and drops it from memory.
+func Close(path string) {
+	documentsMu.Lock()
+	defer documentsMu.Unlock()
+
+	document, ok := documents[path]
+	if !ok {
+		return
+	}
+
+	document.mu.Lock()
+	document.clients--
+	last := document.clients <= 0
+	document.mu.Unlock()
+
+	if last {
+		// Flushed before it's dropped, so an Open right after it reads the saved content
+		document.Flush()
+		delete(documents, path)
+	}
+}
+
+// Snapshot returns the current content and revision.
+func (d *Document) Snapshot() (string, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return string(utf16.Decode(d.content)), d.revision
+}
+
+// Resync calls send with the current content and revision while the document is locked,
+// so no operation published before or after it can overtake it.
+func (d *Document) Resync(send func(content string, revision int)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	send(string(utf16.Decode(d.content)), d.revision)
+}
+
+// FromRange converts a Monaco range change (1-based lines and columns) into an operation on the current revision.
+// It's used for clients that don't send operations.
+func (d *Document) FromRange(change types.ChangeRange, text string) (Operation, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	start := d.offsetAt(change.StartLineNumber, change.StartColumn)
+	end := max(d.offsetAt(change.EndLineNumber, change.EndColumn), start)
+
+	return Replace(len(d.content), start, end-start, text), d.revision
+}
+
+func (d *Document) offsetAt(line, column int) int {
+	offset := 0
+	for line > 1 && offset < len(d.content) {
+		if d.content[offset] == '\n' {
+			line--
+		}
+		offset++
+	}
+	for column > 1 && offset < len(d.content) && d.content[offset] != '\n' {
+		column--
+		offset++
+	}
+	return offset
+}
+
+// Submit transforms the operation made on the given revision against everything applied since then and applies it.
+// Publish is called with the applied operation and the new revision while the document is still locked,
+// so every client receives the revisions in order.
+func (d *Document) Submit(revision int, op Operation, account types.Account, publish func(op Operation, revision int)) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	oldest := d.revision - len(d.history)
+	if revision < oldest || revision > d.revision {
+		return ErrStaleRevision
+	}
+
+	for _, applied := range d.history[revision-oldest:] {
+		var err error
+		if op, _, err = Transform(op, applied); err != nil {
+			return err
+		}
+	}
+
+	content, err := Apply(d.content, op)
+	if err != nil {
+		return err
+	}
+
+	if d.check != nil {
+		if err := d.check(d.path, int64(len(string(utf16.Decode(content)))), account); err != nil {
+			return err
+		}
+	}
+
+	d.content = content
+	d.revision++
+	d.history = append(d.history, op)
+	if len(d.history) > HistoryLimit {
+		d.history = d.history[len(d.history)-HistoryLimit:]
+	}
+	d.editor = account
+
+	if !op.IsNoop() {
+		d.scheduleSave()
+	}
+
+	if publish != nil {
+		publish(op, d.revision)
+	}
+
+	return nil
+}
+
+// Reset replaces the content after the file was changed outside of the editor. Pending changes are dropped
+// and older revisions can't be transformed anymore, so clients have to resync.
+func (d *Document) Reset(content string, publish func(content string, revision int)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.content = utf16.Encode([]rune(content))
+	d.revision++
+	d.history = nil
+	d.dirty = false
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+
+	if publish != nil {
+		publish(content, d.revision)
+	}
+}
+
+func (d *Document) scheduleSave() {
+	if !d.dirty {
+		d.dirty = true
+		d.dirtySince = time.Now()
+	}
+
+	delay := SaveDelay
+	if remaining := MaxSaveDelay - time.Since(d.dirtySince); remaining < delay {
+		delay = max(remaining, 0)
+	}
+
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(delay, func() {
+		d.Flush()
+	})
+}
+
+// Flush writes pending changes to disk.
+func (d *Document) Flush() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+
+	if !d.dirty || d.save == nil {
+		return nil
+	}
+	d.dirty = false
+
+	if err := d.save(d.path, string(utf16.Decode(d.content)), d.editor); err != nil {
+		log.Printf("Error while saving %s: %v\n", d.path, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+// Package collab keeps a server-authoritative copy of every file that is open in the code editor.
+// Clients send operations together with the revision they are based on, the server transforms them
+// against everything applied since then, so concurrent edits converge for all clients.
+package collab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+var ErrInvalidOperation = errors.New("invalid operation")
+
+// Component is one step of an operation. Exactly one of the fields is set.
+// Lengths are UTF-16 code units, the same unit as the offsets of the Monaco editor.
+type Component struct {
+	Retain int
+	Insert string
+	Delete int
+}
+
+// Operation walks the whole document from start to end. On the wire it's an array like [5, "text", -3, 10]:
+// positive numbers retain, strings insert and negative numbers delete.
+type Operation []Component
+
+func textLength(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+func (op Operation) retain(n int) Operation {
+	if n <= 0 {
+		return op
+	}
+	if len(op) > 0 && op[len(op)-1].Retain > 0 {
+		op[len(op)-1].Retain += n
+		return op
+	}
+	return append(op, Component{Retain: n})
+}
+
+func (op Operation) insert(text string) Operation {
+	if text == "" {
+		return op
+	}
+	if len(op) > 0 && op[len(op)-1].Insert != "" {
+		op[len(op)-1].Insert += text
+		return op
+	}
+	// Inserts always go before deletes at the same position, so equal operations have equal components
+	if len(op) > 0 && op[len(op)-1].Delete > 0 {
+		last := op[len(op)-1]
+		if len(op) > 1 && op[len(op)-2].Insert != "" {
+			op[len(op)-2].Insert += text
+			return op
+		}
+		op[len(op)-1] = Component{Insert: text}
+		return append(op, last)
+	}
+	return append(op, Component{Insert: text})
+}
+
+func (op Operation) delete(n int) Operation {
+	if n <= 0 {
+		return op
+	}
+	if len(op) > 0 && op[len(op)-1].Delete > 0 {
+		op[len(op)-1].Delete += n
+		return op
+	}
+	return append(op, Component{Delete: n})
+}
+
+// BaseLength is the document length the operation can be applied to.
+func (op Operation) BaseLength() int {
+	length := 0
+	for _, component := range op {
+		length += component.Retain + component.Delete
+	}
+	return length
+}
+
+// TargetLength is the document length after the operation is applied.
+func (op Operation) TargetLength() int {
+	length := 0
+	for _, component := range op {
+		length += component.Retain + textLength(component.Insert)
+	}
+	return length
+}
+
+// IsNoop reports whether the operation doesn't change anything.
+func (op Operation) IsNoop() bool {
+	for _, component := range op {
+		if component.Insert != "" || component.Delete > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (op Operation) MarshalJSON() ([]byte, error) {
+	values := make([]any, 0, len(op))
+	for _, component := range op {
+		switch {
+		case component.Insert != "":
+			values = append(values, component.Insert)
+		case component.Delete > 0:
+			values = append(values, -component.Delete)
+		default:
+			values = append(values, component.Retain)
+		}
+	}
+	return json.Marshal(values)
+}
+
+func (op *Operation) UnmarshalJSON(data []byte) error {
+	var values []any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	result := Operation{}
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			result = result.insert(v)
+		case float64:
+			if v != float64(int(v)) || v == 0 {
+				return fmt.Errorf("%w: bad component %v", ErrInvalidOperation, v)
+			}
+			if v > 0 {
+				result = result.retain(int(v))
+			} else {
+				result = result.delete(int(-v))
+			}
+		default:
+			return fmt.Errorf("%w: bad component %v", ErrInvalidOperation, v)
+		}
+	}
+
+	*op = result
+	return nil
+}
+
+// Replace builds the operation that replaces length units at offset with text in a document of docLength units.
+func Replace(docLength, offset, length int, text string) Operation {
+	return Operation{}.retain(offset).insert(text).delete(length).retain(docLength - offset - length)
+}
+
+// Apply runs the operation on the document and returns the new one.
+func Apply(document []uint16, op Operation) ([]uint16, error) {
+	if op.BaseLength() != len(document) {
+		return nil, fmt.Errorf("%w: operation expects %d units, document has %d", ErrInvalidOperation, op.BaseLength(), len(document))
+	}
+
+	result := make([]uint16, 0, op.TargetLength())
+	index := 0
+	for _, component := range op {
+		switch {
+		case component.Insert != "":
+			result = append(result, utf16.Encode([]rune(component.Insert))...)
+		case component.Delete > 0:
+			index += component.Delete
+		default:
+			result = append(result, document[index:index+component.Retain]...)
+			index += component.Retain
+		}
+	}
+
+	return result, nil
+}
+
+// Transform takes two operations made on the same document and returns a' and b', so applying a then b'
+// gives the same document as b then a'. When both insert at the same position, the text of a goes first.
+func Transform(a, b Operation) (Operation, Operation, error) {
+	if a.BaseLength() != b.BaseLength() {
+		return nil, nil, fmt.Errorf("%w: base lengths %d and %d differ", ErrInvalidOperation, a.BaseLength(), b.BaseLength())
+	}
+
+	aPrime, bPrime := Operation{}, Operation{}
+	i, j := 0, 0
+	var first, second *Component
+
+	next := func(op Operation, index *int) *Component {
+		if *index >= len(op) {
+			return nil
+		}
+		component := op[*index]
+		*index++
+		return &component
+	}
+
+	first, second = next(a, &i), next(b, &j)
+	for first != nil || second != nil {
+		if first != nil && first.Insert != "" {
+			aPrime = aPrime.insert(first.Insert)
+			bPrime = bPrime.retain(textLength(first.Insert))
+			first = next(a, &i)
+			continue
+		}
+		if second != nil && second.Insert != "" {
+			aPrime = aPrime.retain(textLength(second.Insert))
+			bPrime = bPrime.insert(second.Insert)
+			second = next(b, &j)
+			continue
+		}
+		if first == nil || second == nil {
+			return nil, nil, fmt.Errorf("%w: operations have different lengths", ErrInvalidOperation)
+		}
+
+		firstLength, secondLength := first.Retain+first.Delete, second.Retain+second.Delete
+		length := min(firstLength, secondLength)
+
+		switch {
+		case first.Retain > 0 && second.Retain > 0:
+			aPrime = aPrime.retain(length)
+			bPrime = bPrime.retain(length)
+		case first.Delete > 0 && second.Retain > 0:
+			aPrime = aPrime.delete(length)
+		case first.Retain > 0 && second.Delete > 0:
+			bPrime = bPrime.delete(length)
+		}
+		// Both deleting the same range leaves nothing to do for either side
+
+		first = shorten(first, length)
+		second = shorten(second, length)
+		if first == nil {
+			first = next(a, &i)
+		}
+		if second == nil {
+			second = next(b, &j)
+		}
+	}
+
+	return aPrime, bPrime, nil
+}
+
+func shorten(component *Component, length int) *Component {
+	if component.Retain > 0 {
+		component.Retain -= length
+		if component.Retain == 0 {
+			return nil
+		}
+		return component
+	}
+	component.Delete -= length
+	if component.Delete == 0 {
+		return nil
+	}
+	return component
+}
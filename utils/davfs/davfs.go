@@ -0,0 +1,173 @@
+package davfs
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/recoverybin"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+	"golang.org/x/net/webdav"
+)
+
+// Prefix is where the WebDAV handler is mounted
+const Prefix = "/dav"
+
+// FileSystem serves the scope of an account to the WebDAV handler. The routes check the requested paths,
+// FileSystem checks every item below them with the same permissions the REST routes use.
+type FileSystem struct {
+	Account types.Account
+	Root    string // Like "./host/mert"
+}
+
+func New(account types.Account) *FileSystem {
+	return &FileSystem{
+		Account: account,
+		Root:    config.Config.GetScopedFolder(account.Scope),
+	}
+}
+
+// NewHandler returns the WebDAV handler of the account, locks are shared with the other users of its scope.
+func NewHandler(account types.Account) *webdav.Handler {
+	return &webdav.Handler{
+		Prefix:     Prefix,
+		FileSystem: New(account),
+		LockSystem: LockSystem(account.Scope),
+	}
+}
+
+// Names are slash separated and cleaned by the handler, like "/docs/notes.txt"
+func (fs *FileSystem) diskPath(name string) string {
+	return fs.Root + name
+}
+
+func (fs *FileSystem) check(permission string, name string) error {
+	if !acl.Allowed(fs.Account, permission, name) {
+		return os.ErrPermission
+	}
+	return nil
+}
+
+func (fs *FileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	permission := types.PermissionCreate
+	if requestFrom(ctx).Method == "COPY" {
+		permission = types.PermissionCopy
+	}
+
+	if err := fs.check(permission, name); err != nil {
+		return err
+	}
+
+	if err := os.Mkdir(fs.diskPath(name), 0777); err != nil {
+		return err
+	}
+
+	sizeindex.Refresh(fs.diskPath(name))
+	return nil
+}
+
+func (fs *FileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	request := requestFrom(ctx)
+
+	if flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		permission := types.PermissionReadDirectories
+		switch request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodPost:
+			permission = types.PermissionDownloadFiles
+		case "COPY":
+			permission = types.PermissionCopy
+		}
+
+		if err := fs.check(permission, name); err != nil {
+			return nil, err
+		}
+
+		file, err := os.Open(fs.diskPath(name))
+		if err != nil {
+			return nil, err
+		}
+
+		return &readFile{File: file, fs: fs, name: name, permission: permission}, nil
+	}
+
+	permission := types.PermissionChange
+	switch request.Method {
+	case http.MethodPut:
+		permission = types.PermissionUploadFiles
+	case "COPY":
+		permission = types.PermissionCopy
+	case "LOCK":
+		permission = types.PermissionCreate
+	}
+
+	if err := fs.check(permission, name); err != nil {
+		return nil, err
+	}
+
+	finalPath := fs.diskPath(name)
+	if stat, err := os.Stat(finalPath); err == nil && stat.IsDir() {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrExist}
+	}
+
+	if request.remaining < 0 {
+		remaining, err := utils.GetRemainingSpace(fs.Account)
+		if err != nil {
+			return nil, err
+		}
+		request.remaining = remaining
+	}
+
+	temp, err := utils.CreateTempFile(finalPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &writeFile{File: temp, finalPath: finalPath, username: fs.Account.Username, request: request}, nil
+}
+
+// RemoveAll uses the recovery bin like the delete route. MOVE and COPY use it for overwritten destinations too.
+func (fs *FileSystem) RemoveAll(ctx context.Context, name string) error {
+	if name == "/" {
+		return os.ErrPermission
+	}
+
+	if err := fs.check(types.PermissionDelete, name); err != nil {
+		return err
+	}
+
+	_, err := recoverybin.Delete(fs.Account.Username, fs.diskPath(name))
+	return err
+}
+
+// Rename needs the rename permission inside the same folder and the move permission between folders.
+func (fs *FileSystem) Rename(ctx context.Context, oldName string, newName string) error {
+	if oldName == "/" || newName == "/" {
+		return os.ErrPermission
+	}
+
+	permission := types.PermissionMove
+	if path.Dir(oldName) == path.Dir(newName) {
+		permission = types.PermissionRename
+	}
+
+	if err := fs.check(permission, oldName); err != nil {
+		return err
+	}
+	if err := fs.check(permission, newName); err != nil {
+		return err
+	}
+
+	err := os.Rename(fs.diskPath(oldName), fs.diskPath(newName))
+	sizeindex.Refresh(fs.diskPath(oldName))
+	sizeindex.Refresh(fs.diskPath(newName))
+	return err
+}
+
+func (fs *FileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	return os.Stat(fs.diskPath(name))
+}
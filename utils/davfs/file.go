@@ -0,0 +1,90 @@
+package davfs
+
+import (
+	"io"
+	"os"
+	"path"
+
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+	"github.com/MertJSX/folder-host-go/utils/versions"
+)
+
+// readFile hides the directory entries the account can't see, the handler lists and copies folders with Readdir.
+type readFile struct {
+	*os.File
+	fs         *FileSystem
+	name       string
+	permission string
+}
+
+func (f *readFile) Readdir(count int) ([]os.FileInfo, error) {
+	entries, err := f.File.Readdir(count)
+
+	allowed := entries[:0]
+	for _, entry := range entries {
+		if f.fs.check(f.permission, path.Join(f.name, entry.Name())) == nil {
+			allowed = append(allowed, entry)
+		}
+	}
+
+	return allowed, err
+}
+
+// writeFile writes to a temporary file next to the target. Close moves it into place, or removes it if
+// the body was cut off or the account ran out of space.
+type writeFile struct {
+	*os.File
+	finalPath string
+	username  string
+	request   *Request
+	err       error
+}
+
+func (f *writeFile) Write(p []byte) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+
+	if int64(len(p)) > f.request.remaining {
+		f.request.quotaExceeded = true
+		f.err = utils.ErrQuotaExceeded
+		return 0, f.err
+	}
+
+	n, err := f.File.Write(p)
+	f.request.remaining -= int64(n)
+	if err != nil {
+		f.err = err
+	}
+	return n, err
+}
+
+// ReadFrom hides the one of os.File, io.Copy would use it and skip the quota checks of Write.
+func (f *writeFile) ReadFrom(reader io.Reader) (int64, error) {
+	return io.Copy(struct{ io.Writer }{f}, reader)
+}
+
+func (f *writeFile) Close() error {
+	tempPath := f.File.Name()
+	err := f.File.Close()
+	if err == nil {
+		err = f.err
+	}
+	if err == nil {
+		err = f.request.bodyErr()
+	}
+
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	if _, err := versions.Replace(f.username, tempPath, f.finalPath, utils.ConflictOverwrite); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	sizeindex.Refresh(f.finalPath)
+	return nil
+}
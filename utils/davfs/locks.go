@@ -0,0 +1,20 @@
+package davfs
+
+import (
+	"sync"
+
+	"golang.org/x/net/webdav"
+)
+
+var lockSystems sync.Map
+
+// LockSystem returns the in-memory locks of a scope. Lock paths are relative to the scope, so users of
+// nested scopes don't see the locks of each other.
+func LockSystem(scope string) webdav.LockSystem {
+	if lockSystem, ok := lockSystems.Load(scope); ok {
+		return lockSystem.(webdav.LockSystem)
+	}
+
+	lockSystem, _ := lockSystems.LoadOrStore(scope, webdav.NewMemLS())
+	return lockSystem.(webdav.LockSystem)
+}
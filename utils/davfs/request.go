@@ -0,0 +1,48 @@
+package davfs
+
+import (
+	"context"
+	"io"
+)
+
+type contextKey struct{}
+
+// Request is the state of one WebDAV request, FileSystem gets it from the context the handler passes on.
+type Request struct {
+	Method        string
+	remaining     int64 // Bytes the account can still write, -1 until the first write
+	quotaExceeded bool
+	body          *bodyReader
+}
+
+func requestFrom(ctx context.Context) *Request {
+	if request, ok := ctx.Value(contextKey{}).(*Request); ok {
+		return request
+	}
+	return &Request{remaining: -1}
+}
+
+// bodyErr returns the error of a body that was cut off, PUT must not place a partial file.
+func (r *Request) bodyErr() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.err
+}
+
+// bodyReader remembers if the request body was read to the end.
+type bodyReader struct {
+	reader io.Reader
+	err    error
+	done   bool
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	if err == io.EOF {
+		b.done = true
+	} else if err != nil {
+		b.err = err
+	}
+	return n, err
+}
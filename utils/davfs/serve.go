@@ -0,0 +1,115 @@
+package davfs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Serve runs the WebDAV handler on the fiber request. Unlike the fasthttp adaptor it doesn't buffer
+// bodies: the request body is read from the connection and Serve returns as soon as the handler wrote its
+// headers, fasthttp streams the rest of the response from a pipe.
+func Serve(c *fiber.Ctx, handler http.Handler) error {
+	// Body would read the whole stream into memory
+	body := &bodyReader{}
+	hasBody := false
+	if stream := c.Context().RequestBodyStream(); stream != nil {
+		body.reader = stream
+		hasBody = c.Request().Header.ContentLength() != 0
+	} else {
+		body.reader = bytes.NewReader(c.Body())
+	}
+
+	request := &Request{Method: c.Method(), remaining: -1, body: body}
+	ctx := context.WithValue(context.Background(), contextKey{}, request)
+
+	// fiber strings point to buffers fasthttp reuses, the handler may outlive this call
+	httpRequest, err := http.NewRequestWithContext(ctx, c.Method(), string(c.Request().RequestURI()), body)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"err": "Bad request!"})
+	}
+
+	httpRequest.Host = string(c.Request().Host())
+	httpRequest.RemoteAddr = c.Context().RemoteAddr().String()
+	switch contentLength := c.Request().Header.ContentLength(); {
+	case contentLength >= 0:
+		httpRequest.ContentLength = int64(contentLength)
+	case contentLength == -1:
+		httpRequest.ContentLength = -1 // Chunked
+	}
+	c.Request().Header.VisitAll(func(key []byte, value []byte) {
+		httpRequest.Header.Add(string(key), string(value))
+	})
+
+	reader, writer := io.Pipe()
+	response := &responseWriter{
+		header:  http.Header{},
+		request: request,
+		pipe:    writer,
+		ready:   make(chan struct{}),
+	}
+
+	go func() {
+		defer writer.Close()
+		handler.ServeHTTP(response, httpRequest)
+		response.WriteHeader(http.StatusOK)
+	}()
+
+	<-response.ready
+
+	// The rest of an unread body is still on the connection
+	if hasBody && !body.done {
+		c.Context().SetConnectionClose()
+	}
+
+	size := -1
+	for key, values := range response.header {
+		if key == fiber.HeaderContentLength {
+			if length, err := strconv.Atoi(values[0]); err == nil {
+				size = length
+			}
+			continue
+		}
+		for _, value := range values {
+			c.Response().Header.Add(key, value)
+		}
+	}
+
+	c.Status(response.status)
+	c.Response().SetBodyStream(reader, size)
+	return nil
+}
+
+type responseWriter struct {
+	header  http.Header
+	status  int
+	request *Request
+	pipe    *io.PipeWriter
+	ready   chan struct{} // Closed when the headers are written
+	once    sync.Once
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.once.Do(func() {
+		// The handler answers 405 when PUT fails, running out of space is 507 like on the REST routes
+		if status == http.StatusMethodNotAllowed && w.request.quotaExceeded {
+			status = http.StatusInsufficientStorage
+		}
+		w.status = status
+		close(w.ready)
+	})
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.pipe.Write(p)
+}
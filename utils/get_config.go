@@ -25,6 +25,10 @@ func GetConfig() {
 
 	config.Config.SizeBytes = ConvertStringToBytes(config.Config.StorageLimit)
 
+	if _, err := ParseQuota(config.Config.AdminAccount.Quota); err != nil {
+		log.Fatalf("Config.yml admin quota error: %v", err)
+	}
+
 	config.Config.Folder = strings.TrimPrefix(config.Config.Folder, "./")
 
 	if config.Config.SecretJwtKey == "" ||
@@ -6,9 +6,15 @@ import (
 	"sync"
 
 	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
 )
 
+// GetDirectorySize answers from the size index for paths in the host folder, other paths are walked.
 func GetDirectorySize(DirectoryPath string) (int64, string, error) {
+	if size, ok := sizeindex.Size(DirectoryPath); ok {
+		return size, ConvertBytesToString(size), nil
+	}
+
 	var size int64
 	err := filepath.Walk(DirectoryPath, func(_ string, info os.FileInfo, err error) error {
 		if err != nil {
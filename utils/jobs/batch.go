@@ -0,0 +1,340 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	pathpkg "path"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/recoverybin"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+)
+
+// MaxBatchOperations is how many operations one batch can have.
+const MaxBatchOperations = 1000
+
+// batchItem is a checked operation, job has the normalized paths.
+type batchItem struct {
+	job    types.Job
+	size   int64
+	result *types.BatchResult
+	undo   func() error // Set after a copy or a move, nil for the other operations
+}
+
+// RunBatch runs the operations of a multi-selection directly, without queueing them, and returns a result for
+// each of them. The storage limit and the recovery bin are checked once for the whole batch.
+//
+// With atomic nothing is changed if an operation fails the checks. If one fails while running, the finished
+// copies and moves are undone and the rest is skipped. Deletes run after the other operations, so they only have
+// to be undone when another delete fails; deleted items stay in the recovery bin then.
+// Without atomic every operation that passes the checks runs, in the given order.
+//
+// Downloads aren't run here, the caller creates one archive link for the done ones.
+func RunBatch(ctx context.Context, account types.Account, operations []types.BatchOperation, atomic bool) []types.BatchResult {
+	results := make([]types.BatchResult, len(operations))
+	items := make([]*batchItem, 0, len(operations))
+	scopedFolder := config.Config.GetScopedFolder(account.Scope)
+
+	for index, operation := range operations {
+		results[index] = types.BatchResult{BatchOperation: operation}
+		item := &batchItem{result: &results[index]}
+
+		job, err := validateBatchOperation(account, operation)
+		if err != nil {
+			item.fail(err)
+			continue
+		}
+
+		item.job = job
+		item.result.Path = job.Source
+		if job.Type == types.JobMove || job.Type == types.JobCopy {
+			item.result.Destination = job.Destination
+		}
+		if job.Type == types.JobCopy || job.Type == types.JobDelete {
+			item.size = itemSize(scopedFolder + job.Source)
+		}
+		items = append(items, item)
+	}
+
+	checkBatchConflicts(items)
+
+	copySpace, err := checkBatchSpace(account, items, atomic)
+	if err != nil {
+		// Only copies need the space, the other operations can still run
+		for _, item := range items {
+			if item.job.Type == types.JobCopy {
+				item.fail(errors.New("Cannot get remaining storage space!"))
+			}
+		}
+	}
+
+	bin := recoverybin.NewBin(account.Username)
+	if atomic {
+		var deleteSize int64
+		for _, item := range items {
+			if item.job.Type == types.JobDelete && item.result.Status == "" {
+				deleteSize += item.size
+			}
+		}
+		if err := bin.Fits(deleteSize); err != nil {
+			for _, item := range items {
+				if item.job.Type == types.JobDelete {
+					item.fail(deleteError(err))
+				}
+			}
+		}
+	}
+
+	if atomic {
+		for _, result := range results {
+			if result.Status == types.BatchFailed {
+				skipBatch(items)
+				return results
+			}
+		}
+
+		// Deletes can't be undone, they run last
+		ordered := make([]*batchItem, 0, len(items))
+		for _, item := range items {
+			if item.job.Type != types.JobDelete {
+				ordered = append(ordered, item)
+			}
+		}
+		for _, item := range items {
+			if item.job.Type == types.JobDelete {
+				ordered = append(ordered, item)
+			}
+		}
+		items = ordered
+	}
+
+	for index, item := range items {
+		if item.result.Status != "" {
+			continue
+		}
+
+		if item.job.Type == types.JobCopy && !atomic {
+			if item.size > copySpace {
+				item.fail(errors.New("Not enough space!"))
+				continue
+			}
+			copySpace -= item.size
+		}
+
+		if err := runBatchItem(ctx, account, item, bin); err != nil {
+			item.fail(err)
+			if atomic {
+				revertBatch(items[:index])
+				skipBatch(items[index+1:])
+				return results
+			}
+			continue
+		}
+		item.result.Status = types.BatchDone
+	}
+
+	return results
+}
+
+func (item *batchItem) fail(err error) {
+	if item.result.Status == types.BatchFailed {
+		return
+	}
+	item.result.Status = types.BatchFailed
+	item.result.Error = err.Error()
+}
+
+// validateBatchOperation checks an operation like the job of the same type, downloads like GetArchiveLink.
+func validateBatchOperation(account types.Account, operation types.BatchOperation) (types.Job, error) {
+	if operation.Type == types.BatchDownload {
+		path := acl.NormalizePath(operation.Path)
+		diskPath := config.Config.GetScopedFolder(account.Scope) + path
+		if path == "/" || !utils.IsSafePath(diskPath) || !utils.IsExistingPath(diskPath) {
+			return types.Job{}, errors.New("The item doesn't exist!")
+		}
+		if !acl.Allowed(account, types.PermissionDownloadFiles, path) {
+			return types.Job{}, errors.New("No permission!")
+		}
+		return types.Job{Type: types.BatchDownload, Source: path}, nil
+	}
+
+	if operation.Type != types.JobDelete && operation.Type != types.JobMove && operation.Type != types.JobCopy {
+		return types.Job{}, errors.New("Unknown operation type!")
+	}
+	if operation.Type != types.JobDelete && operation.Destination == "" {
+		return types.Job{}, errors.New("The destination folder is missing!")
+	}
+
+	job := types.Job{
+		Type:        operation.Type,
+		Username:    account.Username,
+		Source:      operation.Path,
+		Destination: operation.Destination,
+	}
+	if err := Validate(account, &job); err != nil {
+		return types.Job{}, err
+	}
+	if !Allowed(account, job) {
+		return types.Job{}, errors.New("No permission!")
+	}
+
+	return job, nil
+}
+
+// checkBatchConflicts fails operations that use an item moved or deleted by an earlier operation, so the
+// order of the operations doesn't matter. Moved items and downloads must have different names too.
+func checkBatchConflicts(items []*batchItem) {
+	for index, item := range items {
+		if item.result.Status != "" {
+			continue
+		}
+
+		for _, earlier := range items[:index] {
+			if earlier.result.Status != "" {
+				continue
+			}
+
+			if batchUses(earlier.job, item.job) || batchUses(item.job, earlier.job) {
+				item.fail(errors.New("The item is used by another operation!"))
+				break
+			}
+
+			sameName := pathpkg.Base(earlier.job.Source) == pathpkg.Base(item.job.Source)
+			if sameName && earlier.job.Type == types.JobMove && item.job.Type == types.JobMove && earlier.job.Destination == item.job.Destination {
+				item.fail(errors.New("The destination already has an item named like that!"))
+				break
+			}
+			if sameName && earlier.job.Type == types.BatchDownload && item.job.Type == types.BatchDownload {
+				item.fail(fmt.Errorf("%s is selected twice!", pathpkg.Base(item.job.Source)))
+				break
+			}
+		}
+	}
+}
+
+// batchUses reports whether other reads or writes something that changing changes.
+func batchUses(changing, other types.Job) bool {
+	if changing.Type != types.JobMove && changing.Type != types.JobDelete {
+		return false
+	}
+	if isInside(other.Source, changing.Source) || isInside(changing.Source, other.Source) {
+		return true
+	}
+	return (other.Type == types.JobMove || other.Type == types.JobCopy) && isInside(other.Destination, changing.Source)
+}
+
+func isInside(path, folder string) bool {
+	return path == folder || strings.HasPrefix(path, folder+"/")
+}
+
+// checkBatchSpace gets the remaining space once for all copies. An atomic batch fails all of them if they don't
+// fit together, otherwise RunBatch fails the ones that don't fit anymore.
+func checkBatchSpace(account types.Account, items []*batchItem, atomic bool) (int64, error) {
+	var copySize int64
+	for _, item := range items {
+		if item.job.Type == types.JobCopy && item.result.Status == "" {
+			copySize += item.size
+		}
+	}
+	if copySize == 0 {
+		return 0, nil
+	}
+
+	remainingSpace, err := utils.GetRemainingSpace(account)
+	if err != nil {
+		return 0, err
+	}
+
+	if atomic && copySize > remainingSpace {
+		for _, item := range items {
+			if item.job.Type == types.JobCopy {
+				item.fail(errors.New("Not enough space!"))
+			}
+		}
+	}
+
+	return remainingSpace, nil
+}
+
+func runBatchItem(ctx context.Context, account types.Account, item *batchItem, bin *recoverybin.Bin) error {
+	scopedFolder := config.Config.GetScopedFolder(account.Scope)
+	src := scopedFolder + item.job.Source
+
+	switch item.job.Type {
+	case types.JobCopy:
+		result := copyTarget(scopedFolder, item.job.Source, item.job.Destination)
+		err := copyItem(ctx, src, scopedFolder+result, func(int64) {})
+		if err != nil {
+			os.RemoveAll(scopedFolder + result)
+		}
+		sizeindex.Refresh(scopedFolder + result)
+		if err != nil {
+			return errors.New("Unknown error while copying item")
+		}
+
+		item.result.Result = result
+		item.undo = func() error {
+			defer sizeindex.Refresh(scopedFolder + result)
+			return os.RemoveAll(scopedFolder + result)
+		}
+	case types.JobMove:
+		dest := pathpkg.Join(item.job.Destination, pathpkg.Base(item.job.Source))
+		if utils.IsExistingPath(scopedFolder + dest) {
+			return errors.New("The destination already has an item named like that!")
+		}
+
+		err := os.Rename(src, scopedFolder+dest)
+		sizeindex.Refresh(src)
+		sizeindex.Refresh(scopedFolder + dest)
+		if err != nil {
+			return errors.New("Unknown error while moving item")
+		}
+
+		item.result.Result = dest
+		item.undo = func() error {
+			defer sizeindex.Refresh(src)
+			defer sizeindex.Refresh(scopedFolder + dest)
+			return os.Rename(scopedFolder+dest, src)
+		}
+	case types.JobDelete:
+		if _, err := bin.Delete(src); err != nil {
+			return deleteError(err)
+		}
+	}
+
+	return nil
+}
+
+// revertBatch undoes the done operations in reverse order. Operations that can't be undone stay done.
+func revertBatch(items []*batchItem) {
+	for index := len(items) - 1; index >= 0; index-- {
+		item := items[index]
+		if item.result.Status != types.BatchDone || item.job.Type == types.JobDelete {
+			continue
+		}
+
+		if item.undo != nil {
+			if err := item.undo(); err != nil {
+				log.Printf("Error while undoing batch %s of %s: %v\n", item.job.Type, item.job.Source, err)
+				continue
+			}
+		}
+		item.result.Status = types.BatchReverted
+		item.result.Result = ""
+	}
+}
+
+func skipBatch(items []*batchItem) {
+	for _, item := range items {
+		if item.result.Status == "" {
+			item.result.Status = types.BatchSkipped
+		}
+	}
+}
@@ -0,0 +1,345 @@
+// Package jobs runs long file operations in the background. Jobs are kept in the database, a pool of workers
+// takes them in order, and listeners get every change so progress can be sent to any client.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	jobsdb "github.com/MertJSX/folder-host-go/database/jobs"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+)
+
+// progressInterval limits how often progress is saved and sent to the listeners
+const progressInterval = 500 * time.Millisecond
+
+var (
+	ErrNotFound       = errors.New("job not found")
+	ErrNotCancellable = errors.New("job is already finished")
+	ErrNotRetryable   = errors.New("only failed and cancelled jobs can be retried")
+)
+
+type runningJob struct {
+	job      types.Job
+	cancel   context.CancelFunc
+	lastSave time.Time
+}
+
+var (
+	mu        sync.Mutex
+	wake      = sync.NewCond(&mu)
+	pending   []int
+	running   = make(map[int]*runningJob)
+	waiters   = make(map[int][]chan types.Job)
+	listeners []func(job types.Job)
+)
+
+// OnUpdate registers a listener for every change of every job. Call it before Start.
+func OnUpdate(listener func(job types.Job)) {
+	listeners = append(listeners, listener)
+}
+
+func notify(job types.Job) {
+	for _, listener := range listeners {
+		listener(job)
+	}
+}
+
+// Start fails the jobs a previous run left in the middle, queues the waiting ones again and starts the workers.
+func Start() {
+	interrupted, err := jobsdb.GetJobsByStatus(types.JobRunning)
+	if err != nil {
+		log.Printf("Error while reading interrupted jobs: %v\n", err)
+	}
+	for _, job := range interrupted {
+		// The result of an interrupted job is incomplete, it can't continue from the middle
+		cleanup(job)
+		finish(job, types.JobFailed, "Interrupted by a server restart")
+	}
+
+	queued, err := jobsdb.GetJobsByStatus(types.JobQueued)
+	if err != nil {
+		log.Printf("Error while reading queued jobs: %v\n", err)
+	}
+
+	mu.Lock()
+	for _, job := range queued {
+		pending = append(pending, job.ID)
+	}
+	mu.Unlock()
+
+	for range config.Config.Jobs.GetConcurrency() {
+		go worker()
+	}
+}
+
+// Enqueue saves the job and queues it. Check the permissions with Allowed before.
+func Enqueue(job types.Job) (types.Job, error) {
+	job.Status = types.JobQueued
+
+	if err := jobsdb.CreateJob(&job); err != nil {
+		return types.Job{}, err
+	}
+
+	mu.Lock()
+	pending = append(pending, job.ID)
+	wake.Signal()
+	mu.Unlock()
+
+	notify(job)
+	return job, nil
+}
+
+func worker() {
+	for {
+		mu.Lock()
+		for len(pending) == 0 {
+			wake.Wait()
+		}
+		id := pending[0]
+		pending = pending[1:]
+
+		// Registered before the lock is released, so Cancel always finds the job
+		ctx, cancel := context.WithCancel(context.Background())
+		running[id] = &runningJob{cancel: cancel}
+		mu.Unlock()
+
+		run(ctx, id)
+		cancel()
+
+		mu.Lock()
+		delete(running, id)
+		mu.Unlock()
+	}
+}
+
+// Get returns the job, with the latest progress if it's running.
+func Get(id int) (types.Job, error) {
+	mu.Lock()
+	if current, ok := running[id]; ok && current.job.ID == id {
+		job := current.job
+		mu.Unlock()
+		return job, nil
+	}
+	mu.Unlock()
+
+	job, err := jobsdb.GetJobByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.Job{}, ErrNotFound
+	}
+	return job, err
+}
+
+// List returns the latest jobs of the user, or of every user if username is empty.
+func List(username string, limit int) ([]types.Job, error) {
+	var list []types.Job
+	var err error
+
+	if username == "" {
+		list, err = jobsdb.GetAllJobs(limit)
+	} else {
+		list, err = jobsdb.GetJobsByUsername(username, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for index, job := range list {
+		if current, ok := running[job.ID]; ok && current.job.ID == job.ID {
+			list[index] = current.job
+		}
+	}
+
+	return list, nil
+}
+
+// Cancel stops a running job or removes a queued one from the queue.
+// A running job is marked as cancelled by its worker after it cleaned up.
+func Cancel(id int) (types.Job, error) {
+	mu.Lock()
+	if current, ok := running[id]; ok {
+		current.cancel()
+		job := current.job
+		mu.Unlock()
+
+		if job.ID == 0 {
+			return Get(id)
+		}
+		return job, nil
+	}
+	pending = slices.DeleteFunc(pending, func(pendingID int) bool {
+		return pendingID == id
+	})
+	mu.Unlock()
+
+	job, err := Get(id)
+	if err != nil {
+		return types.Job{}, err
+	}
+	if job.Status != types.JobQueued {
+		return job, ErrNotCancellable
+	}
+
+	return finish(job, types.JobCancelled, ""), nil
+}
+
+// Retry queues a failed or cancelled job again from the start. The status is changed with one conditional
+// update, so concurrent retries of the same job queue it only once.
+func Retry(id int) (types.Job, error) {
+	requeued, err := jobsdb.RequeueJob(id)
+	if err != nil {
+		return types.Job{}, err
+	}
+
+	job, err := Get(id)
+	if err != nil {
+		return types.Job{}, err
+	}
+	if !requeued {
+		return job, ErrNotRetryable
+	}
+
+	mu.Lock()
+	pending = append(pending, job.ID)
+	wake.Signal()
+	mu.Unlock()
+
+	notify(job)
+	return job, nil
+}
+
+// Wait returns the job when it's finished. The second value is false if it didn't finish in time.
+func Wait(id int, timeout time.Duration) (types.Job, bool) {
+	done := make(chan types.Job, 1)
+
+	mu.Lock()
+	waiters[id] = append(waiters[id], done)
+	mu.Unlock()
+
+	defer func() {
+		mu.Lock()
+		waiters[id] = slices.DeleteFunc(waiters[id], func(waiter chan types.Job) bool {
+			return waiter == done
+		})
+		if len(waiters[id]) == 0 {
+			delete(waiters, id)
+		}
+		mu.Unlock()
+	}()
+
+	// It could be finished before the waiter was registered
+	if job, err := Get(id); err == nil && job.IsFinished() {
+		return job, true
+	}
+
+	select {
+	case job := <-done:
+		return job, true
+	case <-time.After(timeout):
+		job, _ := Get(id)
+		return job, false
+	}
+}
+
+// update saves and sends the changed job. Progress updates are throttled, state changes aren't.
+func update(job types.Job, force bool) {
+	mu.Lock()
+	current, ok := running[job.ID]
+	if ok {
+		current.job = job
+		if !force && time.Since(current.lastSave) < progressInterval {
+			mu.Unlock()
+			return
+		}
+		current.lastSave = time.Now()
+	}
+	mu.Unlock()
+
+	if err := jobsdb.UpdateJob(job); err != nil {
+		log.Printf("Error while saving job %d: %v\n", job.ID, err)
+	}
+	notify(job)
+}
+
+func finish(job types.Job, status string, message string) types.Job {
+	now := time.Now().UTC()
+	job.Status = status
+	job.Error = message
+	job.FinishedAt = &now
+
+	update(job, true)
+
+	mu.Lock()
+	for _, waiter := range waiters[job.ID] {
+		waiter <- job
+	}
+	delete(waiters, job.ID)
+	mu.Unlock()
+
+	return job
+}
+
+func run(ctx context.Context, id int) {
+	job, err := jobsdb.GetJobByID(id)
+	if err != nil {
+		log.Printf("Error while reading job %d: %v\n", id, err)
+		return
+	}
+	if job.Status != types.JobQueued {
+		return
+	}
+	if ctx.Err() != nil {
+		finish(job, types.JobCancelled, "")
+		return
+	}
+
+	account, err := users.GetUserByUsername(job.Username)
+	if err != nil {
+		finish(job, types.JobFailed, "Account not found!")
+		return
+	}
+
+	// Permissions can change while the job waits in the queue
+	if !Allowed(account, job) {
+		finish(job, types.JobFailed, "No permission!")
+		return
+	}
+
+	now := time.Now().UTC()
+	job.Status = types.JobRunning
+	job.Attempts++
+	job.StartedAt = &now
+	update(job, true)
+
+	err = runners[job.Type](ctx, &job, account)
+
+	switch {
+	case ctx.Err() != nil:
+		cleanup(job)
+		finish(job, types.JobCancelled, "")
+	case err != nil:
+		cleanup(job)
+		finish(job, types.JobFailed, err.Error())
+	default:
+		finish(job, types.JobCompleted, "")
+		logJob(job)
+	}
+}
+
+// ClearFinished removes jobs that finished longer ago than keep_days.
+func ClearFinished() error {
+	if err := jobsdb.DeleteFinishedJobsBefore(time.Now().Add(-config.Config.Jobs.GetKeepDuration())); err != nil {
+		return fmt.Errorf("error while clearing finished jobs: %w", err)
+	}
+	return nil
+}
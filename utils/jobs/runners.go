@@ -0,0 +1,472 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/recoverybin"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+)
+
+type runner func(ctx context.Context, job *types.Job, account types.Account) error
+
+var runners = map[string]runner{
+	types.JobArchive: runArchive,
+	types.JobExtract: runExtract,
+	types.JobCopy:    runCopy,
+	types.JobMove:    runMove,
+	types.JobDelete:  runDelete,
+}
+
+// IsValidType reports whether there's a runner for the job type.
+func IsValidType(jobType string) bool {
+	_, ok := runners[jobType]
+	return ok
+}
+
+// Allowed checks the permissions a job needs, the same ones as the routes that do it directly.
+func Allowed(account types.Account, job types.Job) bool {
+	switch job.Type {
+	case types.JobArchive:
+		return acl.Allowed(account, types.PermissionArchive, job.Source) &&
+			acl.Allowed(account, types.PermissionArchive, pathpkg.Join(job.Destination, pathpkg.Base(job.Source)))
+	case types.JobExtract:
+		return acl.Allowed(account, types.PermissionExtract, job.Source) &&
+			acl.Allowed(account, types.PermissionExtract, pathpkg.Join(job.Destination, pathpkg.Base(job.Source)))
+	case types.JobCopy:
+		return acl.Allowed(account, types.PermissionCopy, job.Source) &&
+			acl.Allowed(account, types.PermissionCopy, pathpkg.Join(job.Destination, pathpkg.Base(job.Source)))
+	case types.JobMove:
+		return acl.Allowed(account, types.PermissionMove, job.Source) &&
+			acl.Allowed(account, types.PermissionMove, pathpkg.Join(job.Destination, pathpkg.Base(job.Source)))
+	case types.JobDelete:
+		return acl.Allowed(account, types.PermissionDelete, job.Source)
+	}
+	return false
+}
+
+// Validate normalizes the paths of a new job and checks that it can run. The returned error is meant for the user.
+func Validate(account types.Account, job *types.Job) error {
+	if !IsValidType(job.Type) {
+		return errors.New("Unknown job type!")
+	}
+
+	job.Source = acl.NormalizePath(job.Source)
+	if job.Source == "/" {
+		return errors.New("The main folder can't be used!")
+	}
+
+	if job.Destination == "" {
+		job.Destination = utils.GetParentPath(job.Source)
+	}
+	job.Destination = acl.NormalizePath(job.Destination)
+
+	scopedFolder := config.Config.GetScopedFolder(account.Scope)
+	sourceStat, err := os.Stat(scopedFolder + job.Source)
+	if err != nil || !utils.IsSafePath(scopedFolder+job.Source) {
+		return errors.New("The item doesn't exist!")
+	}
+
+	switch job.Type {
+	case types.JobArchive:
+		if job.Format == "" {
+			job.Format = utils.ArchiveFormatZip
+		}
+		if !utils.CanCreateArchive(job.Format) {
+			return errors.New("Unsupported archive format!")
+		}
+	case types.JobExtract:
+		if job.Format == "" {
+			job.Format, _ = utils.DetectArchiveFormat(job.Source)
+		}
+		if sourceStat.IsDir() || !utils.IsArchiveFormat(job.Format) {
+			return errors.New("Only zip and tar archives can be extracted!")
+		}
+		if err := validateEntries(scopedFolder, job); err != nil {
+			return err
+		}
+	default:
+		job.Format = ""
+	}
+
+	if job.Type != types.JobExtract {
+		job.Entries = nil
+	}
+
+	destinationStat, err := os.Stat(scopedFolder + job.Destination)
+	if err != nil || !destinationStat.IsDir() || !utils.IsSafePath(scopedFolder+job.Destination) {
+		return errors.New("The destination folder doesn't exist!")
+	}
+
+	if (job.Type == types.JobCopy || job.Type == types.JobMove) && sourceStat.IsDir() &&
+		(job.Destination == job.Source || strings.HasPrefix(job.Destination, job.Source+"/")) {
+		return errors.New("A folder can't be put inside itself!")
+	}
+
+	if job.Type == types.JobMove && job.Destination == utils.GetParentPath(job.Source) {
+		return errors.New("Same location!")
+	}
+
+	return nil
+}
+
+// validateEntries cleans the selected entries of an extract job. Their items must not exist in the destination,
+// so a failed job can remove what it created without touching anything else.
+func validateEntries(scopedFolder string, job *types.Job) error {
+	if len(job.Entries) == 0 {
+		job.Entries = nil
+		return nil
+	}
+	if len(job.Entries) > config.Config.Extraction.GetMaxEntries() {
+		return errors.New("Too many entries are selected!")
+	}
+
+	names := make(map[string]bool)
+	entries := make([]string, 0, len(job.Entries))
+
+	for _, entry := range job.Entries {
+		entry = utils.CleanEntryName(entry)
+		if entry == "" {
+			return errors.New("Invalid entry!")
+		}
+		if names[pathpkg.Base(entry)] {
+			return errors.New("Two selected entries have the same name!")
+		}
+		names[pathpkg.Base(entry)] = true
+		entries = append(entries, entry)
+	}
+
+	// Entries inside other selected entries are extracted with them, but in the wrong place
+	for _, entry := range entries {
+		for _, other := range entries {
+			if strings.HasPrefix(entry, other+"/") {
+				return errors.New("Don't select entries inside selected folders!")
+			}
+		}
+	}
+
+	for _, path := range utils.ExtractedPaths(scopedFolder+job.Destination, entries) {
+		if _, err := os.Lstat(path); err == nil {
+			return errors.New("The destination already has an item named like that!")
+		}
+	}
+
+	job.Entries = entries
+	return nil
+}
+
+// uniquePath adds " (1)", " (2)"... before the extension until the path is free.
+func uniquePath(scopedFolder, directory, name, extension string) string {
+	result := pathpkg.Join(directory, name+extension)
+	for index := 1; utils.IsExistingPath(scopedFolder + result); index++ {
+		result = pathpkg.Join(directory, fmt.Sprintf("%s (%d)%s", name, index, extension))
+	}
+	return result
+}
+
+// setProgress is the progress callback of the utils archive functions. abortMsg is kept for the error of the job.
+func setProgress(job *types.Job, abortMsg *string) func(int64, bool, string) {
+	return func(totalSize int64, isCompleted bool, message string) {
+		if message != "" {
+			*abortMsg = message
+		}
+		job.Progress = totalSize
+		update(*job, false)
+	}
+}
+
+func itemSize(diskPath string) int64 {
+	if size, ok := sizeindex.Size(diskPath); ok {
+		return size
+	}
+	size, _, _ := utils.GetDirectorySize(diskPath)
+	return size
+}
+
+func runArchive(ctx context.Context, job *types.Job, account types.Account) error {
+	scopedFolder := config.Config.GetScopedFolder(account.Scope)
+	src := scopedFolder + job.Source
+
+	job.Result = uniquePath(scopedFolder, job.Destination, utils.GetPureFileName(job.Source), utils.ArchiveExtension(job.Format))
+	job.Total = itemSize(src)
+	update(*job, true)
+
+	remainingSpace, err := utils.GetRemainingSpace(account)
+	if err != nil {
+		return errors.New("Cannot get remaining storage space!")
+	}
+
+	var abortMsg string
+	err = utils.Archive(ctx, job.Format, src, scopedFolder+job.Result, remainingSpace, setProgress(job, &abortMsg))
+	sizeindex.Refresh(scopedFolder + job.Result)
+
+	if abortMsg != "" {
+		return errors.New(abortMsg)
+	}
+	return err
+}
+
+func runExtract(ctx context.Context, job *types.Job, account types.Account) error {
+	scopedFolder := config.Config.GetScopedFolder(account.Scope)
+	src := scopedFolder + job.Source
+
+	total, _, err := utils.ArchiveTotalSize(job.Format, src)
+	if err != nil {
+		return errors.New("Cannot open the archive!")
+	}
+	if job.Entries == nil {
+		job.Total = total
+	}
+
+	if job.Entries == nil {
+		job.Result = uniquePath(scopedFolder, job.Destination, utils.GetPureFileName(job.Source), "")
+	} else {
+		// The destination could have changed while the job was queued
+		if err := validateEntries(scopedFolder, job); err != nil {
+			return err
+		}
+		job.Result = job.Destination
+	}
+	update(*job, true)
+
+	remainingSpace, err := utils.GetRemainingSpace(account)
+	if err != nil {
+		return errors.New("Cannot get remaining storage space!")
+	}
+
+	var abortMsg string
+	err = utils.ExtractEntries(ctx, job.Format, src, scopedFolder+job.Result, job.Entries, remainingSpace, setProgress(job, &abortMsg))
+	sizeindex.Refresh(scopedFolder + job.Result)
+
+	if abortMsg != "" {
+		return errors.New(abortMsg)
+	}
+	return err
+}
+
+func runCopy(ctx context.Context, job *types.Job, account types.Account) error {
+	scopedFolder := config.Config.GetScopedFolder(account.Scope)
+	src := scopedFolder + job.Source
+
+	job.Total = itemSize(src)
+
+	remainingSpace, err := utils.GetRemainingSpace(account)
+	if err != nil {
+		return errors.New("Cannot get remaining storage space!")
+	}
+	if job.Total > remainingSpace {
+		return errors.New("Not enough space!")
+	}
+
+	job.Result = copyTarget(scopedFolder, job.Source, job.Destination)
+	update(*job, true)
+
+	err = copyItem(ctx, src, scopedFolder+job.Result, func(copied int64) {
+		job.Progress += copied
+		update(*job, false)
+	})
+	sizeindex.Refresh(scopedFolder + job.Result)
+
+	return err
+}
+
+// copyTarget returns a free path for a copy of source in destination. A copy next to the original is named
+// like "notes - Copy.txt", elsewhere it keeps the name if it's free.
+func copyTarget(scopedFolder, source, destination string) string {
+	extension := ""
+	if info, err := os.Stat(scopedFolder + source); err == nil && !info.IsDir() {
+		extension = filepath.Ext(source)
+	}
+	name := strings.TrimSuffix(pathpkg.Base(source), extension)
+	if destination == utils.GetParentPath(source) || utils.IsExistingPath(scopedFolder+pathpkg.Join(destination, name+extension)) {
+		name += " - Copy"
+	}
+	return uniquePath(scopedFolder, destination, name, extension)
+}
+
+func runMove(ctx context.Context, job *types.Job, account types.Account) error {
+	scopedFolder := config.Config.GetScopedFolder(account.Scope)
+	src := scopedFolder + job.Source
+	dest := pathpkg.Join(job.Destination, pathpkg.Base(job.Source))
+
+	if utils.IsExistingPath(scopedFolder + dest) {
+		return errors.New("The destination already has an item named like that!")
+	}
+
+	err := os.Rename(src, scopedFolder+dest)
+	sizeindex.Refresh(src)
+	sizeindex.Refresh(scopedFolder + dest)
+
+	if err != nil {
+		return errors.New("Unknown error while moving item")
+	}
+
+	// Result is only set after the move, so a failed move never removes anything
+	job.Result = dest
+	return nil
+}
+
+func runDelete(ctx context.Context, job *types.Job, account types.Account) error {
+	scopedFolder := config.Config.GetScopedFolder(account.Scope)
+
+	movedToBin, err := recoverybin.Delete(account.Username, scopedFolder+job.Source)
+	if err != nil {
+		return deleteError(err)
+	}
+
+	if movedToBin {
+		logs.CreateLog(types.AuditLog{
+			Username:    account.Username,
+			Action:      "Delete",
+			Description: fmt.Sprintf("%s moved %s to recovery_bin", account.Username, job.Source),
+		})
+	} else {
+		logs.CreateLog(types.AuditLog{
+			Username:    account.Username,
+			Action:      "Delete",
+			Description: fmt.Sprintf("%s permanently deleted %s", account.Username, job.Source),
+		})
+	}
+
+	return nil
+}
+
+// deleteError converts errors of recoverybin.Delete to errors for the user.
+func deleteError(err error) error {
+	switch {
+	case errors.Is(err, recoverybin.ErrBinFull):
+		return errors.New("This item exceeds the maximum recovery bin size!")
+	case errors.Is(err, recoverybin.ErrRecordFailed):
+		return errors.New("An error occurred during the creation of the recovery record. But the item was moved to the recovery bin.")
+	default:
+		return errors.New("Error deleting item")
+	}
+}
+
+// copyItem copies a file or a folder with everything in it. Symlinks are copied as links.
+func copyItem(ctx context.Context, src, dest string, progress func(copied int64)) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, relPath)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			return utils.CopySymLink(path, target)
+		case !info.Mode().IsRegular():
+			return nil
+		}
+
+		return copyFile(ctx, path, target, info.Mode().Perm(), progress)
+	})
+}
+
+func copyFile(ctx context.Context, src, dest string, mode os.FileMode, progress func(copied int64)) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	buffer := make([]byte, 256*1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, readErr := in.Read(buffer)
+		if n > 0 {
+			if _, err := out.Write(buffer[:n]); err != nil {
+				return err
+			}
+			progress(int64(n))
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// cleanup removes what an unfinished archive, extract or copy job created.
+func cleanup(job types.Job) {
+	if job.Result == "" || (job.Type != types.JobArchive && job.Type != types.JobExtract && job.Type != types.JobCopy) {
+		return
+	}
+
+	account, err := users.GetUserByUsername(job.Username)
+	if err != nil {
+		return
+	}
+
+	path := config.Config.GetScopedFolder(account.Scope) + job.Result
+	if !utils.IsSafePath(path) {
+		return
+	}
+
+	// Extracted entries are put in the destination, only they are removed
+	if job.Type == types.JobExtract && job.Entries != nil {
+		for _, extracted := range utils.ExtractedPaths(path, job.Entries) {
+			os.RemoveAll(extracted)
+		}
+		sizeindex.Refresh(path)
+		return
+	}
+
+	os.RemoveAll(path)
+	sizeindex.Refresh(path)
+}
+
+func logJob(job types.Job) {
+	var action, description string
+
+	switch job.Type {
+	case types.JobArchive:
+		action, description = "Archive file", fmt.Sprintf("%s archived %s to %s.", job.Username, job.Source, job.Result)
+	case types.JobExtract:
+		action, description = "Extract file", fmt.Sprintf("%s extracted %s to %s.", job.Username, job.Source, job.Result)
+	case types.JobCopy:
+		action, description = "Create copy", fmt.Sprintf("%s created a copy of %s", job.Username, job.Source)
+	case types.JobMove:
+		action, description = "Move", fmt.Sprintf("%s moved an item %s -> %s", job.Username, job.Source, job.Result)
+	default:
+		return // Deletes are logged by runDelete, it knows if the item went to the recovery bin
+	}
+
+	logs.CreateLog(types.AuditLog{
+		Username:    job.Username,
+		Action:      action,
+		Description: description,
+	})
+}
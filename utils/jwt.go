@@ -4,14 +4,21 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/MertJSX/folder-host-go/types"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func CreateToken(username string, secretKey string) (string, error) {
+func CreateToken(account types.Account, secretKey string, lifetime time.Duration) (string, error) {
+	if account.ID == nil {
+		return "", fmt.Errorf("account id is missing")
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
 		jwt.MapClaims{
-			"username": username,
-			"exp":      time.Now().Add(time.Hour * 24).Unix(),
+			"username": account.Username,
+			"uid":      *account.ID,
+			"ver":      account.TokenVersion,
+			"exp":      time.Now().Add(lifetime).Unix(),
 		})
 
 	tokenString, err := token.SignedString([]byte(secretKey))
@@ -22,28 +29,45 @@ func CreateToken(username string, secretKey string) (string, error) {
 	return tokenString, nil
 }
 
-func VerifyToken(tokenString string, secretKey string) (string, error) {
+func VerifyToken(tokenString string, secretKey string) (types.TokenClaims, error) {
+	var tokenClaims types.TokenClaims
+
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		return []byte(secretKey), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
 
 	if err != nil {
-		return "", err
+		return tokenClaims, err
 	}
 
 	if !token.Valid {
-		return "", fmt.Errorf("invalid token")
+		return tokenClaims, fmt.Errorf("invalid token")
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", fmt.Errorf("invalid token claims")
+		return tokenClaims, fmt.Errorf("invalid token claims")
 	}
 
 	username, ok := claims["username"].(string)
 	if !ok {
-		return "", fmt.Errorf("username not found in token")
+		return tokenClaims, fmt.Errorf("username not found in token")
 	}
 
-	return username, nil
+	// JSON numbers are decoded as float64
+	userID, ok := claims["uid"].(float64)
+	if !ok {
+		return tokenClaims, fmt.Errorf("user id not found in token")
+	}
+
+	tokenVersion, ok := claims["ver"].(float64)
+	if !ok {
+		return tokenClaims, fmt.Errorf("token version not found in token")
+	}
+
+	tokenClaims.Username = username
+	tokenClaims.UserID = int(userID)
+	tokenClaims.TokenVersion = int(tokenVersion)
+
+	return tokenClaims, nil
 }
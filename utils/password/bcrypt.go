@@ -0,0 +1,34 @@
+package password
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h *BcryptHasher) Name() string {
+	return "bcrypt"
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify uses bcrypt.CompareHashAndPassword which is already constant time.
+func (h *BcryptHasher) Verify(password, encoded string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)) == nil
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return true
+	}
+	return cost < h.Cost
+}
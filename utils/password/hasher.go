@@ -0,0 +1,73 @@
+package password
+
+import (
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/utils/config"
+)
+
+type Hasher interface {
+	// Name is the identifier used in config.yml (password_hashing) and in the encoded hash prefix.
+	Name() string
+	Hash(password string) (string, error)
+	Verify(password, encoded string) bool
+	// NeedsRehash reports whether the encoded hash was created with weaker parameters than the current ones.
+	NeedsRehash(encoded string) bool
+}
+
+const DefaultHasher = "argon2id"
+
+var hashers = map[string]Hasher{
+	"argon2id": &Argon2idHasher{Memory: 64 * 1024, Iterations: 3, Parallelism: 2, SaltLength: 16, KeyLength: 32},
+	"bcrypt":   &BcryptHasher{Cost: 12},
+}
+
+func Register(hasher Hasher) {
+	hashers[hasher.Name()] = hasher
+}
+
+func Current() Hasher {
+	if hasher, ok := hashers[config.Config.PasswordHashing]; ok {
+		return hasher
+	}
+	return hashers[DefaultHasher]
+}
+
+func Hash(password string) (string, error) {
+	return Current().Hash(password)
+}
+
+// Verify checks the password against any supported encoding, including legacy unsalted SHA-256 hex strings.
+// needsRehash is true when the password matched but the stored hash should be replaced with a Current() one.
+func Verify(password, encoded string) (ok bool, needsRehash bool) {
+	hasher := detect(encoded)
+	if hasher == nil {
+		return false, false
+	}
+
+	if !hasher.Verify(password, encoded) {
+		return false, false
+	}
+
+	current := Current()
+	return true, hasher.Name() != current.Name() || current.NeedsRehash(encoded)
+}
+
+func detect(encoded string) Hasher {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return hashers["argon2id"]
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return hashers["bcrypt"]
+	case isLegacySHA256(encoded):
+		return legacySHA256Hasher{}
+	}
+
+	for name, hasher := range hashers {
+		if strings.HasPrefix(encoded, "$"+name+"$") {
+			return hasher
+		}
+	}
+
+	return nil
+}
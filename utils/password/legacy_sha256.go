@@ -0,0 +1,38 @@
+package password
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+)
+
+// legacySHA256Hasher only verifies the unsalted SHA-256 hex strings stored by older versions.
+// New hashes are never created with it, Verify always reports them as needing a rehash.
+type legacySHA256Hasher struct{}
+
+func (legacySHA256Hasher) Name() string {
+	return "sha256"
+}
+
+func (legacySHA256Hasher) Hash(password string) (string, error) {
+	return "", fmt.Errorf("sha256 password hashing is not supported anymore")
+}
+
+func (legacySHA256Hasher) Verify(password, encoded string) bool {
+	hash := sha256.Sum256([]byte(password))
+	hashString := hex.EncodeToString(hash[:])
+	return subtle.ConstantTimeCompare([]byte(hashString), []byte(encoded)) == 1
+}
+
+func (legacySHA256Hasher) NeedsRehash(encoded string) bool {
+	return true
+}
+
+func isLegacySHA256(encoded string) bool {
+	if len(encoded) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(encoded)
+	return err == nil
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Conflict policies of uploads, they decide what happens when the target file already exists.
+const (
+	ConflictOverwrite = "overwrite"
+	ConflictFail      = "fail"
+	ConflictRename    = "rename"
+)
+
+var ErrFileExists = errors.New("file already exists")
+
+// ParseConflictPolicy validates a conflict policy, empty means overwrite like older clients expect.
+func ParseConflictPolicy(policy string) (string, error) {
+	switch policy {
+	case "":
+		return ConflictOverwrite, nil
+	case ConflictOverwrite, ConflictFail, ConflictRename:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid conflict policy %q, use \"fail\", \"overwrite\" or \"rename\"", policy)
+	}
+}
+
+// CreateTempFile creates a hidden file next to finalPath. Being on the same device lets PlaceFile rename it into place.
+func CreateTempFile(finalPath string) (*os.File, error) {
+	return os.CreateTemp(filepath.Dir(finalPath), "."+filepath.Base(finalPath)+".*.part")
+}
+
+// IsTempFile reports whether the name belongs to a file CreateTempFile created.
+func IsTempFile(name string) bool {
+	return strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".part")
+}
+
+// PlaceFile atomically moves tempPath to finalPath and returns the path the file ended up at.
+// With ConflictRename it's "name (1).ext" like copies, with ConflictFail an existing file returns ErrFileExists.
+func PlaceFile(tempPath string, finalPath string, policy string) (string, error) {
+	switch policy {
+	case ConflictFail:
+		return finalPath, moveNoReplace(tempPath, finalPath)
+	case ConflictRename:
+		ext := filepath.Ext(finalPath)
+		base := strings.TrimSuffix(finalPath, ext)
+		path := finalPath
+		for index := 1; ; index++ {
+			err := moveNoReplace(tempPath, path)
+			if !errors.Is(err, ErrFileExists) {
+				return path, err
+			}
+			path = fmt.Sprintf("%s (%d)%s", base, index, ext)
+		}
+	default:
+		return finalPath, os.Rename(tempPath, finalPath)
+	}
+}
+
+// moveNoReplace uses a hard link, it fails atomically if the target exists while rename would replace it.
+func moveNoReplace(tempPath string, finalPath string) error {
+	if err := os.Link(tempPath, finalPath); err != nil {
+		if os.IsExist(err) {
+			return ErrFileExists
+		}
+
+		// Some file systems don't support hard links, checking first leaves a small race there
+		if IsExistingPath(finalPath) {
+			return ErrFileExists
+		}
+		return os.Rename(tempPath, finalPath)
+	}
+
+	return os.Remove(tempPath)
+}
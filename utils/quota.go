@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+)
+
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// ParseQuota converts quotas like "5 GB" to bytes. Empty quota means unlimited and returns 0.
+func ParseQuota(quota string) (int64, error) {
+	if quota == "" {
+		return 0, nil
+	}
+
+	if len(strings.Fields(quota)) != 2 {
+		return 0, fmt.Errorf("invalid quota %q, use a format like \"5 GB\"", quota)
+	}
+
+	bytes := ConvertStringToBytes(quota)
+	if bytes <= 0 {
+		return 0, fmt.Errorf("invalid quota %q, use a format like \"5 GB\"", quota)
+	}
+
+	return bytes, nil
+}
+
+// GetQuotaUsage returns the size of the scope of the account and its quota in bytes. Zero limit means unlimited.
+// Users sharing a scope share the usage too.
+func GetQuotaUsage(account types.Account) (used int64, limit int64, err error) {
+	limit, err = ParseQuota(account.Quota)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	used, _, err = GetDirectorySize(config.Config.GetScopedFolder(account.Scope))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return used, limit, nil
+}
+
+// GetRemainingSpace returns how many bytes the account can still write. It's limited by the global storage limit
+// and the quota of the account, math.MaxInt64 means unlimited.
+func GetRemainingSpace(account types.Account) (int64, error) {
+	var remaining int64 = math.MaxInt64
+
+	if config.Config.StorageLimit != "" {
+		remainingFolderSpace, err := GetRemainingFolderSpace()
+		if err != nil {
+			return 0, err
+		}
+		remaining = remainingFolderSpace
+	}
+
+	if account.Quota == "" {
+		return remaining, nil
+	}
+
+	used, limit, err := GetQuotaUsage(account)
+	if err != nil {
+		return 0, err
+	}
+
+	return min(remaining, limit-used), nil
+}
+
+// CheckQuota returns ErrQuotaExceeded if the account can't write size more bytes.
+func CheckQuota(account types.Account, size int64) error {
+	remaining, err := GetRemainingSpace(account)
+	if err != nil {
+		return err
+	}
+
+	if size > remaining {
+		return ErrQuotaExceeded
+	}
+
+	return nil
+}
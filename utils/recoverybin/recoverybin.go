@@ -0,0 +1,133 @@
+package recoverybin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MertJSX/folder-host-go/database/recovery"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+)
+
+const Folder = "./recovery_bin"
+
+var (
+	ErrBinFull = errors.New("item exceeds the maximum recovery bin size")
+	// ErrRecordFailed means the item was moved to the bin but it can't be recovered from the panel
+	ErrRecordFailed = errors.New("recovery record could not be created")
+)
+
+// Delete moves the item at path to the recovery bin and creates its record, or removes it permanently when
+// the recovery bin is disabled. The returned bool tells if the item was moved to the bin.
+func Delete(username string, path string) (bool, error) {
+	return NewBin(username).Delete(path)
+}
+
+// Bin deletes many items of a user, the size of the recovery bin is only measured for the first one.
+type Bin struct {
+	username string
+	size     int64
+	measured bool
+}
+
+func NewBin(username string) *Bin {
+	return &Bin{username: username}
+}
+
+// Fits returns ErrBinFull if items of size bytes don't fit in the recovery bin anymore.
+func (b *Bin) Fits(size int64) error {
+	config := &config.Config
+	if !config.RecoveryBin || config.BinStorageLimit == "UNLIMITED" {
+		return nil
+	}
+
+	if !b.measured {
+		sizeOfRecoveryBin, _, err := utils.GetDirectorySize(Folder)
+		if err != nil {
+			return err
+		}
+		b.size, b.measured = sizeOfRecoveryBin, true
+	}
+
+	if b.size+size > utils.ConvertStringToBytes(config.BinStorageLimit) {
+		return ErrBinFull
+	}
+	return nil
+}
+
+// Delete works like the Delete function.
+func (b *Bin) Delete(path string) (bool, error) {
+	config := &config.Config
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+
+	if !config.RecoveryBin {
+		err := os.RemoveAll(path)
+		sizeindex.Refresh(path)
+		return false, err
+	}
+
+	isDirectory := stat.IsDir()
+	sizeOfItem := stat.Size()
+	if isDirectory {
+		sizeOfItem, _, err = utils.GetDirectorySize(path)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if err := b.Fits(sizeOfItem); err != nil {
+		return false, err
+	}
+
+	itemName := filepath.Base(path)
+	var (
+		originalName string = utils.GetPureFileName(itemName)
+		extName      string = filepath.Ext(itemName)
+		copyIndex    int    = 0
+		fullFileName string = originalName + extName
+	)
+
+	if isDirectory {
+		fullFileName = itemName
+		for utils.IsExistingPath(fmt.Sprintf("%s/%s", Folder, fullFileName)) {
+			copyIndex++
+			fullFileName = fmt.Sprintf("%s (%d)", fullFileName, copyIndex)
+		}
+	} else {
+		for utils.IsExistingPath(fmt.Sprintf("%s/%s", Folder, fullFileName)) {
+			copyIndex++
+			fullFileName = fmt.Sprintf("%s (%d)%s", originalName, copyIndex, extName)
+		}
+	}
+
+	binLocation := fmt.Sprintf("%s/%s", Folder, fullFileName)
+	if err := os.Rename(path, binLocation); err != nil {
+		return false, err
+	}
+	b.size += sizeOfItem
+
+	sizeindex.Refresh(path)
+
+	recoveryRecord := types.RecoveryRecord{
+		Username:    b.username,
+		OldLocation: path,
+		BinLocation: binLocation,
+		IsDirectory: isDirectory,
+		SizeDisplay: utils.ConvertBytesToString(sizeOfItem),
+		SizeBytes:   sizeOfItem,
+	}
+
+	if err := recovery.CreateRecoveryRecord(recoveryRecord); err != nil {
+		return true, fmt.Errorf("%w: %w", ErrRecordFailed, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,134 @@
+package s3
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Chunks are usually 64 KB, bigger ones are refused instead of buffered
+const maxChunkSize = 16 * 1024 * 1024
+
+var (
+	ErrChunkSignature = errors.New("chunk signature does not match")
+	ErrMalformedChunk = errors.New("malformed aws-chunked body")
+)
+
+// chunkReader decodes aws-chunked bodies, "size;chunk-signature=...\r\ndata\r\n" until an empty chunk.
+// Signed chunks are verified, each signature continues the one of the previous chunk.
+type chunkReader struct {
+	reader    *bufio.Reader
+	signature *Signature
+	key       []byte // nil for unsigned chunks
+	previous  string
+	trailer   bool
+	buffer    []byte
+	offset    int
+	err       error
+}
+
+// NewChunkReader returns the decoded body of an aws-chunked request. The secret verifies signed chunks.
+func NewChunkReader(reader io.Reader, signature *Signature, secret string) io.Reader {
+	chunks := &chunkReader{
+		reader:    bufio.NewReader(reader),
+		signature: signature,
+		previous:  signature.Signature,
+		trailer:   signature.PayloadHash != StreamingPayload,
+	}
+	if signature.PayloadHash != StreamingUnsignedPayloadTrailer {
+		chunks.key = signature.signingKey(secret)
+	}
+	return chunks
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for r.offset >= len(r.buffer) {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.next()
+	}
+
+	n := copy(p, r.buffer[r.offset:])
+	r.offset += n
+	return n, nil
+}
+
+func (r *chunkReader) next() error {
+	header, err := r.readLine()
+	if err != nil {
+		return err
+	}
+
+	sizeText, extension, _ := strings.Cut(header, ";")
+	size, err := strconv.ParseInt(strings.TrimSpace(sizeText), 16, 64)
+	if err != nil || size < 0 || size > maxChunkSize {
+		return ErrMalformedChunk
+	}
+
+	if cap(r.buffer) < int(size) {
+		r.buffer = make([]byte, size)
+	}
+	r.buffer = r.buffer[:size]
+	r.offset = 0
+	if _, err := io.ReadFull(r.reader, r.buffer); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+
+	if r.key != nil {
+		chunkSignature, _ := strings.CutPrefix(extension, "chunk-signature=")
+		stringToSign := strings.Join([]string{
+			algorithm + "-PAYLOAD",
+			r.signature.Time.Format(amzTimeFormat),
+			r.signature.Scope,
+			r.previous,
+			emptySHA256,
+			sha256Hex(r.buffer),
+		}, "\n")
+
+		expected := hex.EncodeToString(hmacSHA256(r.key, stringToSign))
+		if !hmac.Equal([]byte(expected), []byte(chunkSignature)) {
+			return ErrChunkSignature
+		}
+		r.previous = chunkSignature
+	}
+
+	if size == 0 {
+		// Checksum trailers end with an empty line, they aren't used
+		for r.trailer {
+			line, err := r.readLine()
+			if err != nil {
+				return err
+			}
+			if line == "" {
+				break
+			}
+		}
+		if !r.trailer {
+			if line, err := r.readLine(); err != nil || line != "" {
+				return ErrMalformedChunk
+			}
+		}
+		return io.EOF
+	}
+
+	if line, err := r.readLine(); err != nil || line != "" {
+		return ErrMalformedChunk
+	}
+	return nil
+}
+
+func (r *chunkReader) readLine() (string, error) {
+	line, err := r.reader.ReadSlice('\n')
+	if err != nil {
+		if err == io.EOF || err == bufio.ErrBufferFull {
+			return "", ErrMalformedChunk
+		}
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(line), "\n"), "\r"), nil
+}
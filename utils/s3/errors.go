@@ -0,0 +1,80 @@
+package s3
+
+import (
+	"encoding/xml"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Error is an S3 error response, clients decide what to do by its code.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+var (
+	ErrAccessDenied          = &Error{403, "AccessDenied", "Access Denied"}
+	ErrInvalidAccessKeyID    = &Error{403, "InvalidAccessKeyId", "The access key ID you provided does not exist in our records."}
+	ErrSignatureDoesNotMatch = &Error{403, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided."}
+	ErrRequestTimeTooSkewed  = &Error{403, "RequestTimeTooSkewed", "The difference between the request time and the server's time is too large."}
+	ErrExpiredRequest        = &Error{403, "AccessDenied", "Request has expired"}
+	ErrMalformedAuth         = &Error{400, "AuthorizationHeaderMalformed", "The authorization header is malformed."}
+	ErrMissingAuth           = &Error{403, "AccessDenied", "Request is not signed with AWS Signature Version 4"}
+	ErrInvalidArgument       = &Error{400, "InvalidArgument", "Invalid Argument"}
+	ErrInvalidRequest        = &Error{400, "InvalidRequest", "Invalid Request"}
+	ErrMalformedXML          = &Error{400, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema."}
+	ErrBadDigest             = &Error{400, "BadDigest", "The Content-MD5 you specified did not match what we received."}
+	ErrContentSHA256Mismatch = &Error{400, "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed."}
+	ErrIncompleteBody        = &Error{400, "IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header."}
+	ErrInvalidPart           = &Error{400, "InvalidPart", "One or more of the specified parts could not be found or did not match."}
+	ErrInvalidPartOrder      = &Error{400, "InvalidPartOrder", "The list of parts was not in ascending order."}
+	ErrNoSuchBucket          = &Error{404, "NoSuchBucket", "The specified bucket does not exist."}
+	ErrNoSuchKey             = &Error{404, "NoSuchKey", "The specified key does not exist."}
+	ErrNoSuchUpload          = &Error{404, "NoSuchUpload", "The specified multipart upload does not exist."}
+	ErrKeyExists             = &Error{409, "InvalidRequest", "A folder or file with the same name is in the way."}
+	ErrNotImplemented        = &Error{501, "NotImplemented", "A header or query you provided implies functionality that is not implemented."}
+	ErrInternal              = &Error{500, "InternalError", "We encountered an internal error. Please try again."}
+	ErrInsufficientStorage   = &Error{507, "InsufficientStorage", "Not enough space!"}
+	ErrRecoveryBinFull       = &Error{413, "EntityTooLarge", "This item exceeds the maximum recovery bin size!"}
+	ErrEntityTooLarge        = &Error{400, "EntityTooLarge", "Your proposed upload exceeds the maximum allowed object size."}
+)
+
+type errorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+// SendError writes the error as XML. The body of a rejected upload may be unread, the connection is closed then.
+func SendError(c *fiber.Ctx, err *Error) error {
+	if c.Context().RequestBodyStream() != nil && c.Request().Header.ContentLength() != 0 {
+		c.Context().SetConnectionClose()
+	}
+
+	// HEAD responses can't have a body, clients only see the status
+	if c.Method() == fiber.MethodHead {
+		return c.SendStatus(err.Status)
+	}
+
+	return SendXML(c, err.Status, errorResponse{
+		Code:     err.Code,
+		Message:  err.Message,
+		Resource: c.Path(),
+	})
+}
+
+func SendXML(c *fiber.Ctx, status int, body any) error {
+	encoded, err := xml.Marshal(body)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Internal server error!"})
+	}
+
+	c.Set("Content-Type", "application/xml")
+	return c.Status(status).Send(append([]byte(xml.Header), encoded...))
+}
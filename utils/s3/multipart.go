@@ -0,0 +1,80 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MertJSX/folder-host-go/utils/tus"
+)
+
+const MaxPartNumber = 10000
+
+// Parts of multipart uploads are kept in a folder per upload next to the tus uploads, the cleanup task
+// removes both after the upload expiration.
+func UploadDir(id string) string {
+	return filepath.Join(tus.Folder, id)
+}
+
+func PartPath(id string, partNumber int) string {
+	return filepath.Join(UploadDir(id), fmt.Sprintf("%05d", partNumber))
+}
+
+// RemoveUpload removes the parts and forgets the lock of the upload.
+func RemoveUpload(id string) error {
+	if err := os.RemoveAll(UploadDir(id)); err != nil {
+		return err
+	}
+	return tus.Remove(id)
+}
+
+// PendingSize returns the size of the parts an upload already has, except partNumber which is replaced.
+func PendingSize(id string, partNumber int) int64 {
+	entries, err := os.ReadDir(UploadDir(id))
+	if err != nil {
+		return 0
+	}
+
+	var size int64
+	for _, entry := range entries {
+		if entry.Name() == filepath.Base(PartPath(id, partNumber)) {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// AssembleParts writes the parts to file in the given order. Every ETag must match the MD5 of its part,
+// clients send back what UploadPart returned.
+func AssembleParts(id string, parts []CompletedPart, file io.Writer) *Error {
+	for index, part := range parts {
+		if index > 0 && part.PartNumber <= parts[index-1].PartNumber {
+			return ErrInvalidPartOrder
+		}
+
+		partFile, err := os.Open(PartPath(id, part.PartNumber))
+		if err != nil {
+			return ErrInvalidPart
+		}
+
+		hash := md5.New()
+		_, err = io.Copy(io.MultiWriter(file, hash), partFile)
+		partFile.Close()
+		if err != nil {
+			return ErrInternal
+		}
+
+		if strings.Trim(part.ETag, `"`) != hex.EncodeToString(hash.Sum(nil)) {
+			return ErrInvalidPart
+		}
+	}
+
+	return nil
+}
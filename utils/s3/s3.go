@@ -0,0 +1,4 @@
+package s3
+
+// Prefix is where the S3 gateway is mounted. Buckets are path-style, like /s3/mert/docs/notes.txt
+const Prefix = "/s3"
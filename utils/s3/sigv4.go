@@ -0,0 +1,257 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	algorithm      = "AWS4-HMAC-SHA256"
+	amzTimeFormat  = "20060102T150405Z"
+	maxClockSkew   = 15 * time.Minute
+	maxPresignTime = 7 * 24 * time.Hour
+	emptySHA256    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// Payload hashes that aren't a hex SHA-256 of the body
+const (
+	UnsignedPayload                 = "UNSIGNED-PAYLOAD"
+	StreamingPayload                = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	StreamingPayloadTrailer         = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+	StreamingUnsignedPayloadTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+)
+
+// Signature is the SigV4 signature of a request, from the Authorization header or the query of a presigned URL.
+type Signature struct {
+	AccessKeyID   string
+	Scope         string // Like "20250101/us-east-1/s3/aws4_request"
+	SignedHeaders []string
+	Signature     string
+	Time          time.Time
+	PayloadHash   string
+	presigned     bool
+	expires       time.Duration
+}
+
+// ParseSignature reads the signature of the request without verifying it.
+func ParseSignature(c *fiber.Ctx) (*Signature, *Error) {
+	if c.Query("X-Amz-Algorithm") != "" {
+		return parsePresigned(c)
+	}
+
+	authorization := c.Get("Authorization")
+	if authorization == "" {
+		return nil, ErrMissingAuth
+	}
+
+	fields, ok := strings.CutPrefix(authorization, algorithm+" ")
+	if !ok {
+		return nil, ErrMissingAuth
+	}
+
+	signature := &Signature{}
+	var credential string
+	for _, field := range strings.Split(fields, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch key {
+		case "Credential":
+			credential = value
+		case "SignedHeaders":
+			signature.SignedHeaders = strings.Split(value, ";")
+		case "Signature":
+			signature.Signature = value
+		}
+	}
+
+	amzDate := c.Get("X-Amz-Date")
+	if amzDate == "" {
+		amzDate = c.Get("Date")
+	}
+
+	signature.PayloadHash = c.Get("X-Amz-Content-Sha256")
+	if signature.PayloadHash == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	if err := signature.parseCredential(credential, amzDate); err != nil {
+		return nil, err
+	}
+
+	return signature, nil
+}
+
+func parsePresigned(c *fiber.Ctx) (*Signature, *Error) {
+	if c.Query("X-Amz-Algorithm") != algorithm {
+		return nil, ErrMalformedAuth
+	}
+
+	expires, err := strconv.Atoi(c.Query("X-Amz-Expires"))
+	if err != nil || expires < 0 || time.Duration(expires)*time.Second > maxPresignTime {
+		return nil, ErrMalformedAuth
+	}
+
+	signature := &Signature{
+		SignedHeaders: strings.Split(c.Query("X-Amz-SignedHeaders"), ";"),
+		Signature:     c.Query("X-Amz-Signature"),
+		PayloadHash:   UnsignedPayload,
+		presigned:     true,
+		expires:       time.Duration(expires) * time.Second,
+	}
+
+	if err := signature.parseCredential(c.Query("X-Amz-Credential"), c.Query("X-Amz-Date")); err != nil {
+		return nil, err
+	}
+
+	return signature, nil
+}
+
+// Credentials look like "AKID/20250101/us-east-1/s3/aws4_request"
+func (s *Signature) parseCredential(credential string, amzDate string) *Error {
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[3] != "s3" || parts[4] != "aws4_request" || s.Signature == "" || len(s.SignedHeaders) == 0 {
+		return ErrMalformedAuth
+	}
+
+	requestTime, err := time.Parse(amzTimeFormat, amzDate)
+	if err != nil {
+		requestTime, err = time.Parse(time.RFC1123, amzDate)
+	}
+	if err != nil || requestTime.UTC().Format("20060102") != parts[1] {
+		return ErrMalformedAuth
+	}
+
+	s.AccessKeyID = parts[0]
+	s.Scope = strings.Join(parts[1:], "/")
+	s.Time = requestTime.UTC()
+	return nil
+}
+
+// Verify checks the signature with the secret of the access key and the time of the request.
+func (s *Signature) Verify(c *fiber.Ctx, secret string, now time.Time) *Error {
+	if s.presigned {
+		if now.After(s.Time.Add(s.expires)) || s.Time.After(now.Add(maxClockSkew)) {
+			return ErrExpiredRequest
+		}
+	} else if now.Sub(s.Time).Abs() > maxClockSkew {
+		return ErrRequestTimeTooSkewed
+	}
+
+	if !strings.Contains(";"+strings.Join(s.SignedHeaders, ";")+";", ";host;") {
+		return ErrMalformedAuth
+	}
+
+	canonicalRequest := strings.Join([]string{
+		c.Method(),
+		canonicalURI(c),
+		canonicalQuery(c),
+		canonicalHeaders(c, s.SignedHeaders),
+		strings.Join(s.SignedHeaders, ";"),
+		s.PayloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		algorithm,
+		s.Time.Format(amzTimeFormat),
+		s.Scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	expected := hex.EncodeToString(hmacSHA256(s.signingKey(secret), stringToSign))
+	if !hmac.Equal([]byte(expected), []byte(s.Signature)) {
+		return ErrSignatureDoesNotMatch
+	}
+
+	return nil
+}
+
+// IsStreaming reports whether the body is aws-chunked, see NewChunkReader.
+func (s *Signature) IsStreaming() bool {
+	switch s.PayloadHash {
+	case StreamingPayload, StreamingPayloadTrailer, StreamingUnsignedPayloadTrailer:
+		return true
+	}
+	return false
+}
+
+func (s *Signature) signingKey(secret string) []byte {
+	parts := strings.Split(s.Scope, "/")
+	key := hmacSHA256([]byte("AWS4"+secret), parts[0])
+	key = hmacSHA256(key, parts[1])
+	key = hmacSHA256(key, parts[2])
+	return hmacSHA256(key, parts[3])
+}
+
+// The path is signed URI-encoded once, with the slashes left as they are
+func canonicalURI(c *fiber.Ctx) string {
+	path := string(c.Request().URI().PathOriginal())
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+	if path == "" {
+		return "/"
+	}
+	return uriEncode(path, false)
+}
+
+func canonicalQuery(c *fiber.Ctx) string {
+	var pairs []string
+	c.Request().URI().QueryArgs().VisitAll(func(key []byte, value []byte) {
+		if string(key) == "X-Amz-Signature" {
+			return
+		}
+		pairs = append(pairs, uriEncode(string(key), true)+"="+uriEncode(string(value), true))
+	})
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func canonicalHeaders(c *fiber.Ctx, signedHeaders []string) string {
+	var builder strings.Builder
+	for _, name := range signedHeaders {
+		var values []string
+		if name == "host" {
+			values = []string{string(c.Request().Host())}
+		} else {
+			for _, value := range c.Request().Header.PeekAll(name) {
+				values = append(values, strings.Join(strings.Fields(string(value)), " "))
+			}
+		}
+		fmt.Fprintf(&builder, "%s:%s\n", name, strings.Join(values, ","))
+	}
+	return builder.String()
+}
+
+// uriEncode encodes everything except the unreserved characters of RFC 3986, like AWS does.
+func uriEncode(value string, encodeSlash bool) string {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		char := value[i]
+		if 'A' <= char && char <= 'Z' || 'a' <= char && char <= 'z' || '0' <= char && char <= '9' ||
+			char == '-' || char == '_' || char == '.' || char == '~' || (char == '/' && !encodeSlash) {
+			builder.WriteByte(char)
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", char)
+		}
+	}
+	return builder.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,255 @@
+// Package search keeps the names, sizes, dates and optionally the text of small files of the host folder in
+// memory for /api/explorer/search. It follows the size index, so it's updated by the same fsnotify events,
+// Refresh calls from the write paths and reconciles.
+package search
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+)
+
+type node struct {
+	isDir    bool
+	size     int64
+	modTime  time.Time
+	content  string // Text of the file if content indexing is on and it's a small text file
+	children map[string]*node
+}
+
+var (
+	mu       sync.RWMutex
+	root     *node
+	ready    bool
+	scanning atomic.Bool
+)
+
+func newDir(modTime time.Time) *node {
+	return &node{isDir: true, modTime: modTime, children: make(map[string]*node)}
+}
+
+// Start follows the size index. Call it before sizeindex.Start, its first scan builds this index too.
+func Start() {
+	sizeindex.OnChange(func(path string) {
+		if filepath.Clean(path) == filepath.Clean(config.Config.Folder) {
+			// Full scans read the text of files, they don't hold up the size index
+			go func() {
+				if scanning.CompareAndSwap(false, true) {
+					defer scanning.Store(false)
+					Scan()
+				}
+			}()
+			return
+		}
+		Refresh(path)
+	})
+}
+
+// Ready reports whether the first scan is done.
+func Ready() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return ready
+}
+
+// Scan builds the whole index from the disk and replaces the old one. The text of unchanged files is reused.
+func Scan() {
+	scanned := scan(config.Config.Folder, []string{})
+
+	mu.Lock()
+	root = scanned
+	ready = true
+	mu.Unlock()
+}
+
+// Refresh updates the path and everything below it from the disk, paths are like "host/mert/notes.txt".
+func Refresh(path string) {
+	segments, ok := relativeSegments(path)
+	if !ok || !Ready() {
+		return
+	}
+
+	if len(segments) > 0 && utils.IsTempFile(segments[len(segments)-1]) {
+		return
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		mu.Lock()
+		remove(segments)
+		mu.Unlock()
+		return
+	}
+
+	var item *node
+	if info.IsDir() {
+		item = scan(path, segments)
+	} else {
+		item = newFile(path, segments, info)
+	}
+
+	mu.Lock()
+	set(segments, item)
+	mu.Unlock()
+}
+
+// scan walks the directory without holding the lock. segments is the place of the directory in the index.
+func scan(dirPath string, segments []string) *node {
+	var modTime time.Time
+	if info, err := os.Stat(dirPath); err == nil {
+		modTime = info.ModTime()
+	}
+	scanned := newDir(modTime)
+
+	filepath.WalkDir(dirPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || path == dirPath {
+			// Unreadable items are skipped, like removed files during the walk
+			if err != nil && entry != nil && entry.IsDir() && path != dirPath {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if utils.IsTempFile(entry.Name()) {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dirPath, path)
+		if err != nil {
+			return nil
+		}
+		relSegments := strings.Split(filepath.ToSlash(relPath), "/")
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		if entry.IsDir() {
+			insert(scanned, relSegments, newDir(info.ModTime()))
+			return nil
+		}
+
+		insert(scanned, relSegments, newFile(path, append(append([]string{}, segments...), relSegments...), info))
+		return nil
+	})
+
+	return scanned
+}
+
+func newFile(path string, segments []string, info fs.FileInfo) *node {
+	item := &node{size: info.Size(), modTime: info.ModTime()}
+
+	if !config.Config.Search.IndexContent || info.Size() > config.Config.Search.GetMaxContentSize() || !info.Mode().IsRegular() {
+		return item
+	}
+
+	mu.RLock()
+	old := lookup(segments)
+	mu.RUnlock()
+
+	if old != nil && !old.isDir && old.size == item.size && old.modTime.Equal(item.modTime) {
+		item.content = old.content
+		return item
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || !isText(data) {
+		return item
+	}
+
+	item.content = string(data)
+	return item
+}
+
+// isText skips binary files, their bytes would match random searches.
+func isText(data []byte) bool {
+	return len(data) > 0 && utf8.Valid(data) && !bytes.ContainsRune(data, 0)
+}
+
+// lookup returns the item at segments or nil. mu must be held.
+func lookup(segments []string) *node {
+	current := root
+	for _, segment := range segments {
+		if current == nil {
+			return nil
+		}
+		current = current.children[segment]
+	}
+	return current
+}
+
+// insert adds a new item below dir. Only used while scanning, the parents are walked before their children.
+func insert(dir *node, segments []string, item *node) {
+	current := dir
+	for _, segment := range segments[:len(segments)-1] {
+		child := current.children[segment]
+		if child == nil {
+			return
+		}
+		current = child
+	}
+	current.children[segments[len(segments)-1]] = item
+}
+
+// set replaces the item at segments. mu must be held.
+func set(segments []string, item *node) {
+	if len(segments) == 0 {
+		root = item
+		return
+	}
+
+	current := root
+	for _, segment := range segments[:len(segments)-1] {
+		child := current.children[segment]
+		if child == nil || !child.isDir {
+			// The parent was created without an event yet
+			child = newDir(time.Now())
+			current.children[segment] = child
+		}
+		current = child
+	}
+	current.children[segments[len(segments)-1]] = item
+}
+
+// remove deletes the item at segments. mu must be held.
+func remove(segments []string) {
+	if len(segments) == 0 {
+		root = newDir(time.Now())
+		return
+	}
+
+	parent := lookup(segments[:len(segments)-1])
+	if parent != nil && parent.isDir {
+		delete(parent.children, segments[len(segments)-1])
+	}
+}
+
+// relativeSegments splits the path relative to the host folder, false means it's outside of it.
+func relativeSegments(path string) ([]string, bool) {
+	relPath, err := filepath.Rel(filepath.Clean(config.Config.Folder), filepath.Clean(path))
+	if err != nil {
+		return nil, false
+	}
+
+	relPath = filepath.ToSlash(relPath)
+	if relPath == "." {
+		return []string{}, true
+	}
+
+	if relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return nil, false
+	}
+
+	return strings.Split(relPath, "/"), true
+}
@@ -0,0 +1,185 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ModeSubstring = "substring"
+	ModeGlob      = "glob"
+	ModeRegex     = "regex"
+
+	TypeFile      = "file"
+	TypeDirectory = "directory"
+)
+
+var (
+	ErrInvalidQuery = errors.New("invalid search query")
+	ErrNotFound     = errors.New("directory is not in the index")
+)
+
+// Query filters the items below a directory. Zero values don't filter.
+type Query struct {
+	Pattern string
+	// Substring matches are case insensitive. Glob patterns with a "/" match the path below the searched
+	// directory, others match the name. Regular expressions match the name.
+	Mode           string
+	Content        bool // Also match the text of indexed files, not with glob patterns
+	Type           string
+	MinSize        int64 // Sizes only filter files
+	MaxSize        int64
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+}
+
+// Result is an item below the searched directory, Path is relative to it like "/docs/notes.txt".
+type Result struct {
+	Path           string
+	IsDirectory    bool
+	Size           int64
+	ModTime        time.Time
+	ContentMatched bool // The name didn't match, only the text of the file
+}
+
+type matcher struct {
+	query   Query
+	name    func(name string, itemPath string) bool
+	content *regexp.Regexp
+}
+
+func newMatcher(query Query) (*matcher, error) {
+	m := &matcher{query: query}
+
+	switch query.Mode {
+	case "", ModeSubstring:
+		pattern := regexp.MustCompile("(?i)" + regexp.QuoteMeta(query.Pattern))
+		m.name = func(name string, _ string) bool { return pattern.MatchString(name) }
+		m.content = pattern
+	case ModeGlob:
+		if _, err := path.Match(query.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+		if query.Content {
+			return nil, fmt.Errorf("%w: glob patterns can't search the content", ErrInvalidQuery)
+		}
+		matchPath := strings.Contains(query.Pattern, "/")
+		m.name = func(name string, itemPath string) bool {
+			if matchPath {
+				ok, _ := path.Match(strings.TrimPrefix(query.Pattern, "/"), strings.TrimPrefix(itemPath, "/"))
+				return ok
+			}
+			ok, _ := path.Match(query.Pattern, name)
+			return ok
+		}
+	case ModeRegex:
+		pattern, err := regexp.Compile(query.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+		m.name = func(name string, _ string) bool { return pattern.MatchString(name) }
+		m.content = pattern
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", ErrInvalidQuery, query.Mode)
+	}
+
+	switch query.Type {
+	case "", TypeFile, TypeDirectory:
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidQuery, query.Type)
+	}
+
+	return m, nil
+}
+
+// filter checks everything except the pattern.
+func (m *matcher) filter(item *node) bool {
+	q := m.query
+
+	if (q.Type == TypeFile && item.isDir) || (q.Type == TypeDirectory && !item.isDir) {
+		return false
+	}
+	if !item.isDir && ((q.MinSize > 0 && item.size < q.MinSize) || (q.MaxSize > 0 && item.size > q.MaxSize)) {
+		return false
+	}
+	if (!q.ModifiedAfter.IsZero() && item.modTime.Before(q.ModifiedAfter)) ||
+		(!q.ModifiedBefore.IsZero() && item.modTime.After(q.ModifiedBefore)) {
+		return false
+	}
+	return true
+}
+
+// Search returns a page of the items below dirPath that match the query and the number of all matches.
+// allowed is called for every match, the results are sorted by path so pages stay stable.
+func Search(dirPath string, query Query, allowed func(result Result) bool, offset int, limit int) ([]Result, int, error) {
+	m, err := newMatcher(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	segments, ok := relativeSegments(dirPath)
+	if !ok {
+		return nil, 0, ErrInvalidQuery
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	dir := lookup(segments)
+	if dir == nil || !dir.isDir {
+		return nil, 0, ErrNotFound
+	}
+
+	var (
+		results []Result
+		total   int
+	)
+
+	var walk func(dir *node, dirItemPath string)
+	walk = func(dir *node, dirItemPath string) {
+		names := make([]string, 0, len(dir.children))
+		for name := range dir.children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			item := dir.children[name]
+			itemPath := dirItemPath + "/" + name
+
+			if m.filter(item) {
+				nameMatched := m.name(name, itemPath)
+				contentMatched := !nameMatched && query.Content && item.content != "" && m.content.MatchString(item.content)
+
+				if nameMatched || contentMatched {
+					result := Result{
+						Path:           itemPath,
+						IsDirectory:    item.isDir,
+						Size:           item.size,
+						ModTime:        item.modTime,
+						ContentMatched: contentMatched,
+					}
+
+					if allowed(result) {
+						if total >= offset && len(results) < limit {
+							results = append(results, result)
+						}
+						total++
+					}
+				}
+			}
+
+			if item.isDir {
+				walk(item, itemPath)
+			}
+		}
+	}
+	walk(dir, "")
+
+	return results, total, nil
+}
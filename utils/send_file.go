@@ -0,0 +1,304 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Dispositions of SendFile. Inline lets the browser preview images, videos and PDFs.
+const (
+	DispositionAttachment = "attachment"
+	DispositionInline     = "inline"
+)
+
+// More ranges than this are answered with the whole file, clients don't need them and they are expensive.
+const maxRanges = 32
+
+type byteRange struct {
+	start  int64
+	length int64
+}
+
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+// SendFile streams a file with Range, If-Range, ETag and Last-Modified support. It answers 304, 206, 412 and 416
+// on its own. Multiple ranges are sent as multipart/byteranges.
+func SendFile(c *fiber.Ctx, path string, fileinfo os.FileInfo, disposition string) error {
+	modTime := fileinfo.ModTime().UTC().Truncate(time.Second)
+	etag := ETag(fileinfo)
+
+	c.Set("ETag", etag)
+	c.Set("Last-Modified", modTime.Format(http.TimeFormat))
+	c.Set("Accept-Ranges", "bytes")
+
+	if status := checkConditions(c, etag, modTime); status != 0 {
+		return c.SendStatus(status)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't open file"})
+	}
+
+	contentType, err := detectContentType(path, file)
+	if err != nil {
+		file.Close()
+		return c.Status(500).JSON(fiber.Map{"err": "Couldn't read file"})
+	}
+
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": fileinfo.Name()}))
+	c.Set("X-Content-Type-Options", "nosniff")
+
+	size := fileinfo.Size()
+	ranges, err := parseRange(c.Get("Range"), size)
+	if err != nil {
+		file.Close()
+		c.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+		return c.SendStatus(416)
+	}
+
+	if !rangeAllowed(c, etag, modTime) || len(ranges) > maxRanges || sumRanges(ranges) > size {
+		ranges = nil
+	}
+
+	switch len(ranges) {
+	case 0:
+		return c.Status(200).SendStream(file, int(size))
+	case 1:
+		if _, err := file.Seek(ranges[0].start, io.SeekStart); err != nil {
+			file.Close()
+			return c.Status(500).JSON(fiber.Map{"err": "Couldn't read file"})
+		}
+		c.Set("Content-Range", ranges[0].contentRange(size))
+		return c.Status(206).SendStream(readCloser{io.LimitReader(file, ranges[0].length), file}, int(ranges[0].length))
+	default:
+		return sendMultipartRanges(c, file, ranges, contentType, size)
+	}
+}
+
+// ETag changes when the file is written, it's the same for every route that sends the file.
+func ETag(fileinfo os.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, fileinfo.ModTime().UnixNano(), fileinfo.Size())
+}
+
+// IsDownloadStart reports whether the request downloads the file from its first byte. Resumed downloads
+// continue with ranges and HEAD requests only check the file, they shouldn't be logged or counted again.
+func IsDownloadStart(c *fiber.Ctx) bool {
+	if c.Method() == fiber.MethodHead {
+		return false
+	}
+
+	rangeHeader := c.Get("Range")
+	return rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-")
+}
+
+// checkConditions returns 304 or 412 if the conditional headers stop the request, otherwise 0.
+// The order follows RFC 9110 section 13.2.2.
+func checkConditions(c *fiber.Ctx, etag string, modTime time.Time) int {
+	if ifMatch := c.Get("If-Match"); ifMatch != "" {
+		if !etagListMatches(ifMatch, etag) {
+			return 412
+		}
+	} else if since, err := http.ParseTime(c.Get("If-Unmodified-Since")); err == nil && modTime.After(since) {
+		return 412
+	}
+
+	if ifNoneMatch := c.Get("If-None-Match"); ifNoneMatch != "" {
+		if etagListMatches(ifNoneMatch, etag) {
+			return 304
+		}
+	} else if since, err := http.ParseTime(c.Get("If-Modified-Since")); err == nil && !modTime.After(since) {
+		return 304
+	}
+
+	return 0
+}
+
+// rangeAllowed checks If-Range, ranges of a changed file must not be combined with what the client has.
+func rangeAllowed(c *fiber.Ctx, etag string, modTime time.Time) bool {
+	ifRange := c.Get("If-Range")
+	if ifRange == "" {
+		return true
+	}
+
+	if strings.HasPrefix(ifRange, `"`) {
+		return ifRange == etag
+	}
+
+	since, err := http.ParseTime(ifRange)
+	return err == nil && modTime.Equal(since)
+}
+
+func etagListMatches(list string, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRange reads a Range header like "bytes=0-499,-500". Ranges outside the file are dropped,
+// an error means none of them can be satisfied.
+func parseRange(header string, size int64) ([]byteRange, error) {
+	if header == "" {
+		return nil, nil
+	}
+
+	specs, found := strings.CutPrefix(header, "bytes=")
+	if !found {
+		// Other units are ignored like a missing header
+		return nil, nil
+	}
+
+	var ranges []byteRange
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		startText, endText, found := strings.Cut(spec, "-")
+		if !found {
+			return nil, errors.New("invalid range")
+		}
+
+		var r byteRange
+		if startText == "" {
+			// Suffix range, the last n bytes
+			suffix, err := strconv.ParseInt(endText, 10, 64)
+			if err != nil || suffix < 0 {
+				return nil, errors.New("invalid range")
+			}
+			if suffix == 0 {
+				continue
+			}
+			suffix = min(suffix, size)
+			r = byteRange{start: size - suffix, length: suffix}
+		} else {
+			start, err := strconv.ParseInt(startText, 10, 64)
+			if err != nil || start < 0 {
+				return nil, errors.New("invalid range")
+			}
+			if start >= size {
+				continue
+			}
+
+			end := size - 1
+			if endText != "" {
+				end, err = strconv.ParseInt(endText, 10, 64)
+				if err != nil || end < start {
+					return nil, errors.New("invalid range")
+				}
+				end = min(end, size-1)
+			}
+			r = byteRange{start: start, length: end - start + 1}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		return nil, errors.New("no satisfiable range")
+	}
+
+	return ranges, nil
+}
+
+func sumRanges(ranges []byteRange) int64 {
+	var sum int64
+	for _, r := range ranges {
+		sum += r.length
+	}
+	return sum
+}
+
+func detectContentType(path string, file *os.File) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType, nil
+	}
+
+	buffer := make([]byte, 512)
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return http.DetectContentType(buffer[:n]), nil
+}
+
+func sendMultipartRanges(c *fiber.Ctx, file *os.File, ranges []byteRange, contentType string, size int64) error {
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	partHeader := func(r byteRange) textproto.MIMEHeader {
+		return textproto.MIMEHeader{
+			"Content-Range": {r.contentRange(size)},
+			"Content-Type":  {contentType},
+		}
+	}
+
+	// The length is counted with the same boundary first, so the response can have a Content-Length
+	var counter countingWriter
+	countWriter := multipart.NewWriter(&counter)
+	countWriter.SetBoundary(boundary)
+	for _, r := range ranges {
+		countWriter.CreatePart(partHeader(r))
+	}
+	countWriter.Close()
+	length := int64(counter) + sumRanges(ranges)
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		defer file.Close()
+
+		writer := multipart.NewWriter(pipeWriter)
+		writer.SetBoundary(boundary)
+		for _, r := range ranges {
+			part, err := writer.CreatePart(partHeader(r))
+			if err != nil {
+				pipeWriter.CloseWithError(err)
+				return
+			}
+			if _, err := io.Copy(part, io.NewSectionReader(file, r.start, r.length)); err != nil {
+				pipeWriter.CloseWithError(err)
+				return
+			}
+		}
+		pipeWriter.CloseWithError(writer.Close())
+	}()
+
+	c.Set("Content-Type", "multipart/byteranges; boundary="+boundary)
+	return c.Status(206).SendStream(pipeReader, int(length))
+}
+
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+// readCloser closes the file after fasthttp sent the limited reader.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -45,6 +45,16 @@ func Setup() {
 		}
 	}
 
+	// Unlike tmp, unfinished resumable uploads are kept on restarts
+	if IsNotExistingPath("uploads") {
+		fmt.Println("Creating /uploads folder...")
+		err := os.Mkdir("uploads", 0700)
+
+		if err != nil {
+			log.Fatalf("Error creating uploads folder!")
+		}
+	}
+
 	if IsNotExistingPath("recovery_bin") {
 		fmt.Println("Creating /recovery_bin folder...")
 		err := os.Mkdir("recovery_bin", 0700)
@@ -53,4 +63,13 @@ func Setup() {
 			log.Fatalf("Error creating recovery_bin folder!")
 		}
 	}
+
+	if IsNotExistingPath("versions") {
+		fmt.Println("Creating /versions folder...")
+		err := os.Mkdir("versions", 0700)
+
+		if err != nil {
+			log.Fatalf("Error creating versions folder!")
+		}
+	}
 }
@@ -0,0 +1,79 @@
+package sftpserver
+
+import (
+	"errors"
+	"net"
+
+	"github.com/MertJSX/folder-host-go/database/sftpkeys"
+	"github.com/MertJSX/folder-host-go/database/twofactor"
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/utils/bruteforce"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	errTooManyAttempts   = errors.New("too many attempts")
+	errWrongPassword     = errors.New("wrong username or password")
+	errTwoFactorRequired = errors.New("accounts with two factor authentication must use a public key")
+	errUnknownPublicKey  = errors.New("unknown public key")
+)
+
+// checkPassword counts failures like the login route. Accounts with two factor authentication can't log in
+// with a password, a second factor can't be asked for.
+func checkPassword(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+	username := conn.User()
+	ip := remoteIP(conn)
+
+	if bruteforce.Check(ip, username) > 0 {
+		return nil, errTooManyAttempts
+	}
+
+	account, err := users.GetUserByUsername(username)
+	if err != nil {
+		bruteforce.RegisterFailure(ip, username, false, "account not found")
+		return nil, errWrongPassword
+	}
+
+	if !users.VerifyPassword(&account, string(password)) {
+		bruteforce.RegisterFailure(ip, username, true, "wrong password")
+		return nil, errWrongPassword
+	}
+
+	if err := twofactor.CheckSecondFactor(&account, ""); err != nil {
+		return nil, errTwoFactorRequired
+	}
+
+	bruteforce.RegisterSuccess(account.Username)
+
+	return &ssh.Permissions{Extensions: map[string]string{
+		"username": account.Username,
+		"method":   "password",
+	}}, nil
+}
+
+// checkPublicKey accepts the keys the user added. Clients offer every key they have, so unknown keys
+// aren't counted as failures.
+func checkPublicKey(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	username := conn.User()
+
+	if bruteforce.Check(remoteIP(conn), username) > 0 {
+		return nil, errTooManyAttempts
+	}
+
+	if _, err := sftpkeys.GetSFTPKeyByFingerprint(username, ssh.FingerprintSHA256(key)); err != nil {
+		return nil, errUnknownPublicKey
+	}
+
+	return &ssh.Permissions{Extensions: map[string]string{
+		"username": username,
+		"method":   "public key",
+	}}, nil
+}
+
+func remoteIP(conn ssh.ConnMetadata) string {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return conn.RemoteAddr().String()
+	}
+	return host
+}
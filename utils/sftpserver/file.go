@@ -0,0 +1,95 @@
+package sftpserver
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+	"github.com/MertJSX/folder-host-go/utils/versions"
+)
+
+// writeFile writes to a temporary file next to the target. Close moves it into place, or removes it if
+// the session ended early or the account ran out of space.
+type writeFile struct {
+	*os.File
+	handlers  *handlers
+	name      string
+	finalPath string
+	limit     int64 // The size the file can grow to
+
+	mu  sync.Mutex
+	err error
+}
+
+// copyExisting starts the temporary file with the old content, for clients that append or write parts of it.
+func (f *writeFile) copyExisting() error {
+	existing, err := os.Open(f.finalPath)
+	if err != nil {
+		return err
+	}
+	defer existing.Close()
+
+	if _, err := io.Copy(f.File, existing); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (f *writeFile) WriteAt(p []byte, offset int64) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return 0, f.err
+	}
+
+	if offset+int64(len(p)) > f.limit {
+		f.err = utils.ErrQuotaExceeded
+		return 0, f.err
+	}
+
+	n, err := f.File.WriteAt(p, offset)
+	if err != nil {
+		f.err = f.handlers.fxError(err)
+		return n, f.err
+	}
+	return n, nil
+}
+
+// TransferError is called when the session ends before the client closed the handle.
+func (f *writeFile) TransferError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err == nil {
+		f.err = err
+	}
+}
+
+func (f *writeFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tempPath := f.File.Name()
+	err := f.File.Close()
+	if err == nil {
+		err = f.err
+	}
+
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	if _, err := versions.Replace(f.handlers.account.Username, tempPath, f.finalPath, utils.ConflictOverwrite); err != nil {
+		os.Remove(tempPath)
+		return f.handlers.fxError(err)
+	}
+
+	sizeindex.Refresh(f.finalPath)
+	logSFTP(f.handlers.account, "Upload", fmt.Sprintf("uploaded a %s file", f.name))
+	return nil
+}
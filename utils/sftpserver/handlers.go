@@ -0,0 +1,312 @@
+package sftpserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path"
+
+	"github.com/MertJSX/folder-host-go/database/logs"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/acl"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/recoverybin"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+	"github.com/pkg/sftp"
+)
+
+var errExists = errors.New("file already exists")
+
+// handlers serves the scope of an account. The request server cleans the paths, so they are always
+// absolute and can't leave the scope, like "/docs/notes.txt".
+type handlers struct {
+	account types.Account
+	root    string // Like "./host/mert"
+}
+
+func NewHandlers(account types.Account) sftp.Handlers {
+	h := &handlers{
+		account: account,
+		root:    config.Config.GetScopedFolder(account.Scope),
+	}
+	return sftp.Handlers{FileGet: h, FilePut: h, FileCmd: h, FileList: h}
+}
+
+func (h *handlers) diskPath(name string) string {
+	return h.root + name
+}
+
+func (h *handlers) check(permission string, name string) error {
+	if !utils.IsSafePath(name) || !acl.Allowed(h.account, permission, acl.NormalizePath(name)) {
+		return sftp.ErrSSHFxPermissionDenied
+	}
+	return nil
+}
+
+func (h *handlers) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	if err := h.check(types.PermissionDownloadFiles, r.Filepath); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(h.diskPath(r.Filepath))
+	if err != nil {
+		return nil, h.fxError(err)
+	}
+
+	if stat, err := file.Stat(); err != nil || stat.IsDir() {
+		file.Close()
+		return nil, sftp.ErrSSHFxFailure
+	}
+
+	logSFTP(h.account, "Download", fmt.Sprintf("downloaded a %s file", r.Filepath))
+	return file, nil
+}
+
+// Filewrite writes to a temporary file, the existing file stays untouched until the client closes the handle.
+func (h *handlers) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	if r.Filepath == "/" {
+		return nil, sftp.ErrSSHFxPermissionDenied
+	}
+
+	if err := h.check(types.PermissionUploadFiles, r.Filepath); err != nil {
+		return nil, err
+	}
+
+	flags := r.Pflags()
+	finalPath := h.diskPath(r.Filepath)
+
+	var existingSize int64
+	stat, err := os.Stat(finalPath)
+	switch {
+	case err == nil && stat.IsDir():
+		return nil, sftp.ErrSSHFxFailure
+	case err == nil && flags.Excl:
+		return nil, errExists
+	case err == nil:
+		existingSize = stat.Size()
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, h.fxError(err)
+	}
+
+	remaining, err := utils.GetRemainingSpace(h.account)
+	if err != nil {
+		return nil, h.fxError(err)
+	}
+
+	// The old content is replaced, so its space is available to the new one. Unlimited stays unlimited.
+	limit := remaining
+	if remaining <= math.MaxInt64-existingSize {
+		limit = remaining + existingSize
+	}
+
+	temp, err := utils.CreateTempFile(finalPath)
+	if err != nil {
+		return nil, h.fxError(err)
+	}
+
+	file := &writeFile{
+		File:      temp,
+		handlers:  h,
+		name:      r.Filepath,
+		finalPath: finalPath,
+		limit:     limit,
+	}
+
+	if existingSize > 0 && !flags.Trunc {
+		if err := file.copyExisting(); err != nil {
+			temp.Close()
+			os.Remove(temp.Name())
+			return nil, h.fxError(err)
+		}
+	}
+
+	return file, nil
+}
+
+func (h *handlers) Filecmd(r *sftp.Request) error {
+	switch r.Method {
+	case "Setstat":
+		// Times and modes are managed by the server, clients set them after uploads
+		return nil
+	case "Mkdir":
+		return h.mkdir(r.Filepath)
+	case "Remove", "Rmdir":
+		return h.remove(r.Filepath, r.Method == "Rmdir")
+	case "Rename":
+		return h.rename(r.Filepath, r.Target)
+	}
+	return sftp.ErrSSHFxOpUnsupported
+}
+
+func (h *handlers) mkdir(name string) error {
+	if err := h.check(types.PermissionCreate, name); err != nil {
+		return err
+	}
+
+	if err := os.Mkdir(h.diskPath(name), 0777); err != nil {
+		return h.fxError(err)
+	}
+
+	sizeindex.Refresh(h.diskPath(name))
+	logSFTP(h.account, "Create folder", fmt.Sprintf("created a %s folder", name))
+	return nil
+}
+
+// remove uses the recovery bin like the delete route.
+func (h *handlers) remove(name string, isDir bool) error {
+	if name == "/" {
+		return sftp.ErrSSHFxPermissionDenied
+	}
+
+	if err := h.check(types.PermissionDelete, name); err != nil {
+		return err
+	}
+
+	stat, err := os.Stat(h.diskPath(name))
+	if err != nil {
+		return h.fxError(err)
+	}
+	if stat.IsDir() != isDir {
+		return sftp.ErrSSHFxFailure
+	}
+
+	movedToBin, err := recoverybin.Delete(h.account.Username, h.diskPath(name))
+	if errors.Is(err, recoverybin.ErrBinFull) {
+		return err
+	}
+	if err != nil && !errors.Is(err, recoverybin.ErrRecordFailed) {
+		return h.fxError(err)
+	}
+	if err != nil {
+		log.Printf("Error: %v\n", err)
+	}
+
+	if movedToBin {
+		logSFTP(h.account, "Delete", fmt.Sprintf("moved %s to recovery_bin", name))
+	} else if isDir {
+		logSFTP(h.account, "Delete", fmt.Sprintf("permanently deleted a %s directory", name))
+	} else {
+		logSFTP(h.account, "Delete", fmt.Sprintf("permanently deleted a %s file", name))
+	}
+	return nil
+}
+
+// rename needs the rename permission inside the same folder and the move permission between folders.
+// Like SFTP clients expect, existing targets aren't replaced.
+func (h *handlers) rename(oldName string, newName string) error {
+	if oldName == "/" || newName == "/" {
+		return sftp.ErrSSHFxPermissionDenied
+	}
+
+	action, permission := "Move", types.PermissionMove
+	if path.Dir(oldName) == path.Dir(newName) {
+		action, permission = "Rename", types.PermissionRename
+	}
+
+	if err := h.check(permission, oldName); err != nil {
+		return err
+	}
+	if err := h.check(permission, newName); err != nil {
+		return err
+	}
+
+	if _, err := os.Lstat(h.diskPath(newName)); err == nil {
+		return errExists
+	}
+
+	if err := os.Rename(h.diskPath(oldName), h.diskPath(newName)); err != nil {
+		return h.fxError(err)
+	}
+
+	sizeindex.Refresh(h.diskPath(oldName))
+	sizeindex.Refresh(h.diskPath(newName))
+
+	if action == "Move" {
+		logSFTP(h.account, action, fmt.Sprintf("moved an item %s -> %s", oldName, newName))
+	} else {
+		logSFTP(h.account, action, fmt.Sprintf("renamed an item %s -> %s", oldName, newName))
+	}
+	return nil
+}
+
+func (h *handlers) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	if err := h.check(types.PermissionReadDirectories, r.Filepath); err != nil {
+		return nil, err
+	}
+
+	switch r.Method {
+	case "List":
+		entries, err := os.ReadDir(h.diskPath(r.Filepath))
+		if err != nil {
+			return nil, h.fxError(err)
+		}
+
+		var list listerAt
+		for _, entry := range entries {
+			if utils.IsTempFile(entry.Name()) {
+				continue
+			}
+			if h.check(types.PermissionReadDirectories, path.Join(r.Filepath, entry.Name())) != nil {
+				continue
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			list = append(list, info)
+		}
+		return list, nil
+	case "Stat", "Lstat":
+		info, err := os.Stat(h.diskPath(r.Filepath))
+		if err != nil {
+			return nil, h.fxError(err)
+		}
+		return listerAt{info}, nil
+	}
+	return nil, sftp.ErrSSHFxOpUnsupported
+}
+
+// fxError converts errors to SFTP status codes. Their text is sent to the client, it must not show
+// where the scope is on the disk.
+func (h *handlers) fxError(err error) error {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return sftp.ErrSSHFxNoSuchFile
+	case errors.Is(err, os.ErrPermission):
+		return sftp.ErrSSHFxPermissionDenied
+	case errors.Is(err, os.ErrExist):
+		return errExists
+	case errors.Is(err, utils.ErrQuotaExceeded):
+		return utils.ErrQuotaExceeded
+	}
+
+	log.Printf("SFTP error of %s: %v\n", h.account.Username, err)
+	return sftp.ErrSSHFxFailure
+}
+
+type listerAt []os.FileInfo
+
+func (l listerAt) ListAt(list []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+
+	n := copy(list, l[offset:])
+	if n < len(list) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func logSFTP(account types.Account, action string, description string) {
+	logs.CreateLog(types.AuditLog{
+		Username:    account.Username,
+		Action:      action,
+		Description: fmt.Sprintf("%s %s over SFTP.", account.Username, description),
+	})
+}
@@ -0,0 +1,141 @@
+// Package sftpserver serves the scopes of the users over SFTP. Users log in with the password of their
+// account or with their public keys, every operation is checked and logged like the HTTP routes do.
+package sftpserver
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+
+	"github.com/MertJSX/folder-host-go/database/users"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// Start listens on the configured port and serves SFTP sessions until the listener fails.
+func Start() {
+	sftpConfig := &config.Config.SFTP
+
+	hostKey, err := loadHostKey(sftpConfig.GetHostKey())
+	if err != nil {
+		log.Printf("SFTP server couldn't start: %v\n", err)
+		return
+	}
+
+	serverConfig := &ssh.ServerConfig{
+		PasswordCallback:  checkPassword,
+		PublicKeyCallback: checkPublicKey,
+		ServerVersion:     "SSH-2.0-FolderHost",
+		MaxAuthTries:      6,
+	}
+	serverConfig.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", sftpConfig.GetPort()))
+	if err != nil {
+		log.Printf("SFTP server couldn't start: %v\n", err)
+		return
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("SFTP server stopped: %v\n", err)
+			return
+		}
+		go serveConnection(conn, serverConfig)
+	}
+}
+
+// loadHostKey reads the private key of the server, it's created on the first start. Clients remember it,
+// so it must stay the same across restarts.
+func loadHostKey(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("error generating host key: %w", err)
+		}
+
+		block, err := ssh.MarshalPrivateKey(privateKey, "FolderHost SFTP host key")
+		if err != nil {
+			return nil, fmt.Errorf("error encoding host key: %w", err)
+		}
+
+		data = pem.EncodeToMemory(block)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return nil, fmt.Errorf("error saving host key: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading host key: %w", err)
+	}
+
+	return ssh.ParsePrivateKey(data)
+}
+
+func serveConnection(netConn net.Conn, serverConfig *ssh.ServerConfig) {
+	conn, channels, requests, err := ssh.NewServerConn(netConn, serverConfig)
+	if err != nil {
+		netConn.Close()
+		return
+	}
+	defer conn.Close()
+
+	go ssh.DiscardRequests(requests)
+
+	// Permission changes apply to the next connection, like sessions of the web panel
+	account, err := users.GetUserByUsername(conn.Permissions.Extensions["username"])
+	if err != nil {
+		return
+	}
+
+	logSFTP(account, "Login", fmt.Sprintf("logged in with a %s", conn.Permissions.Extensions["method"]))
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+
+		go serveSession(account, channel, channelRequests)
+	}
+}
+
+// serveSession waits for the sftp subsystem, there is no shell or command execution.
+func serveSession(account types.Account, channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for request := range requests {
+		var subsystem struct{ Name string }
+		if request.Type != "subsystem" || ssh.Unmarshal(request.Payload, &subsystem) != nil || subsystem.Name != "sftp" {
+			request.Reply(false, nil)
+			continue
+		}
+		request.Reply(true, nil)
+
+		go func() {
+			for request := range requests {
+				request.Reply(false, nil)
+			}
+		}()
+
+		server := sftp.NewRequestServer(channel, NewHandlers(account))
+		if err := server.Serve(); err != nil && !errors.Is(err, io.EOF) {
+			log.Printf("SFTP session of %s ended: %v\n", account.Username, err)
+		}
+		server.Close()
+		return
+	}
+}
@@ -0,0 +1,257 @@
+// Package sizeindex keeps the sizes of the host folder in memory, so they don't need a filepath.Walk on every check.
+// It's built with one scan and kept current by fsnotify events, Refresh calls from the write paths
+// and a periodic reconcile against the disk.
+package sizeindex
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/MertJSX/folder-host-go/utils/config"
+)
+
+type node struct {
+	size     int64 // Size of the file, or the total size of everything below a directory
+	isDir    bool
+	children map[string]*node
+}
+
+var (
+	mu        sync.RWMutex
+	root      *node
+	ready     bool
+	listeners []func(path string)
+)
+
+func newDir() *node {
+	return &node{isDir: true, children: make(map[string]*node)}
+}
+
+// Size returns the total size below a directory or the size of a file. Paths are the same as for os.Stat,
+// like "host/mert". The second value is false if the index isn't ready or the path is outside the host folder.
+func Size(path string) (int64, bool) {
+	segments, ok := relativeSegments(path)
+	if !ok {
+		return 0, false
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if !ready {
+		return 0, false
+	}
+
+	current := root
+	for _, segment := range segments {
+		current = current.children[segment]
+		if current == nil {
+			return 0, false
+		}
+	}
+
+	return current.size, true
+}
+
+// Refresh updates the path and everything below it from the disk. Write paths of the server call it,
+// so checks right after a write don't wait for the fsnotify event.
+func Refresh(path string) {
+	segments, ok := relativeSegments(path)
+	if !ok || !isReady() {
+		return
+	}
+	defer notify(path)
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		mu.Lock()
+		remove(segments)
+		mu.Unlock()
+		return
+	}
+
+	if !info.IsDir() {
+		mu.Lock()
+		set(segments, &node{size: info.Size()})
+		mu.Unlock()
+		return
+	}
+
+	scanned := scan(path)
+
+	mu.Lock()
+	set(segments, scanned)
+	mu.Unlock()
+}
+
+// Scan builds the whole index from the disk and replaces the old one.
+func Scan() int64 {
+	scanned := scan(config.Config.Folder)
+
+	mu.Lock()
+	root = scanned
+	ready = true
+	mu.Unlock()
+
+	notify(config.Config.Folder)
+	return scanned.size
+}
+
+// OnChange registers a function that is called with the path after every Refresh and with the host folder
+// after every Scan. Other indexes use it to follow the same changes. Call it before Start.
+func OnChange(listener func(path string)) {
+	listeners = append(listeners, listener)
+}
+
+func notify(path string) {
+	for _, listener := range listeners {
+		listener(path)
+	}
+}
+
+func isReady() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return ready
+}
+
+// scan walks the directory without holding the lock and adds fsnotify watches for its directories.
+func scan(dirPath string) *node {
+	scanned := newDir()
+
+	filepath.WalkDir(dirPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable items are skipped, like removed files during the walk
+			if entry != nil && entry.IsDir() && path != dirPath {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dirPath, path)
+		if err != nil || relPath == "." {
+			if entry.IsDir() {
+				watch(path)
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			watch(path)
+			insert(scanned, strings.Split(filepath.ToSlash(relPath), "/"), newDir())
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		insert(scanned, strings.Split(filepath.ToSlash(relPath), "/"), &node{size: info.Size()})
+		return nil
+	})
+
+	return scanned
+}
+
+// insert adds a new item below dir and adds its size to every directory on the way. Only used while scanning.
+func insert(dir *node, segments []string, item *node) {
+	current := dir
+	for _, segment := range segments[:len(segments)-1] {
+		current.size += item.size
+		child := current.children[segment]
+		if child == nil {
+			child = newDir()
+			current.children[segment] = child
+		}
+		current = child
+	}
+	current.size += item.size
+	current.children[segments[len(segments)-1]] = item
+}
+
+// set replaces the item at segments and fixes the sizes of its parents. mu must be held.
+func set(segments []string, item *node) {
+	if len(segments) == 0 {
+		root = item
+		return
+	}
+
+	parents := make([]*node, 0, len(segments))
+	current := root
+	for _, segment := range segments[:len(segments)-1] {
+		parents = append(parents, current)
+		child := current.children[segment]
+		if child == nil || !child.isDir {
+			if child != nil {
+				addToParents(parents, -child.size)
+			}
+			child = newDir()
+			current.children[segment] = child
+		}
+		current = child
+	}
+	parents = append(parents, current)
+
+	name := segments[len(segments)-1]
+	var delta int64 = item.size
+	if old := current.children[name]; old != nil {
+		delta -= old.size
+	}
+	current.children[name] = item
+
+	addToParents(parents, delta)
+}
+
+// remove deletes the item at segments and fixes the sizes of its parents. mu must be held.
+func remove(segments []string) {
+	if len(segments) == 0 {
+		root = newDir()
+		return
+	}
+
+	parents := make([]*node, 0, len(segments))
+	current := root
+	for _, segment := range segments[:len(segments)-1] {
+		parents = append(parents, current)
+		current = current.children[segment]
+		if current == nil {
+			return
+		}
+	}
+	parents = append(parents, current)
+
+	name := segments[len(segments)-1]
+	if old := current.children[name]; old != nil {
+		delete(current.children, name)
+		addToParents(parents, -old.size)
+	}
+}
+
+func addToParents(parents []*node, delta int64) {
+	for _, parent := range parents {
+		parent.size += delta
+	}
+}
+
+// relativeSegments splits the path relative to the host folder, false means it's outside of it.
+func relativeSegments(path string) ([]string, bool) {
+	relPath, err := filepath.Rel(filepath.Clean(config.Config.Folder), filepath.Clean(path))
+	if err != nil {
+		return nil, false
+	}
+
+	relPath = filepath.ToSlash(relPath)
+	if relPath == "." {
+		return []string{}, true
+	}
+
+	if relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return nil, false
+	}
+
+	return strings.Split(relPath, "/"), true
+}
@@ -0,0 +1,81 @@
+package sizeindex
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/fsnotify/fsnotify"
+)
+
+var (
+	watcher         *fsnotify.Watcher
+	watchFailedOnce sync.Once
+)
+
+// Start does the initial scan and keeps the index current in the background.
+func Start() {
+	var err error
+	watcher, err = fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("Size index watcher creation error, only reconciles will update the index: %v\n", err)
+	}
+
+	Scan()
+
+	if watcher != nil {
+		go listen()
+	}
+	go reconcile()
+}
+
+func watch(path string) {
+	if watcher == nil {
+		return
+	}
+
+	if err := watcher.Add(path); err != nil {
+		// Usually the inotify watch limit. Reconciles still fix the index, but slower.
+		watchFailedOnce.Do(func() {
+			log.Printf("Size index can't watch all directories, changes made outside FolderHost are picked up by the periodic reconcile: %v\n", err)
+		})
+	}
+}
+
+func listen() {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			// Removed and renamed items are checked with Lstat, so every event is a Refresh
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			Refresh(event.Name)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Size index watcher error: %v\n", err)
+		}
+	}
+}
+
+func reconcile() {
+	ticker := time.NewTicker(config.Config.GetSizeIndexReconcileInterval())
+	defer ticker.Stop()
+
+	for range ticker.C {
+		before, _ := Size(config.Config.Folder)
+		after := Scan()
+
+		if before != after {
+			log.Printf("Size index reconciled, the drift was %d bytes.\n", after-before)
+		}
+	}
+}
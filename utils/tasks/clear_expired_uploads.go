@@ -0,0 +1,84 @@
+package tasks
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/multipart"
+	"github.com/MertJSX/folder-host-go/database/uploads"
+	"github.com/MertJSX/folder-host-go/utils/s3"
+	"github.com/MertJSX/folder-host-go/utils/tus"
+)
+
+func AutoClearExpiredUploads() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if err := clearExpiredUploads(); err != nil {
+			fmt.Printf("Error while clearing expired uploads: %s\n", err)
+		}
+		<-ticker.C
+	}
+}
+
+func clearExpiredUploads() error {
+	expired, err := uploads.GetExpiredUploads()
+	if err != nil {
+		return err
+	}
+
+	for _, upload := range expired {
+		unlock := tus.Lock(upload.ID)
+		if err := uploads.DeleteUpload(upload.ID); err == nil {
+			tus.Remove(upload.ID)
+		}
+		unlock()
+	}
+
+	expiredMultipart, err := multipart.GetExpiredMultipartUploads()
+	if err != nil {
+		return err
+	}
+
+	for _, upload := range expiredMultipart {
+		unlock := tus.Lock(upload.ID)
+		if err := multipart.DeleteMultipartUpload(upload.ID); err == nil {
+			s3.RemoveUpload(upload.ID)
+		}
+		unlock()
+	}
+
+	// Files are listed before the records are read, records are always created before their files and folders.
+	// What is left has lost its record, for example when the user was removed.
+	entries, err := os.ReadDir(tus.Folder)
+	if err != nil {
+		return err
+	}
+
+	ids, err := uploads.GetUploadIDs()
+	if err != nil {
+		return err
+	}
+
+	multipartIDs, err := multipart.GetMultipartUploadIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if ids[entry.Name()] || multipartIDs[entry.Name()] {
+			continue
+		}
+
+		// Multipart uploads of S3 keep their parts in a folder
+		if entry.IsDir() {
+			s3.RemoveUpload(entry.Name())
+		} else {
+			tus.Remove(entry.Name())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/utils/jobs"
+)
+
+// AutoClearFinishedJobs removes jobs that finished longer ago than keep_days of the jobs config.
+func AutoClearFinishedJobs() {
+	ticker := time.NewTicker(6 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if err := jobs.ClearFinished(); err != nil {
+			fmt.Printf("%s\n", err)
+		}
+		<-ticker.C
+	}
+}
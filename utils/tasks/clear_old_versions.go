@@ -0,0 +1,24 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/versions"
+)
+
+// AutoClearOldVersions applies keep_days and the storage limit of versioning, keep_last is applied on every snapshot.
+func AutoClearOldVersions() {
+	ticker := time.NewTicker(6 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if config.Config.Versioning.Enabled {
+			if err := versions.Clear(); err != nil {
+				fmt.Printf("Error while clearing old versions: %s\n", err)
+			}
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,20 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/tokens"
+)
+
+func AutoClearExpiredRefreshTokens() {
+	ticker := time.NewTicker(6 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if err := tokens.ClearExpiredRefreshTokens(); err != nil {
+			fmt.Printf("Error while clearing expired refresh tokens: %s\n", err)
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,254 @@
+// Package textdiff creates unified diffs of text files, like "diff -u".
+package textdiff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Context is the number of unchanged lines around the changes
+const Context = 3
+
+// MaxChanges keeps the memory of a diff small, the Myers algorithm stores every step
+const MaxChanges = 2000
+
+var ErrTooManyChanges = errors.New("too many changes to compare")
+
+type operation int
+
+const (
+	equal operation = iota
+	deleted
+	inserted
+)
+
+type edit struct {
+	op   operation
+	line string
+}
+
+// Unified returns the unified diff of two texts. It's empty if they are the same.
+func Unified(fromName string, toName string, from string, to string) (string, error) {
+	if from == to {
+		return "", nil
+	}
+
+	edits, err := diffLines(splitLines(from), splitLines(to))
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	writeHunks(&out, edits)
+	return out.String(), nil
+}
+
+// splitLines keeps the line endings, so a missing newline at the end is a change too.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines finds the shortest edit script with the Myers algorithm.
+func diffLines(a []string, b []string) ([]edit, error) {
+	// Common lines at the start and the end don't need the algorithm
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	middle, err := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	if err != nil {
+		return nil, err
+	}
+
+	edits := make([]edit, 0, prefix+len(middle)+suffix)
+	for _, line := range a[:prefix] {
+		edits = append(edits, edit{equal, line})
+	}
+	edits = append(edits, middle...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, edit{equal, line})
+	}
+	return edits, nil
+}
+
+func myers(a []string, b []string) ([]edit, error) {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil, nil
+	}
+
+	// v[k+offset] is the furthest x on diagonal k, trace keeps v after every step for the backtrack
+	offset := max
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		if d > MaxChanges {
+			return nil, ErrTooManyChanges
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+				x = v[k+1+offset]
+			} else {
+				x = v[k-1+offset] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+offset] = x
+
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+				return backtrack(a, b, trace), nil
+			}
+		}
+
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+
+	return nil, ErrTooManyChanges
+}
+
+// backtrack walks the trace from the end. trace[d] holds the diagonals -d..d of step d.
+func backtrack(a []string, b []string, trace [][]int) []edit {
+	x, y := len(a), len(b)
+	var reversed []edit
+
+	for d := len(trace) - 1; d > 0; d-- {
+		previous := trace[d-1]
+		at := func(k int) int { return previous[k+d-1] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, edit{equal, a[x]})
+		}
+
+		if x == prevX {
+			y--
+			reversed = append(reversed, edit{inserted, b[y]})
+		} else {
+			x--
+			reversed = append(reversed, edit{deleted, a[x]})
+		}
+	}
+
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, edit{equal, a[x]})
+	}
+
+	edits := make([]edit, len(reversed))
+	for i, e := range reversed {
+		edits[len(reversed)-1-i] = e
+	}
+	return edits
+}
+
+func writeHunks(out *strings.Builder, edits []edit) {
+	for start := 0; start < len(edits); {
+		// Find the next change and the end of the hunk around it
+		for start < len(edits) && edits[start].op == equal {
+			start++
+		}
+		if start == len(edits) {
+			return
+		}
+
+		end := start
+		for end < len(edits) {
+			if edits[end].op != equal {
+				end++
+				continue
+			}
+
+			unchanged := end
+			for unchanged < len(edits) && edits[unchanged].op == equal {
+				unchanged++
+			}
+			if unchanged == len(edits) || unchanged-end > 2*Context {
+				break
+			}
+			end = unchanged
+		}
+
+		hunkStart := max(start-Context, 0)
+		hunkEnd := min(end+Context, len(edits))
+
+		fromLine, toLine := 1, 1
+		for _, e := range edits[:hunkStart] {
+			if e.op != inserted {
+				fromLine++
+			}
+			if e.op != deleted {
+				toLine++
+			}
+		}
+
+		fromCount, toCount := 0, 0
+		for _, e := range edits[hunkStart:hunkEnd] {
+			if e.op != inserted {
+				fromCount++
+			}
+			if e.op != deleted {
+				toCount++
+			}
+		}
+
+		fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(fromLine, fromCount), hunkRange(toLine, toCount))
+		for _, e := range edits[hunkStart:hunkEnd] {
+			prefix := " "
+			switch e.op {
+			case deleted:
+				prefix = "-"
+			case inserted:
+				prefix = "+"
+			}
+
+			out.WriteString(prefix + e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		start = hunkEnd
+	}
+}
+
+// hunkRange formats the lines like diff does, an empty range starts at the line before it.
+func hunkRange(line int, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", line-1)
+	case 1:
+		return fmt.Sprintf("%d", line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
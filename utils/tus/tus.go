@@ -0,0 +1,148 @@
+// Package tus keeps the data of resumable uploads (https://tus.io/protocols/resumable-upload, version 1.0.0).
+// Upload state lives in the uploads table and the data in the uploads folder, so both survive restarts.
+package tus
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/checksum"
+	"github.com/MertJSX/folder-host-go/utils/versions"
+)
+
+const (
+	Version    = "1.0.0"
+	Extensions = "creation,termination,expiration,checksum"
+	// Folder holds the data of unfinished uploads. Unlike ./tmp it isn't cleared on startup.
+	Folder = "uploads"
+)
+
+var locks sync.Map
+
+// Lock makes requests for the same upload wait for each other and returns the unlock function.
+func Lock(id string) func() {
+	value, _ := locks.LoadOrStore(id, &sync.Mutex{})
+	mutex := value.(*sync.Mutex)
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+func NewID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("random read failed: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func DataPath(id string) string {
+	return filepath.Join(Folder, id)
+}
+
+// ParseMetadata decodes an Upload-Metadata header like "filename d29ybGQ=,is_confidential".
+// Keys without a value get an empty string.
+func ParseMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		switch len(parts) {
+		case 1:
+			metadata[parts[0]] = ""
+		case 2:
+			value, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid base64 value for %q", parts[0])
+			}
+			metadata[parts[0]] = string(value)
+		default:
+			return nil, fmt.Errorf("invalid metadata pair %q", pair)
+		}
+	}
+
+	return metadata, nil
+}
+
+// Create makes the empty data file of a new upload.
+func Create(id string) error {
+	file, err := os.OpenFile(DataPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// Append writes data at the offset of the upload and returns the new offset. Bytes after the saved offset
+// are dropped first, they are left over from a request that failed before the offset was saved.
+func Append(upload types.Upload, data io.Reader) (int64, error) {
+	file, err := os.OpenFile(DataPath(upload.ID), os.O_WRONLY, 0600)
+	if err != nil {
+		return upload.Offset, err
+	}
+	defer file.Close()
+
+	if err := file.Truncate(upload.Offset); err != nil {
+		return upload.Offset, err
+	}
+
+	if _, err := file.Seek(upload.Offset, io.SeekStart); err != nil {
+		return upload.Offset, err
+	}
+
+	written, err := io.Copy(file, io.LimitReader(data, upload.Length-upload.Offset))
+	if err != nil {
+		return upload.Offset, err
+	}
+
+	return upload.Offset + written, file.Sync()
+}
+
+// Finish verifies the whole upload and moves it to finalPath with the conflict policy. It returns the path
+// the file ended up at. The data goes through a temp file next to finalPath, so the rename into place is atomic
+// even when the uploads folder is on another device.
+func Finish(upload types.Upload, finalPath string, conflictPolicy string, fileChecksum checksum.Checksum) (string, error) {
+	if err := fileChecksum.VerifyFile(DataPath(upload.ID)); err != nil {
+		return "", err
+	}
+
+	tempFile, err := utils.CreateTempFile(finalPath)
+	if err != nil {
+		return "", err
+	}
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	if err := os.Rename(DataPath(upload.ID), tempFile.Name()); err != nil {
+		if err := utils.CopyFile(DataPath(upload.ID), tempFile.Name()); err != nil {
+			return "", err
+		}
+	}
+
+	placedPath, err := versions.Replace(upload.Username, tempFile.Name(), finalPath, conflictPolicy)
+	if err != nil {
+		return "", err
+	}
+
+	return placedPath, Remove(upload.ID)
+}
+
+func Remove(id string) error {
+	err := os.Remove(DataPath(id))
+	locks.Delete(id)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,260 @@
+// Package versions keeps the old content of files that are overwritten by uploads, the editor and the other
+// write paths. Versions are kept in ./versions outside of the host folder, like the recovery bin.
+package versions
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MertJSX/folder-host-go/database/versions"
+	"github.com/MertJSX/folder-host-go/types"
+	"github.com/MertJSX/folder-host-go/utils"
+	"github.com/MertJSX/folder-host-go/utils/config"
+	"github.com/MertJSX/folder-host-go/utils/sizeindex"
+)
+
+const Folder = "./versions"
+
+// EditSession is how long the editor has to be idle on a file before its next change makes a new version
+const EditSession = 10 * time.Minute
+
+var (
+	// Snapshots and the retention of the same files must not run together
+	mu        sync.Mutex
+	editsMu   sync.Mutex
+	lastEdits = make(map[string]time.Time)
+)
+
+// Replace moves tempPath to finalPath like utils.PlaceFile. With ConflictOverwrite the replaced content becomes a
+// version, it's kept with a hard link. The link is only recorded after the rename, if the rename fails the live
+// file still has the linked content and in-place writes to it would change the version, so the link is removed.
+// Snapshot failures are logged, they don't stop the write.
+func Replace(username string, tempPath string, finalPath string, policy string) (string, error) {
+	if policy != utils.ConflictOverwrite {
+		return utils.PlaceFile(tempPath, finalPath, policy)
+	}
+
+	version, kept := keep(username, finalPath, true)
+
+	placedPath, err := utils.PlaceFile(tempPath, finalPath, policy)
+	if err != nil {
+		if kept {
+			os.Remove(version.Location)
+		}
+		return placedPath, err
+	}
+
+	if kept {
+		record(version)
+	}
+	return placedPath, nil
+}
+
+// BeforeEdit keeps the content of the file at path as a version before the editor changes it. The editor writes
+// the file in place on every change, so only the content from before an editing session is kept and it's copied.
+func BeforeEdit(username string, path string) {
+	editsMu.Lock()
+	lastEdit, editing := lastEdits[path]
+	lastEdits[path] = time.Now()
+	for editedPath, editedAt := range lastEdits {
+		if time.Since(editedAt) > EditSession {
+			delete(lastEdits, editedPath)
+		}
+	}
+	editsMu.Unlock()
+
+	if editing && time.Since(lastEdit) <= EditSession {
+		return
+	}
+	if version, kept := keep(username, path, false); kept {
+		record(version)
+	}
+}
+
+// keep links or copies the content of the file at path to the versions folder. False means there is nothing
+// to keep, like when versioning is disabled.
+func keep(username string, path string, link bool) (types.FileVersion, bool) {
+	versioning := &config.Config.Versioning
+	if !versioning.Enabled {
+		return types.FileVersion{}, false
+	}
+
+	stat, err := os.Lstat(path)
+	if err != nil || !stat.Mode().IsRegular() {
+		return types.FileVersion{}, false
+	}
+
+	if limit := storageLimit(); limit > 0 && stat.Size() > limit {
+		return types.FileVersion{}, false
+	}
+
+	relPath, ok := relativePath(path)
+	if !ok {
+		return types.FileVersion{}, false
+	}
+
+	location := fmt.Sprintf("%s/%s_%s", Folder, utils.GenerateUniqueString(), filepath.Base(path))
+	if !link || os.Link(path, location) != nil {
+		if err := utils.CopyFile(path, location); err != nil {
+			os.Remove(location)
+			log.Printf("Error while saving a version of %s: %v\n", path, err)
+			return types.FileVersion{}, false
+		}
+	}
+
+	return types.FileVersion{
+		Path:        relPath,
+		Location:    location,
+		Username:    username,
+		SizeDisplay: utils.ConvertBytesToString(stat.Size()),
+		SizeBytes:   stat.Size(),
+	}, true
+}
+
+// record saves the kept version and removes the versions that don't fit the retention anymore.
+func record(version types.FileVersion) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := versions.CreateVersion(&version); err != nil {
+		os.Remove(version.Location)
+		log.Printf("Error while saving a version of %s: %v\n", version.Path, err)
+		return
+	}
+
+	if err := applyRetention(version.Path); err != nil {
+		log.Printf("Error while removing old versions: %v\n", err)
+	}
+}
+
+// Restore puts the content of the version back at path. The current content becomes a version first,
+// so a restore can be undone.
+func Restore(username string, version types.FileVersion, path string) error {
+	temp, err := utils.CreateTempFile(path)
+	if err != nil {
+		return err
+	}
+	temp.Close()
+
+	if err := utils.CopyFile(version.Location, temp.Name()); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	if _, err := Replace(username, temp.Name(), path, utils.ConflictOverwrite); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	sizeindex.Refresh(path)
+	return nil
+}
+
+// PathOf returns the path a file has in the version records, false means it's outside of the host folder.
+func PathOf(path string) (string, bool) {
+	return relativePath(path)
+}
+
+// Clear removes versions older than keep_days and the oldest versions above the storage limit.
+func Clear() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if days := config.Config.Versioning.KeepDays; days > 0 {
+		old, err := versions.GetVersionsCreatedBefore(time.Now().AddDate(0, 0, -days))
+		if err != nil {
+			return err
+		}
+		for _, version := range old {
+			if err := remove(version); err != nil {
+				return err
+			}
+		}
+	}
+
+	return applyStorageLimit()
+}
+
+// applyRetention removes the versions of the file above keep_last and the oldest versions above the storage limit.
+// mu must be held.
+func applyRetention(relPath string) error {
+	if keepLast := config.Config.Versioning.KeepLast; keepLast > 0 {
+		fileVersions, err := versions.GetVersionsByPath(relPath)
+		if err != nil {
+			return err
+		}
+		for _, version := range fileVersions[min(keepLast, len(fileVersions)):] {
+			if err := remove(version); err != nil {
+				return err
+			}
+		}
+	}
+
+	return applyStorageLimit()
+}
+
+// applyStorageLimit removes the oldest versions until they fit in the storage limit. mu must be held.
+func applyStorageLimit() error {
+	limit := storageLimit()
+	if limit <= 0 {
+		return nil
+	}
+
+	size, err := versions.GetVersionsSize()
+	if err != nil || size <= limit {
+		return err
+	}
+
+	all, err := versions.GetAllVersions()
+	if err != nil {
+		return err
+	}
+
+	for _, version := range all {
+		if size <= limit {
+			break
+		}
+		if err := remove(version); err != nil {
+			return err
+		}
+		size -= version.SizeBytes
+	}
+
+	return nil
+}
+
+// storageLimit returns the storage limit in bytes, 0 means unlimited.
+func storageLimit() int64 {
+	limit, err := utils.ParseQuota(config.Config.Versioning.StorageLimit)
+	if err != nil {
+		log.Printf("Versioning storage limit is ignored: %v\n", err)
+		return 0
+	}
+	return limit
+}
+
+func remove(version types.FileVersion) error {
+	if err := os.Remove(version.Location); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return versions.DeleteVersion(version.ID)
+}
+
+func relativePath(path string) (string, bool) {
+	relPath, err := filepath.Rel(filepath.Clean(config.Config.Folder), filepath.Clean(path))
+	if err != nil {
+		return "", false
+	}
+
+	relPath = filepath.ToSlash(relPath)
+	if relPath == "." || relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", false
+	}
+
+	return relPath, true
+}
@@ -56,15 +56,27 @@ func safeWriteMessage(conn *websocket.Conn, mt int, message []byte) error {
 	return conn.WriteMessage(mt, message)
 }
 
+// SendTo writes to a single client. Use it instead of conn.WriteMessage when other goroutines can write to the same client.
+func SendTo(conn *websocket.Conn, mt int, message []byte) error {
+	return safeWriteMessage(conn, mt, message)
+}
+
+// SendToAllExclude waits until every client got the message, so messages sent one after another arrive in order.
 func SendToAllExclude(path string, mt int, message []byte, exclude *websocket.Conn) {
 	clientsMu.RLock()
 	defer clientsMu.RUnlock()
 
+	var wg sync.WaitGroup
 	for conn, client := range clients {
 		if client.Path == path && conn != exclude {
-			go safeWriteMessage(conn, mt, message)
+			wg.Add(1)
+			go func(c *websocket.Conn) {
+				defer wg.Done()
+				safeWriteMessage(c, mt, message)
+			}(conn)
 		}
 	}
+	wg.Wait()
 }
 
 func SendToAll(path string, mt int, message []byte) {
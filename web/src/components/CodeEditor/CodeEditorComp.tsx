@@ -5,7 +5,9 @@ import { jsSnippets } from './snippets/jsSnippets';
 import { yamlSnippets } from './snippets/yamlSnippets';
 import theme from './themes/theme.json' with { type: 'json' }
 import Cookies from 'js-cookie';
-import type { ChangeData, EditorChange } from '../../types/CodeEditorTypes';
+import type { EditorChange, EditorPresence } from '../../types/CodeEditorTypes';
+import { presenceColors, presenceColorIndex } from '../../utils/presenceColor';
+import { type TextOperation, fromMonacoChanges, lengthBeforeChanges, toMonacoEdits, transform } from '../../utils/textOperation';
 import type { Monaco } from '@monaco-editor/react';
 import {
   FiSettings,
@@ -23,7 +25,8 @@ import { editor, Position } from 'monaco-editor';
 
 interface CodeEditorCompProps {
   editorLanguage: string,
-  handleEditorChange: (event: editor.IModelContentChangedEvent) => void,
+  path: string,
+  sendMessage: (message: string) => void,
   setEditorLanguage: React.Dispatch<React.SetStateAction<string>>,
   fileContent: string,
   response: string,
@@ -38,7 +41,8 @@ interface CodeEditorCompProps {
 
 const CodeEditorComp: React.FC<CodeEditorCompProps> = ({
   editorLanguage,
-  handleEditorChange,
+  path,
+  sendMessage,
   setEditorLanguage,
   fileContent,
   response,
@@ -55,9 +59,74 @@ const CodeEditorComp: React.FC<CodeEditorCompProps> = ({
   const [clientsCount, setClientsCount] = useState<number>(0)
   const isRemoteChangeRef = useRef<boolean>(false);
   const editorRef = useRef<editor.IStandaloneCodeEditor | null>(null);
+  // Collaboration state: the last revision of the server, the operation waiting for its ack
+  // and the local operations made meanwhile. Only one operation is sent at a time.
+  const revisionRef = useRef<number>(0);
+  const outstandingRef = useRef<TextOperation | null>(null);
+  const bufferRef = useRef<TextOperation[]>([]);
+  const processedMessagesRef = useRef<number>(0);
+  // Presence: the other connections of this file and the decorations that show their cursors
+  const [remoteUsers, setRemoteUsers] = useState<EditorPresence[]>([]);
+  const selfIdRef = useRef<string>("");
+  const presenceTimeoutRef = useRef<number | null>(null);
+  const decorationsRef = useRef<editor.IEditorDecorationsCollection | null>(null);
+
+  const sendPresence = useCallback(() => {
+    const editor = editorRef.current;
+    if (!isConnectedRef.current || !editor) return;
+
+    sendMessage(JSON.stringify({
+      type: 'presence-update',
+      path: path,
+      cursor: editor.getPosition(),
+      selections: (editor.getSelections() ?? [])
+        .filter((selection) => !selection.isEmpty())
+        .map((selection) => ({
+          startLineNumber: selection.startLineNumber,
+          startColumn: selection.startColumn,
+          endLineNumber: selection.endLineNumber,
+          endColumn: selection.endColumn
+        }))
+    }));
+  }, [path, sendMessage]);
+
+  // Cursor moves are sent at most every 100 ms
+  const schedulePresence = useCallback(() => {
+    if (presenceTimeoutRef.current) return;
+
+    presenceTimeoutRef.current = setTimeout(() => {
+      presenceTimeoutRef.current = null;
+      sendPresence();
+    }, 100);
+  }, [sendPresence]);
+
+  const sendOperation = useCallback((operation: TextOperation) => {
+    sendMessage(JSON.stringify({
+      type: 'editor-change',
+      path: path,
+      revision: revisionRef.current,
+      operation: operation
+    }));
+  }, [path, sendMessage]);
+
+  const handleLocalChange = useCallback((event: editor.IModelContentChangedEvent) => {
+    const model = editorRef.current?.getModel();
+    if (!isConnectedRef.current || !model) return;
+
+    const operation = fromMonacoChanges(event.changes, lengthBeforeChanges(model, event.changes));
+
+    if (outstandingRef.current) {
+      bufferRef.current.push(operation);
+      return;
+    }
+
+    outstandingRef.current = operation;
+    sendOperation(operation);
+  }, [sendOperation]);
 
   const handleEditorDidMount = useCallback((editor: editor.IStandaloneCodeEditor, monacoInstance: Monaco) => {
     editorRef.current = editor;
+    decorationsRef.current = editor.createDecorationsCollection();
 
     monacoInstance.editor.defineTheme('vs-dark', theme as any);
 
@@ -150,17 +219,21 @@ const CodeEditorComp: React.FC<CodeEditorCompProps> = ({
     });
 
     editor.onDidChangeModelContent((event: editor.IModelContentChangedEvent) => {
-      if (readOnly) {
-        editor.trigger("myapp", "undo", "");
+      if (isRemoteChangeRef.current) {
+        isRemoteChangeRef.current = false;
         return;
       }
 
-      if (isRemoteChangeRef.current) {
-        isRemoteChangeRef.current = false;
+      if (readOnly) {
+        editor.trigger("myapp", "undo", "");
         return;
       }
 
-      handleEditorChange(event);
+      handleLocalChange(event);
+    });
+
+    editor.onDidChangeCursorSelection(() => {
+      schedulePresence();
     });
 
     editor.addAction({
@@ -195,45 +268,161 @@ const CodeEditorComp: React.FC<CodeEditorCompProps> = ({
         editor.trigger("myapp", "redo", "");
       }
     });
-  }, [handleEditorChange, readOnly]);
+  }, [handleLocalChange, schedulePresence, readOnly]);
 
   useEffect(() => {
-    if (editorRef.current) {
-      const position = editorRef.current.getPosition();
-      const model = editorRef.current.getModel();
-
-      if (model) {
-        model.setValue(fileContent);
-        if (position) {
-          editorRef.current.setPosition(position);
-        }
-        editorRef.current.updateOptions({ readOnly: readOnly });
+    editorRef.current?.updateOptions({ readOnly: readOnly });
+  }, [readOnly]);
+
+  useEffect(() => {
+    const decorations: editor.IModelDeltaDecoration[] = [];
+
+    remoteUsers.forEach((user) => {
+      const color = presenceColorIndex(user.id);
+
+      user.selections.forEach((selection) => {
+        decorations.push({
+          range: selection,
+          options: {
+            className: `remote-selection-${color}`,
+            hoverMessage: { value: user.username }
+          }
+        });
+      });
+
+      if (user.cursor) {
+        decorations.push({
+          range: {
+            startLineNumber: user.cursor.lineNumber,
+            startColumn: user.cursor.column,
+            endLineNumber: user.cursor.lineNumber,
+            endColumn: user.cursor.column
+          },
+          options: {
+            beforeContentClassName: `remote-cursor-${color}`,
+            hoverMessage: { value: user.username },
+            stickiness: 1 // NeverGrowsWhenTypingAtEdges
+          }
+        });
+      }
+    });
+
+    decorationsRef.current?.set(decorations);
+  }, [remoteUsers]);
+
+  useEffect(() => {
+    replaceContent(fileContent);
+  }, [fileContent]);
+
+  // Replaces the whole content without sending it to the server
+  const replaceContent = (content: string) => {
+    const model = editorRef.current?.getModel();
+    if (!model || model.getValue() === content) return;
+
+    const position = editorRef.current?.getPosition();
+    isRemoteChangeRef.current = true;
+    model.applyEdits([{
+      range: model.getFullModelRange(),
+      text: content,
+      forceMoveMarkers: true
+    }]);
+    if (position) {
+      editorRef.current?.setPosition(position);
+    }
+  };
+
+  const resetCollaboration = (content: string, revision: number) => {
+    revisionRef.current = revision;
+    outstandingRef.current = null;
+    bufferRef.current = [];
+    replaceContent(content);
+  };
+
+  const applyRemoteOperation = (operation: TextOperation, revision: number) => {
+    const model = editorRef.current?.getModel();
+    if (!model || revision <= revisionRef.current) return;
+
+    // The local operations that the server doesn't know yet go after the remote one
+    try {
+      if (outstandingRef.current) {
+        [outstandingRef.current, operation] = transform(outstandingRef.current, operation);
       }
+      bufferRef.current = bufferRef.current.map((buffered) => {
+        const [bufferedPrime, operationPrime] = transform(buffered, operation);
+        operation = operationPrime;
+        return bufferedPrime;
+      });
+    } catch (err) {
+      console.error(err);
+      sendMessage(JSON.stringify({ type: 'resync', path: path }));
+      return;
     }
-  }, [readOnly, fileContent]);
+
+    revisionRef.current = revision;
+
+    const edits = toMonacoEdits(operation, model);
+    if (edits.length > 0) {
+      isRemoteChangeRef.current = true;
+      model.applyEdits(edits);
+    }
+  };
+
+  const handleAck = (revision: number) => {
+    if (!outstandingRef.current) return;
+
+    revisionRef.current = revision;
+    outstandingRef.current = bufferRef.current.shift() ?? null;
+    if (outstandingRef.current) {
+      sendOperation(outstandingRef.current);
+    }
+  };
 
   useEffect(() => {
-    if (isConnectedRef.current && messages.length > 0) {
+    // Every message matters for the revisions, not only the last one
+    const newMessages = messages.slice(processedMessagesRef.current);
+    processedMessagesRef.current = messages.length;
+
+    newMessages.forEach((rawMessage) => {
       let message: EditorChange;
 
       try {
-        message = JSON.parse(messages[messages.length - 1] ?? "");
+        message = JSON.parse(rawMessage);
       } catch (err) {
-        console.warn(messages[messages.length - 1]);
+        console.warn(rawMessage);
         console.error(err);
         return;
       }
 
       switch (message.type) {
         case "editor-change":
-          isRemoteChangeRef.current = true;
-          if (message.change) {
-            applyRemoteChange(message.change);
+          if (message.operation && message.revision !== undefined) {
+            applyRemoteOperation(message.operation, message.revision);
           }
           break;
+        case "editor-ack":
+          handleAck(message.revision ?? 0);
+          break;
+        case "editor-resync":
+          resetCollaboration(message.content ?? "", message.revision ?? 0);
+          break;
         case "editor-update-usercount":
           setClientsCount(message.count ?? 0);
           break;
+        case "presence-roster":
+          selfIdRef.current = message.self ?? "";
+          setRemoteUsers((message.users ?? []).filter((user) => user.id !== selfIdRef.current));
+          sendPresence();
+          break;
+        case "presence-join":
+        case "presence-update":
+          if (message.user && message.user.id !== selfIdRef.current) {
+            const user = message.user;
+            setRemoteUsers((users) => [...users.filter((other) => other.id !== user.id), user]);
+          }
+          break;
+        case "presence-leave":
+          setRemoteUsers((users) => users.filter((user) => user.id !== message.id));
+          break;
         case "error":
           setRes(message.error ?? "Unknown error");
           break;
@@ -242,59 +431,11 @@ const CodeEditorComp: React.FC<CodeEditorCompProps> = ({
           setReadOnly(true);
           break;
         case "full-content-update":
-          if (message.content) {
-            if (!editorRef.current) return;
-
-            const editor = editorRef.current;
-            const model = editor.getModel();
-
-            if (!model) return;
-            
-            isRemoteChangeRef.current = true;
-
-            model.applyEdits([{
-              range: model.getFullModelRange(),
-              text: message.content,
-              forceMoveMarkers: true
-            }]);
-          }
+          resetCollaboration(message.content ?? "", message.revision ?? 0);
           break;
       }
-    }
-  }, [messages, isConnectedRef, setRes]);
-
-  const applyRemoteChange = (change: ChangeData) => {
-    if (!editorRef.current) return;
-
-    const editor = editorRef.current;
-    const model = editor.getModel();
-
-    if (!model) return;
-
-    switch (change.type) {
-      case 'insert':
-        model.applyEdits([{
-          range: change.range,
-          text: change?.text,
-          forceMoveMarkers: true
-        }]);
-        break;
-      case 'delete':
-        model.applyEdits([{
-          range: change.range,
-          text: '',
-          forceMoveMarkers: true
-        }]);
-        break;
-      case 'replace':
-        model.applyEdits([{
-          range: change.range,
-          text: change.text,
-          forceMoveMarkers: true
-        }]);
-        break;
-    }
-  };
+    });
+  }, [messages, setRes]);
 
   return (
     <div className={"flex flex-col h-screen bg-gray-900"}>
@@ -319,6 +460,16 @@ const CodeEditorComp: React.FC<CodeEditorCompProps> = ({
             <span className="text-sm">
               Online: <span className="font-semibold text-emerald-300">{clientsCount}</span>
             </span>
+            {remoteUsers.map((user) => (
+              <span
+                key={user.id}
+                title={user.canEdit ? "Editing" : "Viewing"}
+                className="px-2 py-0.5 text-xs font-semibold rounded-full"
+                style={{ backgroundColor: presenceColors[presenceColorIndex(user.id)] }}
+              >
+                <span className="text-gray-900">{user.username}</span>
+              </span>
+            ))}
           </div>
 
           {/* Status */}
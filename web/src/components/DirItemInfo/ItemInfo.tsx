@@ -6,6 +6,7 @@ import { type ExplorerContextType } from '../../types/ExplorerContextType';
 import { DirectoryItemIcon } from '../../utils/DirectoryItemIcon';
 import axiosInstance from '../../utils/axiosInstance';
 import { isImageItem } from '../../utils/isImageItem';
+import { archiveFormats, isArchiveItem } from '../../utils/isArchiveItem';
 
 const ItemInfo = () => {
   const renameInput = useRef<HTMLInputElement>(null)
@@ -31,6 +32,7 @@ const ItemInfo = () => {
   const [imageData, setImageData] = useState("")
   const [imageLoading, setImageLoading] = useState(false)
   const [imageError, setImageError] = useState(false)
+  const [archiveFormat, setArchiveFormat] = useState(archiveFormats[0])
 
   const fetchImage = async () => {
     setImageLoading(true)
@@ -212,7 +214,7 @@ const ItemInfo = () => {
                     disabled
                   >Create copy</button> : null
             }
-            {itemInfo?.name.split(".").pop() === "zip" && unzipProgress === "" ?
+            {isArchiveItem(itemInfo) && unzipProgress === "" ?
               (permissions?.extract ?
                 <button
                   className='bg-yellow-600 px-6 font-bold rounded-xl'
@@ -226,7 +228,7 @@ const ItemInfo = () => {
                     title='No permission!'
                     disabled
                   >Unzip</button> : null)
-              : itemInfo?.name.split(".").pop() === "zip" && unzipProgress !== "" ?
+              : isArchiveItem(itemInfo) && unzipProgress !== "" ?
                 <div>
                   <h1 className='text-center'>Unzipping...</h1>
                   <h1 className='text-center text-xl'>Progress: <span className='text-sky-300'>{unzipProgress}</span></h1>
@@ -292,13 +294,23 @@ const ItemInfo = () => {
             }
             {itemInfo?.path !== "./" && itemInfo?.path !== directoryInfo?.path && zipProgress === "" ?
               (permissions?.archive ?
-                <button
-                  className='bg-yellow-600 px-6 font-bold rounded-xl'
-                  title='Click to zip.'
-                  onClick={() => {
-                    startZipping()
-                  }}
-                >Zip</button> : showDisabled === true ?
+                <div className='flex gap-2'>
+                  <button
+                    className='bg-yellow-600 px-6 font-bold rounded-xl'
+                    title='Click to zip.'
+                    onClick={() => {
+                      startZipping(archiveFormat)
+                    }}
+                  >Zip</button>
+                  <select
+                    className='bg-gray-700 px-2 rounded-xl'
+                    title='Archive format'
+                    value={archiveFormat}
+                    onChange={(e) => setArchiveFormat(e.target.value)}
+                  >
+                    {archiveFormats.map((format) => <option key={format} value={format}>.{format}</option>)}
+                  </select>
+                </div> : showDisabled === true ?
                   <button
                     className='bg-yellow-600 px-6 font-bold rounded-xl opacity-50'
                     title='No permission!'
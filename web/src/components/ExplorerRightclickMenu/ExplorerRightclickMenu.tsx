@@ -4,6 +4,7 @@ import { RiDeleteBin6Fill } from "react-icons/ri";
 import { FaDownload, FaCopy, FaFileArchive } from "react-icons/fa";
 import ExplorerRMItem from "./ExplorerRMItem";
 import { MdDriveFileRenameOutline } from "react-icons/md";
+import { isArchiveItem } from "../../utils/isArchiveItem";
 
 
 interface ExplorerRightclickMenuProps {
@@ -88,7 +89,7 @@ const ExplorerRightclickMenu: React.FC<ExplorerRightclickMenuProps> = ({ x, y })
                 onClick={() => { createCopy(itemInfo) }}>
                 <FaCopy size={iconSize} />Create Copy
             </ExplorerRMItem>
-            {(itemInfo?.name.split(".").pop() === "zip" && unzipProgress === "") && !itemInfo?.isDirectory ?
+            {(isArchiveItem(itemInfo) && unzipProgress === "") && !itemInfo?.isDirectory ?
                 (permissions?.extract ?
                     <ExplorerRMItem
                         title='Click to unzip.'
@@ -100,7 +101,7 @@ const ExplorerRightclickMenu: React.FC<ExplorerRightclickMenuProps> = ({ x, y })
                             title='No permission!'
                             isDisabled={true}
                         ><FaFileArchive size={iconSize} />Unzip</ExplorerRMItem> : null)
-                : (itemInfo?.name.split(".").pop() === "zip" && unzipProgress !== "") && !itemInfo?.isDirectory ?
+                : (isArchiveItem(itemInfo) && unzipProgress !== "") && !itemInfo?.isDirectory ?
                     <ExplorerRMItem
                         title='Unzipping...'
                         isDisabled={true}
@@ -1,10 +1,9 @@
-import { useState, useEffect, useCallback } from 'react';
+import { useState, useEffect } from 'react';
 import Cookies from 'js-cookie';
 import { useNavigate, useParams } from 'react-router-dom';
 import CodeEditorComp from '../../components/CodeEditor/CodeEditorComp.jsx';
 import useWebSocket from '../../utils/useWebSocket.js';
 import axiosInstance from '../../utils/axiosInstance.js';
-import type { editor } from 'monaco-editor';
 import MessageBox from '../../components/minimal/MessageBox/MessageBox.js';
 
 const CodeEditorPage = () => {
@@ -25,44 +24,6 @@ const CodeEditorPage = () => {
         sendMessage
     } = useWebSocket(path?.slice(1) ? path?.slice(1) : "", shouldConnect)
 
-    function handleEditorChange(event: editor.IModelContentChangedEvent) {
-        sendChangeToWebSocket(event.changes)
-    }
-
-    const sendChangeToWebSocket = useCallback((changes: editor.IModelContentChange[]) => {
-        if (!isConnectedRef.current || readOnly) return;
-
-        changes.forEach((change: editor.IModelContentChange) => {
-            let changeType;
-            if (change.text && change.range.startLineNumber !== change.range.endLineNumber ||
-                change.range.startColumn !== change.range.endColumn) {
-                changeType = 'replace';
-            } else if (change.text) {
-                changeType = 'insert';
-            } else {
-                changeType = 'delete';
-            }
-
-            const delta = JSON.stringify({
-                type: 'editor-change',
-                path: path?.slice(1),
-                change: {
-                    type: changeType,
-                    range: {
-                        startLineNumber: change.range.startLineNumber,
-                        startColumn: change.range.startColumn,
-                        endLineNumber: change.range.endLineNumber,
-                        endColumn: change.range.endColumn
-                    },
-                    text: change.text || '',
-                    timestamp: Date.now()
-                }
-            });
-
-            sendMessage(delta);
-        });
-    }, [readOnly]);
-
     function readFile() {
         axiosInstance.get(`/read-file?filepath=${path?.slice(1)}`
         ).then((data) => {
@@ -141,7 +102,8 @@ const CodeEditorPage = () => {
         <div>
             <MessageBox isErr={err != ""} message={err} setMessage={setErr} />
             <CodeEditorComp
-                handleEditorChange={handleEditorChange}
+                path={path?.slice(1) ?? ""}
+                sendMessage={sendMessage}
                 editorLanguage={editorLanguage}
                 setEditorLanguage={setEditorLanguage}
                 fileContent={fileContent}
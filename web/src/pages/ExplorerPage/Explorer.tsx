@@ -422,7 +422,7 @@ const ExplorerPage: React.FC = () => {
     }
   }
 
-  function startZipping(): void {
+  function startZipping(format: string = "zip"): void {
     if (downloading || waitingResponse || unzipping || zipping) {
       waitPreviousAction();
       return
@@ -431,7 +431,8 @@ const ExplorerPage: React.FC = () => {
       setZipping(true);
       sendMessage(JSON.stringify({
         type: "zip",
-        path: itemInfo?.path.slice(1)
+        path: itemInfo?.path.slice(1),
+        format: format
       }))
     }
   }
@@ -1,3 +1,5 @@
+import type { TextOperation } from '../utils/textOperation';
+
 interface WebSocketResponseType {
     type: string,
     error?: string,
@@ -15,7 +17,26 @@ interface EditorChange {
     count?: number,
     content?: string,
     error?: string,
-    change: ChangeData
+    change?: ChangeData,
+    revision?: number,
+    operation?: TextOperation,
+    self?: string,
+    users?: EditorPresence[],
+    user?: EditorPresence,
+    id?: string
+}
+
+interface EditorPresence {
+    id: string,
+    username: string,
+    canEdit: boolean,
+    cursor: EditorPosition | null,
+    selections: ChangeRange[]
+}
+
+interface EditorPosition {
+    lineNumber: number,
+    column: number
 }
 
 interface ChangeData {
@@ -33,4 +54,4 @@ interface ChangeRange {
     endColumn: number
 }
 
-export {type WebSocketResponseType, type EditorChange, type ChangeData, type ChangeRange}
\ No newline at end of file
+export {type WebSocketResponseType, type EditorChange, type ChangeData, type ChangeRange, type EditorPresence, type EditorPosition}
\ No newline at end of file
@@ -0,0 +1,19 @@
+import type { DirectoryItem } from "../types/DirectoryItem";
+
+// Same extensions as utils.DetectArchiveFormat on the server
+const archiveExtensions = [
+    '.zip', '.tar', '.tar.gz', '.tgz', '.tar.bz2', '.tbz2', '.tbz',
+    '.tar.xz', '.txz', '.tar.zst', '.tzst'
+];
+
+// Formats the server can create, the first one is the default
+export const archiveFormats = ['zip', 'tar.gz', 'tar.xz', 'tar.zst', 'tar'];
+
+export const isArchiveItem = (itemInfo: DirectoryItem | null): boolean => {
+    if (!itemInfo || itemInfo.isDirectory) {
+        return false;
+    }
+
+    const name = itemInfo.name?.toLowerCase() ?? "";
+    return archiveExtensions.some((extension) => name.endsWith(extension));
+};
@@ -0,0 +1,12 @@
+// Same order as the .remote-cursor-N and .remote-selection-N classes in global.css
+const presenceColors = ["#f87171", "#fb923c", "#facc15", "#4ade80", "#2dd4bf", "#60a5fa", "#a78bfa", "#f472b6"];
+
+function presenceColorIndex(id: string): number {
+    let hash = 0;
+    for (let i = 0; i < id.length; i++) {
+        hash = (hash * 31 + id.charCodeAt(i)) | 0;
+    }
+    return Math.abs(hash) % presenceColors.length;
+}
+
+export { presenceColors, presenceColorIndex };
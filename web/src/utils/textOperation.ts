@@ -0,0 +1,168 @@
+import type { editor } from 'monaco-editor';
+
+// Same format as the server: positive numbers retain, strings insert and negative numbers delete.
+// Lengths are UTF-16 code units, like the offsets of Monaco.
+type TextOperation = Array<number | string>;
+
+function isRetain(component: number | string | undefined): component is number {
+    return typeof component === "number" && component > 0;
+}
+
+function isDelete(component: number | string | undefined): component is number {
+    return typeof component === "number" && component < 0;
+}
+
+function isInsert(component: number | string | undefined): component is string {
+    return typeof component === "string";
+}
+
+function retain(op: TextOperation, n: number) {
+    if (n <= 0) return;
+    if (isRetain(op[op.length - 1])) {
+        (op[op.length - 1] as number) += n;
+        return;
+    }
+    op.push(n);
+}
+
+function insert(op: TextOperation, text: string) {
+    if (text === "") return;
+    const last = op[op.length - 1];
+    if (isInsert(last)) {
+        op[op.length - 1] = last + text;
+        return;
+    }
+    // Inserts always go before deletes at the same position
+    if (isDelete(last)) {
+        const beforeLast = op[op.length - 2];
+        if (isInsert(beforeLast)) {
+            op[op.length - 2] = beforeLast + text;
+            return;
+        }
+        op[op.length - 1] = text;
+        op.push(last);
+        return;
+    }
+    op.push(text);
+}
+
+function remove(op: TextOperation, n: number) {
+    if (n <= 0) return;
+    if (isDelete(op[op.length - 1])) {
+        (op[op.length - 1] as number) -= n;
+        return;
+    }
+    op.push(-n);
+}
+
+// Builds one operation from the changes of a Monaco event. All changes refer to the content before the event.
+function fromMonacoChanges(changes: editor.IModelContentChange[], lengthBefore: number): TextOperation {
+    const op: TextOperation = [];
+    let index = 0;
+
+    [...changes].sort((a, b) => a.rangeOffset - b.rangeOffset).forEach((change) => {
+        retain(op, change.rangeOffset - index);
+        insert(op, change.text);
+        remove(op, change.rangeLength);
+        index = change.rangeOffset + change.rangeLength;
+    });
+    retain(op, lengthBefore - index);
+
+    return op;
+}
+
+// Length of the content before the changes of a Monaco event, computed from the current model.
+function lengthBeforeChanges(model: editor.ITextModel, changes: editor.IModelContentChange[]): number {
+    return changes.reduce((length, change) => length - change.text.length + change.rangeLength, model.getValueLength());
+}
+
+// Converts an operation to edits for model.applyEdits, based on the current content of the model.
+function toMonacoEdits(op: TextOperation, model: editor.ITextModel): editor.IIdentifiedSingleEditOperation[] {
+    const edits: editor.IIdentifiedSingleEditOperation[] = [];
+    let index = 0;
+
+    op.forEach((component, i) => {
+        if (isRetain(component)) {
+            index += component;
+            return;
+        }
+        if (isInsert(component)) {
+            const next = op[i + 1];
+            const deleted = isDelete(next) ? -next : 0;
+            const start = model.getPositionAt(index);
+            const end = model.getPositionAt(index + deleted);
+            edits.push({
+                range: { startLineNumber: start.lineNumber, startColumn: start.column, endLineNumber: end.lineNumber, endColumn: end.column },
+                text: component,
+                forceMoveMarkers: true
+            });
+            index += deleted;
+            return;
+        }
+        if (isDelete(component) && !isInsert(op[i - 1])) {
+            const start = model.getPositionAt(index);
+            const end = model.getPositionAt(index - component);
+            edits.push({
+                range: { startLineNumber: start.lineNumber, startColumn: start.column, endLineNumber: end.lineNumber, endColumn: end.column },
+                text: "",
+                forceMoveMarkers: true
+            });
+            index -= component;
+        }
+    });
+
+    return edits;
+}
+
+// Returns [a', b'], so applying a then b' gives the same content as b then a'. On ties the insert of a goes first,
+// the same rule as the server.
+function transform(a: TextOperation, b: TextOperation): [TextOperation, TextOperation] {
+    const aPrime: TextOperation = [];
+    const bPrime: TextOperation = [];
+    let i = 0, j = 0;
+    let first = a[i++];
+    let second = b[j++];
+
+    while (first !== undefined || second !== undefined) {
+        if (isInsert(first)) {
+            insert(aPrime, first);
+            retain(bPrime, first.length);
+            first = a[i++];
+            continue;
+        }
+        if (isInsert(second)) {
+            retain(aPrime, second.length);
+            insert(bPrime, second);
+            second = b[j++];
+            continue;
+        }
+        if (first === undefined || second === undefined) {
+            throw new Error("Operations have different lengths");
+        }
+
+        const length = Math.min(Math.abs(first), Math.abs(second));
+
+        if (isRetain(first) && isRetain(second)) {
+            retain(aPrime, length);
+            retain(bPrime, length);
+        } else if (isDelete(first) && isRetain(second)) {
+            remove(aPrime, length);
+        } else if (isRetain(first) && isDelete(second)) {
+            remove(bPrime, length);
+        }
+
+        first = shorten(first, length);
+        second = shorten(second, length);
+        if (first === undefined) first = a[i++];
+        if (second === undefined) second = b[j++];
+    }
+
+    return [aPrime, bPrime];
+}
+
+function shorten(component: number, length: number): number | undefined {
+    const rest = component > 0 ? component - length : component + length;
+    return rest === 0 ? undefined : rest;
+}
+
+export { type TextOperation, fromMonacoChanges, lengthBeforeChanges, toMonacoEdits, transform };